@@ -2,7 +2,11 @@ package reform
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/empirefox/reform/parse"
 )
@@ -13,42 +17,153 @@ var (
 
 	// ErrNoPK is returned from various methods when primary key is required and not set.
 	ErrNoPK = errors.New("reform: no primary key")
+
+	// ErrNothingToUpdate is returned from various update methods when there are no columns to update.
+	ErrNothingToUpdate = errors.New("reform: nothing to update")
+
+	// ErrMultipleRowsAffected is returned from update and Delete methods when more than one row
+	// was affected by primary key, and Querier's PanicOnMultiAffected is false.
+	ErrMultipleRowsAffected = errors.New("reform: multiple rows affected by primary key")
 )
 
+// Default is a sentinel value which makes Insert and other single-row insert methods use column's default value
+// instead of binding a parameter. It can be returned by struct's Values method or ColumnTransformer's ToDB.
+// It can't be used in other queries.
+var Default driver.Valuer = defaultValue{}
+
+// defaultValue is a type of Default.
+type defaultValue struct{}
+
+// Value implements driver.Valuer. It returns error, as Default should not reach the database driver.
+func (defaultValue) Value() (driver.Value, error) {
+	return nil, errors.New("reform: Default can be used only with single-row insert methods")
+}
+
+// InvalidEnumError is returned from Querier's insert and update methods
+// when struct implementing EnumValidator has a value which is not allowed for enum column.
+type InvalidEnumError struct {
+	Column string // SQL database column name
+	Value  string // invalid value
+}
+
+// Error returns a string representation of this error.
+func (e *InvalidEnumError) Error() string {
+	return fmt.Sprintf("reform: invalid value %q for enum column %s", e.Value, e.Column)
+}
+
 type ViewBase struct {
 	m      map[string]string
 	fields []string
+	cols   []string
 	icols  []interface{}
 	pk     string
+
+	omitEmpty       map[string]bool // columns with "omitempty" label
+	caseInsensitive bool            // set by CaseInsensitive
+
+	foldedRW sync.RWMutex
+	folded   map[folder]map[string]string // folded column or field -> column, per folder
+}
+
+// folder folds identifiers for matching; Dialect is a folder.
+type folder interface {
+	FoldIdentifier(identifier string) string
+}
+
+// lowerFolder is a folder used by CaseInsensitive.
+type lowerFolder struct{}
+
+func (lowerFolder) FoldIdentifier(identifier string) string {
+	return strings.ToLower(identifier)
 }
 
-func NewViewBase(s *parse.StructInfo) *ViewBase {
-	v := ViewBase{m: make(map[string]string)}
+// ViewBaseOption configures ViewBase created by NewViewBase.
+type ViewBaseOption func(v *ViewBase)
+
+// CaseInsensitive is a ViewBaseOption which makes HasCol and ToCol (and "$Field" references expansion)
+// match field and column names case-insensitively, so "userID" and "USER_ID" both resolve to "user_id"
+// column of UserID field. It is useful for translating JSON keys to columns.
+//
+// Exact matches are always preferred. If several fields or columns differ only by case,
+// a case-insensitive lookup prefers columns to fields, and returns the first one in struct field order.
+func CaseInsensitive(v *ViewBase) {
+	v.caseInsensitive = true
+}
+
+func NewViewBase(s *parse.StructInfo, opts ...ViewBaseOption) *ViewBase {
+	v := ViewBase{
+		m:         make(map[string]string),
+		folded:    make(map[folder]map[string]string),
+		omitEmpty: make(map[string]bool),
+	}
 	for _, info := range s.Fields {
 		v.m[info.Name] = info.Column
 		v.m[info.Column] = info.Column
 		v.fields = append(v.fields, info.Name)
+		v.cols = append(v.cols, info.Column)
 		v.icols = append(v.icols, info.Column)
 		if info.PKType != "" {
 			v.pk = info.Column
 		}
+		if info.OmitEmpty {
+			v.omitEmpty[info.Column] = true
+		}
+	}
+	for _, opt := range opts {
+		opt(&v)
 	}
 	return &v
 }
 
 func (v *ViewBase) HasCol(field string) (string, bool) {
 	col, ok := v.m[field]
+	if !ok && v.caseInsensitive {
+		col, ok = v.foldedCol(lowerFolder{}, field)
+	}
 	return col, ok
 }
 
 func (v *ViewBase) ToCol(field string) string {
-	col, ok := v.m[field]
+	col, ok := v.HasCol(field)
 	if ok {
 		return col
 	}
 	return field
 }
 
+// FoldedCol returns a column which matches given name (for example, returned by database)
+// when both are folded with dialect's FoldIdentifier. Like HasCol, it also matches field names.
+func (v *ViewBase) FoldedCol(dialect Dialect, name string) (string, bool) {
+	return v.foldedCol(dialect, name)
+}
+
+// foldedCol returns a column which matches given name when both are folded with f.
+// Columns are preferred to fields; if several of them are folded to the same name, the first one wins.
+func (v *ViewBase) foldedCol(f folder, name string) (string, bool) {
+	v.foldedRW.RLock()
+	m := v.folded[f]
+	v.foldedRW.RUnlock()
+
+	if m == nil {
+		m = make(map[string]string, len(v.m))
+		for _, names := range [][]string{v.cols, v.fields} {
+			for i, n := range names {
+				key := f.FoldIdentifier(n)
+				if _, ok := m[key]; !ok {
+					m[key] = v.cols[i]
+				}
+			}
+		}
+
+		v.foldedRW.Lock()
+		v.folded[f] = m
+		v.foldedRW.Unlock()
+	}
+
+	col, ok := m[f.FoldIdentifier(name)]
+	return col, ok
+}
+
 func (v *ViewBase) Fields() []string {
 	return v.fields
 }
@@ -61,6 +176,12 @@ func (v *ViewBase) PK() string {
 	return v.pk
 }
 
+// OmitEmpty returns true if column has "omitempty" label in "reform:" struct field tag,
+// so Insert omits it when field has zero value.
+func (v *ViewBase) OmitEmpty(column string) bool {
+	return v.omitEmpty[column]
+}
+
 // View represents SQL database view or table.
 type View interface {
 	// Schema returns a schema name in SQL database.
@@ -70,6 +191,8 @@ type View interface {
 	Name() string
 
 	// Columns returns a new slice of column names for that view or table in SQL database.
+	// Their order is the order of struct fields (as generated by reform tool), and it is a part of the contract:
+	// struct's Values and Pointers, and select methods' scan targets follow it.
 	Columns() []string
 
 	// NewStruct makes a new struct for that view or table.
@@ -79,9 +202,13 @@ type View interface {
 
 	ToCol(field string) string
 
+	FoldedCol(dialect Dialect, name string) (string, bool)
+
 	Fields() (fields []string)
 
 	IColumns() []interface{}
+
+	OmitEmpty(column string) bool
 }
 
 // Table represents SQL database table with single-column primary key.
@@ -103,11 +230,11 @@ type Struct interface {
 	// String returns a string representation of this struct or record.
 	String() string
 
-	// Values returns a slice of struct or record field values.
+	// Values returns a slice of struct or record field values in the same order as View's Columns.
 	// Returned interface{} values are never untyped nils.
 	Values() []interface{}
 
-	// Pointers returns a slice of pointers to struct or record fields.
+	// Pointers returns a slice of pointers to struct or record fields in the same order as View's Columns.
 	// Returned interface{} values are never untyped nils.
 	Pointers() []interface{}
 
@@ -137,6 +264,75 @@ type Record interface {
 	SetPK(pk interface{})
 }
 
+// AsRecord returns str as Record and true, or nil and false if str is not a Record (for example, it belongs to a view).
+func AsRecord(str Struct) (Record, bool) {
+	record, ok := str.(Record)
+	return record, ok
+}
+
+// MustRecord returns str as Record, or panics if it is not a Record.
+func MustRecord(str Struct) Record {
+	record, ok := str.(Record)
+	if !ok {
+		panic(fmt.Sprintf("reform: %T is not a Record, %s has no primary key", str, str.View().Name()))
+	}
+	return record
+}
+
+// IsTable returns true if view is a Table, i.e. has a primary key.
+func IsTable(view View) bool {
+	_, ok := view.(Table)
+	return ok
+}
+
+// ValuesFiller is an optional interface for Struct which is generated by reform tool.
+// Insert methods use it to fill pooled slices instead of allocating new ones with Values.
+type ValuesFiller interface {
+	Struct
+
+	// ValuesInto appends struct field values to values in the same order as Values returns them,
+	// and returns the extended slice.
+	ValuesInto(values []interface{}) []interface{}
+}
+
+// ColumnOrderer is an optional interface for View which pins the order of columns in multi-row inserts
+// (InsertMulti, InsertMultiReturning and InsertMultiIfNotExists) independently of Columns order.
+// It is not generated by reform tool.
+type ColumnOrderer interface {
+	View
+
+	// ColumnOrder returns all view's column names in the order they should be listed in INSERT statement.
+	ColumnOrder() []string
+}
+
+// CompositeTable is an optional interface for Table with composite primary key.
+// It is not generated by reform tool. FindByPrimaryKeysTo, FindByPrimaryKeyFrom and Reload use it
+// to query table by all primary key columns.
+type CompositeTable interface {
+	Table
+
+	// PKColumnIndexes returns indexes of primary key columns for that table in SQL database, in key order.
+	PKColumnIndexes() []uint
+}
+
+// CompositeRecord is an optional interface for Record with composite primary key.
+// It is not generated by reform tool. Reload uses it to pass all primary key values.
+type CompositeRecord interface {
+	Record
+
+	// PKValues returns values of primary key columns for that record in the same order as table's PKColumnIndexes.
+	// Returned interface{} values are never untyped nils.
+	PKValues() []interface{}
+}
+
+// pkColumnIndexes returns indexes of table's primary key columns.
+func pkColumnIndexes(table Table) []uint {
+	if ct, ok := table.(CompositeTable); ok {
+		return ct.PKColumnIndexes()
+	}
+	return []uint{table.PKColumnIndex()}
+}
+
 // BeforeInserter is an optional interface for Record which is used by Querier.Insert.
 // It can be used to set record's timestamp fields, convert timezones, change data precision, etc.
 // Returning error aborts operation.
@@ -151,6 +347,14 @@ type BeforeUpdater interface {
 	BeforeUpdate() error
 }
 
+// AfterInserter is an optional interface for Struct which is used by Querier.Insert, Querier.InsertMulti
+// and Querier.InsertMultiReturning after rows are inserted (and primary key fields are filled, if they are).
+// It can be used to publish domain events, invalidate caches, etc.
+// Returned error is returned by operation, but does not revert it; use a transaction for that.
+type AfterInserter interface {
+	AfterInsert() error
+}
+
 // AfterFinder is an optional interface for Record which is used by Querier's finders and selectors.
 // It can be used to convert timezones, change data precision, etc.
 // Returning error aborts operation.
@@ -158,6 +362,77 @@ type AfterFinder interface {
 	AfterFind() error
 }
 
+// BeforeInserterQ is an optional interface for Record which is used by Querier.Insert.
+// It is preferred over BeforeInserter and receives the Querier which performs the operation,
+// so hook can make additional queries in the same transaction.
+// Returning error aborts operation.
+type BeforeInserterQ interface {
+	BeforeInsertQ(q *Querier) error
+}
+
+// BeforeUpdaterQ is an optional interface for Record which is used by Querier.Update and Querier.UpdateColumns.
+// It is preferred over BeforeUpdater and receives the Querier which performs the operation,
+// so hook can make additional queries in the same transaction.
+// Returning error aborts operation.
+type BeforeUpdaterQ interface {
+	BeforeUpdateQ(q *Querier) error
+}
+
+// AfterFinderQ is an optional interface for Record which is used by Querier's finders and selectors.
+// It is preferred over AfterFinder and receives the Querier which performs the operation,
+// so hook can make additional queries in the same transaction.
+// Returning error aborts operation.
+type AfterFinderQ interface {
+	AfterFindQ(q *Querier) error
+}
+
+// AfterFinderRows is an optional interface for Struct which is used by Querier's finders and selectors.
+// It is preferred over AfterFinderQ and AfterFinder. Querier.NextRow passes values of extra result columns
+// which follow struct's columns (for example, from JOIN), keyed by column names; other methods pass nil.
+// It can be used to hydrate related data in one pass. Returning error aborts operation.
+type AfterFinderRows interface {
+	AfterFindRows(extra map[string]interface{}) error
+}
+
+// BulkHooker is an optional interface for View which is used by Querier's bulk methods: InsertMulti (op "insert"),
+// UpdateAll (op "update") and DeleteFrom (op "delete"), and methods which use them. Those methods do not call
+// per-struct update and delete hooks, as they do not load affected rows.
+// BeforeBulk is called before the first statement; returning error aborts operation.
+// AfterBulk is called after successful operation with the number of affected rows;
+// returned error is returned by operation, but does not revert it. See also Querier.SkipBulkHooks.
+type BulkHooker interface {
+	View
+
+	BeforeBulk(q *Querier, op string) error
+	AfterBulk(q *Querier, op string, rows uint) error
+}
+
+// EnumValidator is an optional interface for Struct which is used by Querier's insert and update methods.
+// Enums returns a map of column (or field) names to allowed values for that columns.
+// Values are checked after BeforeInserter and BeforeUpdater. NULL values are always allowed.
+// Invalid value aborts operation with InvalidEnumError.
+type EnumValidator interface {
+	Enums() map[string][]string
+}
+
+// Snapshotter is an optional interface for Record which is used by Querier's FindByPrimaryKeyTo and UpdateChanged.
+// FindByPrimaryKeyTo stores a copy of loaded values with SetSnapshot, UpdateChanged compares current values with it.
+type Snapshotter interface {
+	Snapshot() []interface{}
+	SetSnapshot(values []interface{})
+}
+
+// ColumnTransformer converts column values on the way to and from SQL database.
+// It is registered with Querier's Transformers and can be used for cross-cutting concerns like encryption.
+type ColumnTransformer struct {
+	// ToDB converts a value from Struct's field before it is written by insert and update methods.
+	ToDB func(value interface{}) (interface{}, error)
+
+	// FromDB converts a scanned value before it is stored to Struct's field.
+	// Returned value should be assignable to field.
+	FromDB func(value interface{}) (interface{}, error)
+}
+
 // DBTX is an interface for database connection or transaction.
 // It's implemented by *sql.DB, *sql.Tx, *DB, *TX and *Querier.
 type DBTX interface {
@@ -186,6 +461,16 @@ const (
 
 	// OutputInserted is method using "OUTPUT INSERTED.id" SQL syntax.
 	OutputInserted
+
+	// ThenReturn is method using "THEN RETURN id" SQL syntax.
+	ThenReturn
+
+	// FinalTable is method using "SELECT id FROM FINAL TABLE (INSERT ...)" SQL syntax.
+	FinalTable
+
+	// NoLastInsertId is used when database has no way to return primary key of inserted row.
+	// It is not filled by insert methods.
+	NoLastInsertId
 )
 
 // SelectLimitMethod is a method of limiting the number of rows in a query result.
@@ -197,6 +482,90 @@ const (
 
 	// SelectTop is a method using "SELECT TOP N" SQL syntax.
 	SelectTop
+
+	// FetchFirst is a method using ANSI "FETCH FIRST N ROWS ONLY" SQL syntax
+	// (and "OFFSET M ROWS FETCH NEXT N ROWS ONLY" for pages).
+	FetchFirst
+)
+
+// LockForUpdateMethod is a method of locking selected rows until the end of transaction.
+type LockForUpdateMethod int
+
+const (
+	// ForUpdate is a method using "SELECT ... FOR UPDATE" SQL syntax.
+	ForUpdate LockForUpdateMethod = iota
+
+	// UpdLock is a method using "SELECT ... FROM table WITH (UPDLOCK)" SQL syntax.
+	UpdLock
+
+	// NoLockForUpdate is a method for databases without row locks, rows are not locked explicitly.
+	NoLockForUpdate
+)
+
+// SliceArgMethod is a method of passing slice arguments for "IN" conditions.
+type SliceArgMethod int
+
+const (
+	// ExpandSliceArg is a method expanding slice argument into a list of placeholders, one per element.
+	ExpandSliceArg SliceArgMethod = iota
+
+	// ArraySliceArg is a method passing slice argument as a single array parameter with "= ANY(...)" SQL syntax.
+	ArraySliceArg
+)
+
+// NullsOrderingMethod is a method of specifying a position of NULL values in "ORDER BY".
+type NullsOrderingMethod int
+
+const (
+	// NullsFirstLast is a method using "NULLS FIRST" and "NULLS LAST" SQL syntax.
+	NullsFirstLast NullsOrderingMethod = iota
+
+	// NullsCase is a method emulating it with "CASE WHEN column IS NULL" expression.
+	NullsCase
+)
+
+// InputOrderMethod is a method of ordering rows to match the order of given values, see FindAllFromPKInOrder.
+type InputOrderMethod int
+
+const (
+	// ArrayPosition is a method using "ORDER BY array_position(ARRAY[...], column)" SQL syntax.
+	ArrayPosition InputOrderMethod = iota
+
+	// FieldFunc is a method using "ORDER BY FIELD(column, ...)" SQL syntax.
+	FieldFunc
+
+	// OrderCase is a method using "ORDER BY CASE column WHEN ... THEN 0 ... END" expression.
+	OrderCase
+)
+
+// NamedArgMethod is a method of passing named arguments (sql.NamedArg) to database driver, see NamedArgsTail.
+type NamedArgMethod int
+
+const (
+	// NoNamedArgs is used when database driver does not support named arguments.
+	// They are replaced with placeholder parameters.
+	NoNamedArgs NamedArgMethod = iota
+
+	// AtNamedArgs is a method using "@name" SQL syntax with sql.NamedArg arguments.
+	AtNamedArgs
+)
+
+// UpsertMethod is a method of inserting a row or updating existing conflicting row.
+type UpsertMethod int
+
+const (
+	// OnConflict is a method using "ON CONFLICT (...) DO UPDATE" SQL syntax.
+	OnConflict UpsertMethod = iota
+
+	// OnDuplicateKeyUpdate is a method using "ON DUPLICATE KEY UPDATE" SQL syntax.
+	// It ignores conflict columns and uses all unique indexes.
+	OnDuplicateKeyUpdate
+
+	// Merge is a method using "MERGE" statement.
+	Merge
+
+	// NoUpsert is used by dialects which do not support upsert.
+	NoUpsert
 )
 
 // DefaultValuesMethod is a method of inserting of row with all default values.
@@ -210,6 +579,17 @@ const (
 	EmptyLists
 )
 
+// ColumnDefaultMethod is a method of inserting column's default value, see Default.
+type ColumnDefaultMethod int
+
+const (
+	// DefaultKeyword is a method using "DEFAULT" keyword instead of placeholder.
+	DefaultKeyword ColumnDefaultMethod = iota
+
+	// OmitColumn is a method omitting column from INSERT statement.
+	OmitColumn
+)
+
 // Dialect represents differences in various SQL dialects.
 type Dialect interface {
 	// Placeholder returns representation of placeholder parameter for given index,
@@ -222,8 +602,13 @@ type Dialect interface {
 
 	// QuoteIdentifier returns quoted database identifier,
 	// typically "identifier" or `identifier`.
+	// Qualified identifier like schema.table is quoted part by part, typically as "schema"."table".
 	QuoteIdentifier(identifier string) string
 
+	// FoldIdentifier returns unquoted database identifier as it is folded by database,
+	// typically identifier as is or lowercased.
+	FoldIdentifier(identifier string) string
+
 	// LastInsertIdMethod returns a method of receiving primary key of last inserted row.
 	LastInsertIdMethod() LastInsertIdMethod
 
@@ -232,6 +617,103 @@ type Dialect interface {
 
 	// DefaultValuesMethod returns a method of inserting of row with all default values.
 	DefaultValuesMethod() DefaultValuesMethod
+
+	// ColumnDefaultMethod returns a method of inserting column's default value.
+	ColumnDefaultMethod() ColumnDefaultMethod
+
+	// LockForUpdateMethod returns a method of locking selected rows until the end of transaction.
+	LockForUpdateMethod() LockForUpdateMethod
+
+	// MaxPlaceholders returns the maximum number of placeholder parameters in a single query,
+	// or 0 if there is no known limit.
+	MaxPlaceholders() int
+
+	// SliceArgMethod returns a method of passing slice arguments for "IN" conditions.
+	SliceArgMethod() SliceArgMethod
+
+	// NullsOrderingMethod returns a method of specifying a position of NULL values in "ORDER BY".
+	NullsOrderingMethod() NullsOrderingMethod
+
+	// InputOrderMethod returns a method of ordering rows to match the order of given values.
+	InputOrderMethod() InputOrderMethod
+
+	// NamedArgMethod returns a method of passing named arguments to database driver.
+	NamedArgMethod() NamedArgMethod
+
+	// UpsertMethod returns a method of inserting a row or updating existing conflicting row.
+	UpsertMethod() UpsertMethod
+
+	// SupportsReturning returns true if dialect supports "RETURNING" clause in INSERT, UPDATE and DELETE statements.
+	SupportsReturning() bool
+
+	// SupportsOutput returns true if dialect supports "OUTPUT" clause in INSERT, UPDATE, DELETE and MERGE statements.
+	SupportsOutput() bool
+
+	// SupportsOnConflict returns true if dialect supports "ON CONFLICT" clause in INSERT statement.
+	// It should be true if and only if UpsertMethod is OnConflict.
+	SupportsOnConflict() bool
+
+	// SupportsMerge returns true if dialect supports "MERGE" statement as used by upsert methods.
+	// It should be true if and only if UpsertMethod is Merge.
+	SupportsMerge() bool
+
+	// SupportsUpdateFromValues returns true if dialect supports "UPDATE ... FROM (VALUES ...)" statement
+	// (see Querier.UpdateMultiFromValues).
+	SupportsUpdateFromValues() bool
+
+	// IsConnectionError returns true if err is a connection-level error,
+	// after which connection should be re-established. See DB.WithReconnect.
+	IsConnectionError(err error) bool
+
+	// BoolLiteral returns representation of boolean literal for use in queries,
+	// typically TRUE/FALSE or 1/0.
+	BoolLiteral(b bool) string
+
+	// ExplainPrefix returns a prefix for SELECT statement which makes database return its execution plan
+	// instead of result, like "EXPLAIN ", or "EXPLAIN ANALYZE " if analyze is true and it is supported.
+	// For dialects which enable execution plan output with session option (like MS SQL Server's SHOWPLAN),
+	// it returns "SET <option> ON" statement, which is executed separately before query.
+	// Empty string is returned if execution plan can't be obtained with SQL.
+	ExplainPrefix(analyze bool) string
+
+	// GoquAdapter returns a name of goqu adapter for this dialect, used by Querier.Dataset,
+	// or empty string if goqu has no adapter for it (default one is used then).
+	// Dialect's package registers that adapter.
+	GoquAdapter() string
+}
+
+// QuoteIdentifierParts splits qualified identifier (like schema.table) by dots and wraps each part
+// in open and close quote characters. Parts which are already quoted are kept as is, and dots inside them
+// are not treated as separators; doubled close character inside them is treated as escaped one.
+// It is used by dialects to implement QuoteIdentifier.
+func QuoteIdentifierParts(identifier string, open, close byte) string {
+	var parts []string
+	start := 0
+	inQuote := false
+	for i := 0; i < len(identifier); i++ {
+		c := identifier[i]
+		switch {
+		case !inQuote && i == start && c == open:
+			inQuote = true
+		case inQuote && c == close:
+			if i+1 < len(identifier) && identifier[i+1] == close {
+				i++ // escaped close character
+				continue
+			}
+			inQuote = false
+		case !inQuote && c == '.':
+			parts = append(parts, identifier[start:i])
+			start = i + 1
+		}
+	}
+	parts = append(parts, identifier[start:])
+
+	for i, p := range parts {
+		if len(p) < 2 || p[0] != open || p[len(p)-1] != close {
+			parts[i] = string(open) + p + string(close)
+		}
+	}
+	return strings.Join(parts, ".")
 }
 
 // check interface
@@ -1,11 +1,14 @@
 package reform_test
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,11 +25,16 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/empirefox/reform"
+	"github.com/empirefox/reform/dialects/db2"
 	"github.com/empirefox/reform/dialects/mssql"
 	"github.com/empirefox/reform/dialects/mysql"
 	"github.com/empirefox/reform/dialects/postgresql"
+	"github.com/empirefox/reform/dialects/redshift"
+	"github.com/empirefox/reform/dialects/spanner"
 	"github.com/empirefox/reform/dialects/sqlite3"
+	"github.com/empirefox/reform/dialects/testdialect"
 	"github.com/empirefox/reform/internal/test/models"
+	"github.com/empirefox/reform/parse"
 )
 
 var (
@@ -113,6 +121,79 @@ func setIdentityInsert(t *testing.T, tx *reform.TX, table string, allow bool) {
 	require.NoError(t, err)
 }
 
+// errFake is returned by fakeDB for all queries.
+var errFake = errors.New("fake error")
+
+// fakeDB is a DBInterface test double which records queries and returns errFake for all of them.
+// Its QueryRow method should not be used.
+type fakeDB struct {
+	queries []string
+}
+
+func (f *fakeDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	f.queries = append(f.queries, query)
+	return nil, errFake
+}
+
+func (f *fakeDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	f.queries = append(f.queries, query)
+	return nil, errFake
+}
+
+func (f *fakeDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	f.queries = append(f.queries, query)
+	panic("fakeDB.QueryRow should not be used")
+}
+
+func (f *fakeDB) Begin() (*sql.Tx, error) {
+	return nil, errFake
+}
+
+// queryRowDB is a fakeDB which runs QueryRow on real db.
+type queryRowDB struct {
+	fakeDB
+	db reform.DBTX
+}
+
+func (f *queryRowDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	f.queries = append(f.queries, query)
+	return f.db.QueryRow(query, args...)
+}
+
+// fetchFirstDialect is a Dialect with FetchFirst SelectLimitMethod.
+type fetchFirstDialect struct {
+	reform.Dialect
+}
+
+func (fetchFirstDialect) SelectLimitMethod() reform.SelectLimitMethod {
+	return reform.FetchFirst
+}
+
+// fakeTX is a TXInterface test double which records queries to fakeDB.
+type fakeTX struct {
+	db *fakeDB
+}
+
+func (f fakeTX) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return f.db.Exec(query, args...)
+}
+
+func (f fakeTX) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return f.db.Query(query, args...)
+}
+
+func (f fakeTX) QueryRow(query string, args ...interface{}) *sql.Row {
+	return f.db.QueryRow(query, args...)
+}
+
+func (fakeTX) Commit() error {
+	return nil
+}
+
+func (fakeTX) Rollback() error {
+	return nil
+}
+
 type ReformSuite struct {
 	suite.Suite
 	q *reform.TX
@@ -197,6 +278,60 @@ func (s *ReformSuite) TestHasPK() {
 	s.True(project.HasPK())
 }
 
+// dbWithoutPing is a DBInterface test double without PingContext and Stats methods.
+type dbWithoutPing struct {
+	reform.DBTX
+}
+
+func (dbWithoutPing) Begin() (*sql.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *ReformSuite) TestPing() {
+	// release the only connection
+	err := s.q.Rollback()
+	s.Require().NoError(err)
+	s.q = nil
+
+	s.NoError(DB.Ping())
+	s.NoError(DB.PingContext(context.Background()))
+	s.Equal(1, DB.Stats().OpenConnections)
+
+	db := reform.NewDBFromInterface(dbWithoutPing{DB}, DB.Dialect, nil)
+	s.EqualError(db.Ping(), "reform: DBInterface does not support PingContext")
+	s.Equal(sql.DBStats{}, db.Stats())
+}
+
+func (s *ReformSuite) TestFoldedCol() {
+	col, ok := models.PersonTable.FoldedCol(s.q.Dialect, "name")
+	s.True(ok)
+	s.Equal("name", col)
+
+	col, ok = models.PersonTable.FoldedCol(s.q.Dialect, "NAME")
+	if s.q.Dialect == postgresql.Dialect {
+		s.True(ok)
+		s.Equal("name", col)
+	} else {
+		s.False(ok)
+	}
+
+	_, ok = models.PersonTable.FoldedCol(s.q.Dialect, "no_such_column")
+	s.False(ok)
+}
+
+func (s *ReformSuite) TestExpand() {
+	query := "SELECT $Name, $email FROM people WHERE $ID = $1 AND $Unknown = $12"
+	expected := "SELECT name, email FROM people WHERE id = $1 AND Unknown = $12"
+	s.Equal(expected, reform.Expand(models.PersonTable, query))
+	s.Equal(expected, s.q.Expand(models.PersonTable, query))
+
+	var name string
+	query = s.q.Expand(models.PersonTable, "SELECT $Name FROM people WHERE $ID = "+s.q.Placeholder(1))
+	err := s.q.QueryRow(query, 1).Scan(&name)
+	s.NoError(err)
+	s.Equal("Denis Mills", name)
+}
+
 func (s *ReformSuite) TestPlaceholders() {
 	if s.q.Dialect != postgresql.Dialect {
 		s.T().Skip("PostgreSQL-specific test")
@@ -206,6 +341,344 @@ func (s *ReformSuite) TestPlaceholders() {
 	s.Equal([]string{"$2", "$3", "$4", "$5", "$6"}, s.q.Placeholders(2, 5))
 }
 
+func (s *ReformSuite) TestBuildWhere() {
+	pg := reform.NewDBFromInterface(new(fakeDB), postgresql.Dialect, nil)
+	where, args, err := pg.BuildWhere(models.PersonTable, 3, map[string]interface{}{"Email": (*string)(nil), "name": "Elfrieda Abbott"})
+	s.NoError(err)
+	s.Equal(`"name" = $3 AND "email" IS NULL`, where)
+	s.Equal([]interface{}{"Elfrieda Abbott"}, args)
+
+	_, _, err = pg.BuildWhere(models.PersonTable, 1, nil)
+	s.EqualError(err, "reform: BuildWhere requires at least one condition")
+	_, _, err = pg.BuildWhere(models.PersonTable, 1, map[string]interface{}{"unknown": 1})
+	s.EqualError(err, "reform: unexpected columns: [unknown]")
+
+	// compose with own parameters
+	where, args, err = s.q.BuildWhere(models.PersonTable, 3, map[string]interface{}{"name": "Elfrieda Abbott", "email": nil})
+	s.Require().NoError(err)
+	tail := fmt.Sprintf("WHERE id >= %s AND id <= %s AND %s", s.q.Placeholder(1), s.q.Placeholder(2), where)
+	structs, err := s.q.SelectAllFrom(models.PersonTable, tail, append([]interface{}{100, 200}, args...)...)
+	s.NoError(err)
+	s.Len(structs, 1)
+	s.Equal(int32(103), structs[0].(*models.Person).ID)
+}
+
+func (s *ReformSuite) TestBuildInsert() {
+	pg := reform.NewDBFromInterface(new(fakeDB), postgresql.Dialect, nil)
+	person := &models.Person{Name: "Built Person"}
+	query, args, err := pg.BuildInsert(person, 2)
+	s.NoError(err)
+	s.Equal(`INSERT INTO "people" ("group_id", "name", "email", "created_at", "updated_at") VALUES ($2, $3, $4, $5, $6)`, query)
+	s.Len(args, 5)
+	s.False(person.CreatedAt.IsZero(), "BeforeInsert should be called")
+
+	query, args, err = s.q.BuildInsert(&models.Person{Name: "Built Person"}, 1)
+	s.Require().NoError(err)
+	_, err = s.q.Exec(query, args...)
+	s.NoError(err)
+	str, err := s.q.FindOneFrom(models.PersonTable, "name", "Built Person")
+	s.NoError(err)
+	s.NotZero(str.(*models.Person).ID)
+}
+
+func (s *ReformSuite) TestRecordHelpers() {
+	person := new(models.Person)
+	record, ok := reform.AsRecord(person)
+	s.True(ok)
+	s.Equal(person, record)
+	s.Equal(person, reform.MustRecord(person))
+	s.True(reform.IsTable(models.PersonTable))
+
+	pp := new(models.PersonProject)
+	record, ok = reform.AsRecord(pp)
+	s.False(ok)
+	s.Nil(record)
+	s.PanicsWithValue("reform: *models.PersonProject is not a Record, person_project has no primary key", func() {
+		reform.MustRecord(pp)
+	})
+	s.False(reform.IsTable(models.PersonProjectView))
+}
+
+func (s *ReformSuite) TestViewBaseCaseInsensitive() {
+	info, err := parse.Object(new(models.Person), "", "people")
+	s.Require().NoError(err)
+
+	v := reform.NewViewBase(info)
+	s.Equal("group_id", v.ToCol("GroupID"))
+	s.Equal("group_id", v.ToCol("group_id"))
+	s.Equal("groupID", v.ToCol("groupID"))
+	_, ok := v.HasCol("GROUP_ID")
+	s.False(ok)
+
+	v = reform.NewViewBase(info, reform.CaseInsensitive)
+	for _, field := range []string{"GroupID", "group_id", "groupID", "groupid", "GROUP_ID"} {
+		col, ok := v.HasCol(field)
+		s.True(ok, "%s", field)
+		s.Equal("group_id", col, "%s", field)
+	}
+	s.Equal("unknown", v.ToCol("unknown"))
+
+	// exact match is preferred, otherwise the first field wins
+	v = reform.NewViewBase(&parse.StructInfo{
+		Type:    "Collision",
+		SQLName: "collision",
+		Fields: []parse.FieldInfo{
+			{Name: "URL", Column: "url"},
+			{Name: "Url", Column: "url2"},
+		},
+		PKFieldIndex: -1,
+	}, reform.CaseInsensitive)
+	s.Equal("url", v.ToCol("URL"))
+	s.Equal("url2", v.ToCol("Url"))
+	s.Equal("url2", v.ToCol("url2"))
+	s.Equal("url", v.ToCol("uRL"))
+}
+
+func (s *ReformSuite) TestQuoteIdentifier() {
+	for dialect, expected := range map[reform.Dialect]map[string]string{
+		mssql.Dialect: {
+			"col":             "[col]",
+			"schema.table":    "[schema].[table]",
+			"[sche.ma].table": "[sche.ma].[table]",
+			"[a]]b.c].d":      "[a]]b.c].[d]",
+		},
+		mysql.Dialect: {
+			"col":             "`col`",
+			"schema.table":    "`schema`.`table`",
+			"`sche.ma`.table": "`sche.ma`.`table`",
+			"`a``b.c`.d":      "`a``b.c`.`d`",
+		},
+		postgresql.Dialect: {
+			"col":             `"col"`,
+			"schema.table":    `"schema"."table"`,
+			`"sche.ma".table`: `"sche.ma"."table"`,
+			`"a""b.c".d`:      `"a""b.c"."d"`,
+		},
+		sqlite3.Dialect: {
+			"col":             `"col"`,
+			"schema.table":    `"schema"."table"`,
+			`"sche.ma".table`: `"sche.ma"."table"`,
+			`"a""b.c".d`:      `"a""b.c"."d"`,
+		},
+	} {
+		for identifier, quoted := range expected {
+			s.Equal(quoted, dialect.QuoteIdentifier(identifier), "%s", identifier)
+		}
+	}
+}
+
+func (s *ReformSuite) TestBoolLiteral() {
+	expected := map[reform.Dialect][2]string{
+		mssql.Dialect:      {"1", "0"},
+		mysql.Dialect:      {"TRUE", "FALSE"},
+		postgresql.Dialect: {"TRUE", "FALSE"},
+		sqlite3.Dialect:    {"TRUE", "FALSE"},
+	}
+	s.Equal(expected[s.q.Dialect], [2]string{s.q.BoolLiteral(true), s.q.BoolLiteral(false)})
+
+	var b bool
+	err := s.q.QueryRow("SELECT " + s.q.BoolLiteral(true)).Scan(&b)
+	s.NoError(err)
+	s.True(b)
+	err = s.q.QueryRow("SELECT " + s.q.BoolLiteral(false)).Scan(&b)
+	s.NoError(err)
+	s.False(b)
+}
+
+func (s *ReformSuite) TestExplain() {
+	s.Equal("EXPLAIN ", postgresql.Dialect.ExplainPrefix(false))
+	s.Equal("EXPLAIN ANALYZE ", postgresql.Dialect.ExplainPrefix(true))
+	s.Equal("SET SHOWPLAN_TEXT ON", mssql.Dialect.ExplainPrefix(false))
+	s.Equal("SET SHOWPLAN_TEXT ON", mssql.Dialect.ExplainPrefix(true))
+
+	plan, err := s.q.Explain(models.PersonTable, "WHERE id = "+s.q.Placeholder(1), 1)
+	s.NoError(err)
+	s.NotEmpty(plan)
+
+	if s.q.Dialect == postgresql.Dialect {
+		plan, err = s.q.ExplainAnalyze(models.PersonTable, "WHERE id = "+s.q.Placeholder(1), 1)
+		s.NoError(err)
+		s.Contains(plan, "actual time")
+	}
+
+	_, err = reform.NewDBFromInterface(new(fakeDB), spanner.Dialect, nil).Explain(models.PersonTable, "")
+	s.EqualError(err, "reform: Explain is not supported by this dialect")
+}
+
+func (s *ReformSuite) TestQueryRewriter() {
+	var ops []string
+	rewriter := func(op string, query string) string {
+		ops = append(ops, op)
+		return "/* service:orders */ " + query
+	}
+
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, s.q.Dialect, nil)
+	db.QueryRewriter = rewriter
+	_, err := db.SelectAllFrom(models.PersonTable, "WHERE $Name IS NULL")
+	s.Equal(errFake, err)
+	_, err = db.DeleteFrom(models.PersonTable, "")
+	s.Equal(errFake, err)
+	s.Equal([]string{"select", "delete"}, ops)
+	s.Require().Len(fake.queries, 2)
+	s.Contains(fake.queries[0], "/* service:orders */ SELECT ")
+	s.Contains(fake.queries[0], "WHERE name IS NULL")
+	s.Equal("/* service:orders */ DELETE FROM "+s.q.QuoteIdentifier("people")+" ", fake.queries[1])
+
+	ops = nil
+	s.q.QueryRewriter = rewriter
+	_, err = s.q.FindByPrimaryKeyFrom(models.PersonTable, 1)
+	s.NoError(err)
+	s.Equal([]string{"select"}, ops)
+}
+
+func (s *ReformSuite) TestDB2() {
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, db2.Dialect, nil)
+
+	s.Panics(func() { db.FindByPrimaryKeyFrom(models.PersonTable, 1) })
+	s.Panics(func() { db.ExistsByPK(models.PersonTable, 1) })
+	s.Panics(func() { db.Insert(&models.Person{Name: "Alice"}) })
+	_, err := db.SelectAllFrom(models.PersonTable, "WHERE id > ? ORDER BY id FOR UPDATE", 1)
+	s.Equal(errFake, err)
+	s.Require().Len(fake.queries, 4)
+	s.Equal(`SELECT "people"."id", "people"."group_id", "people"."name", "people"."email", "people"."created_at", "people"."updated_at" `+
+		`FROM "people" WHERE "people"."id" = ? FETCH FIRST 1 ROW ONLY`, fake.queries[0])
+	s.Equal(`SELECT 1 FROM "people" WHERE "id" = ? FETCH FIRST 1 ROW ONLY`, fake.queries[1])
+	s.Equal(`SELECT "id" FROM FINAL TABLE (INSERT INTO "people" ("group_id", "name", "email", "created_at", "updated_at") `+
+		`VALUES (?, ?, ?, ?, ?))`, fake.queries[2])
+	s.Contains(fake.queries[3], `FROM "people" WHERE id > ? ORDER BY id FOR UPDATE`)
+
+	fake.queries = nil
+	s.Panics(func() { db.SelectAllAndCount(models.PersonTable, "ORDER BY id", 10, 20) })
+	s.Require().Len(fake.queries, 1)
+	s.Contains(fake.queries[0], `SELECT COUNT(*) FROM "people"`)
+}
+
+func (s *ReformSuite) TestTestDialect() {
+	for dialect, expected := range map[reform.Dialect][]string{
+		testdialect.Dialect: {
+			`SELECT |people|.|id|, |people|.|group_id|, |people|.|name|, |people|.|email|, |people|.|created_at|, |people|.|updated_at| ` +
+				`FROM |people| WHERE |people|.|id| = {1} LIMIT 1`,
+			`INSERT INTO |people| (|group_id|, |name|, |email|, |created_at|, |updated_at|) VALUES ({1}, {2}, {3}, {4}, {5})`,
+			`DELETE FROM |legacy|.|people| WHERE name = {1}`,
+			`SELECT |people|.|id|, |people|.|group_id|, |people|.|name|, |people|.|email|, |people|.|created_at|, |people|.|updated_at| ` +
+				`FROM |people| WHERE id IN ({1}, {2}) AND name <> {3}`,
+		},
+		testdialect.NopDialect: {
+			`SELECT people.id, people.group_id, people.name, people.email, people.created_at, people.updated_at ` +
+				`FROM people WHERE people.id = ? LIMIT 1`,
+			`INSERT INTO people (group_id, name, email, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			`DELETE FROM legacy.people WHERE name = ?`,
+			`SELECT people.id, people.group_id, people.name, people.email, people.created_at, people.updated_at ` +
+				`FROM people WHERE id IN (?, ?) AND name <> ?`,
+		},
+	} {
+		fake := new(fakeDB)
+		db := reform.NewDBFromInterface(fake, dialect, nil)
+		s.Panics(func() { db.FindByPrimaryKeyFrom(models.PersonTable, 1) })
+		s.Equal(errFake, db.Insert(&models.Person{Name: "Alice", CreatedAt: time.Now()}))
+		_, err := db.DeleteFrom(models.LegacyPersonTable, "WHERE name = "+db.Placeholder(1), "Alice")
+		s.Equal(errFake, err)
+		_, err = db.SelectAllFromIn(models.PersonTable, "WHERE id IN ("+db.Placeholder(1)+") AND name <> "+db.Placeholder(2),
+			[]int32{1, 2}, "Alice")
+		s.Equal(errFake, err)
+		s.Equal(expected, fake.queries)
+	}
+}
+
+func (s *ReformSuite) TestDialectCapabilities() {
+	for _, tc := range []struct {
+		dialect                                              reform.Dialect
+		returning, output, onConflict, mergeStmt, updateFrom bool
+	}{
+		{postgresql.Dialect, true, false, true, false, true},
+		{mysql.Dialect, false, false, false, false, false},
+		{sqlite3.Dialect, false, false, true, false, false},
+		{mssql.Dialect, false, true, false, true, false},
+		{redshift.Dialect, false, false, false, false, false},
+		{spanner.Dialect, false, false, false, false, false},
+		{db2.Dialect, false, false, false, false, false},
+	} {
+		name := fmt.Sprintf("%T", tc.dialect)
+		s.Equal(tc.returning, tc.dialect.SupportsReturning(), "%s", name)
+		s.Equal(tc.output, tc.dialect.SupportsOutput(), "%s", name)
+		s.Equal(tc.onConflict, tc.dialect.SupportsOnConflict(), "%s", name)
+		s.Equal(tc.mergeStmt, tc.dialect.SupportsMerge(), "%s", name)
+		s.Equal(tc.updateFrom, tc.dialect.SupportsUpdateFromValues(), "%s", name)
+
+		// upsert methods switch on UpsertMethod
+		s.Equal(tc.dialect.UpsertMethod() == reform.OnConflict, tc.dialect.SupportsOnConflict(), "%s", name)
+		s.Equal(tc.dialect.UpsertMethod() == reform.Merge, tc.dialect.SupportsMerge(), "%s", name)
+	}
+}
+
+func (s *ReformSuite) TestQueryRewriterShardHint() {
+	// Citus-style hint for router queries: distribution column value is passed as a comment
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, postgresql.Dialect, nil)
+	db.QueryRewriter = func(op string, query string) string {
+		if op != "insert" {
+			return query
+		}
+		return query + " /* citus: shard_key=102 */"
+	}
+	err := db.Insert(&models.PersonProject{PersonID: 102, ProjectID: "traveler"})
+	s.Equal(errFake, err)
+	_, err = db.DeleteFrom(models.PersonProjectView, "WHERE person_id = $1", 102)
+	s.Equal(errFake, err)
+	s.Equal([]string{
+		`INSERT INTO "person_project" ("person_id", "project_id") VALUES ($1, $2) /* citus: shard_key=102 */`,
+		`DELETE FROM "person_project" WHERE person_id = $1`,
+	}, fake.queries)
+}
+
+// countMetrics is a reform.Metrics test double which counts operations and errors.
+type countMetrics struct {
+	ops    map[string]int
+	errors map[string]int
+}
+
+func (m *countMetrics) IncOp(op, table string) {
+	m.ops[op+" "+table]++
+}
+
+func (m *countMetrics) IncError(op, table string) {
+	m.errors[op+" "+table]++
+}
+
+func (m *countMetrics) ObserveDuration(op, table string, d time.Duration) {}
+
+func (s *ReformSuite) TestMetrics() {
+	m := &countMetrics{ops: make(map[string]int), errors: make(map[string]int)}
+	s.q.Metrics = m
+
+	person := &models.Person{Name: "Alice"}
+	s.NoError(s.q.Insert(person))
+	s.NoError(s.q.Update(person))
+	s.NoError(s.q.Reload(person))
+	_, err := s.q.FindByPrimaryKeyFrom(models.PersonTable, -1)
+	s.Equal(reform.ErrNoRows, err)
+	_, err = s.q.SelectAllFrom(models.ProjectTable, "WHERE invalid_tail")
+	s.Error(err)
+	s.NoError(s.q.Delete(person))
+	s.Equal(reform.ErrNoPK, s.q.Delete(new(models.Person)))
+	_, _, err = s.q.SelectAllAndCount(models.PersonTable, "ORDER BY id", 1, 0)
+	s.NoError(err)
+	_, err = s.q.DsExec(models.PersonTable, "UPDATE people SET name = name WHERE id = -1")
+	s.NoError(err)
+
+	s.Equal(map[string]int{
+		"insert people":   1,
+		"update people":   1,
+		"select people":   4,
+		"select projects": 1,
+		"delete people":   2,
+		"exec people":     1,
+	}, m.ops)
+	s.Equal(map[string]int{"select projects": 1, "delete people": 1}, m.errors)
+}
+
 func (s *ReformSuite) TestInTransaction() {
 	setIdentityInsert(s.T(), s.q, "people", true)
 
@@ -244,6 +717,322 @@ func (s *ReformSuite) TestInTransaction() {
 	s.NoError(err)
 }
 
+// dbWithBeginTx is a DBInterface test double which records BeginTx options.
+type dbWithBeginTx struct {
+	dbWithoutPing
+	opts *sql.TxOptions
+}
+
+func (db *dbWithBeginTx) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	db.opts = opts
+	return nil, errFake
+}
+
+func (s *ReformSuite) TestInTransactionOpts() {
+	err := s.q.Rollback()
+	s.Require().NoError(err)
+	s.q = nil
+
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	ctx := context.Background()
+
+	fake := &dbWithBeginTx{dbWithoutPing: dbWithoutPing{DB}}
+	err = reform.NewDBFromInterface(fake, DB.Dialect, nil).InTransactionOpts(ctx, opts, func(tx *reform.TX) error {
+		s.Fail("should not be called")
+		return nil
+	})
+	s.Equal(errFake, err)
+	s.Equal(opts, fake.opts)
+
+	err = reform.NewDBFromInterface(dbWithoutPing{DB}, DB.Dialect, nil).InTransactionOpts(ctx, opts, nil)
+	s.EqualError(err, "reform: DBInterface does not support BeginTx")
+
+	err = DB.InTransactionOpts(ctx, opts, func(tx *reform.TX) error {
+		_, err := tx.FindByPrimaryKeyFrom(models.PersonTable, 1)
+		s.NoError(err)
+		return errors.New("epic error")
+	})
+	s.EqualError(err, "epic error")
+
+	err = DB.InTransactionOpts(ctx, opts, func(tx *reform.TX) error {
+		_, err := tx.FindByPrimaryKeyFrom(models.PersonTable, 1)
+		return err
+	})
+	s.NoError(err)
+}
+
+func (s *ReformSuite) TestInTransactionWith() {
+	err := s.q.Rollback()
+	s.Require().NoError(err)
+	s.q = nil
+
+	// stands for "SET LOCAL", which is not supported by all databases
+	person := &models.Person{Name: "Setup", CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	setup := func(tx *reform.TX) error {
+		return tx.Insert(person)
+	}
+	ctx := context.Background()
+
+	err = DB.InTransactionWith(ctx, setup, func(tx *reform.TX) error {
+		p, err := tx.FindByPrimaryKeyFrom(models.PersonTable, person.ID)
+		s.NoError(err)
+		s.Equal(person, p)
+		return errors.New("epic error")
+	})
+	s.EqualError(err, "epic error")
+
+	// setup was rolled back
+	_, err = DB.FindByPrimaryKeyFrom(models.PersonTable, person.ID)
+	s.Equal(reform.ErrNoRows, err)
+
+	err = DB.InTransactionWith(ctx, func(tx *reform.TX) error {
+		return errors.New("setup error")
+	}, func(tx *reform.TX) error {
+		s.Fail("should not be called")
+		return nil
+	})
+	s.EqualError(err, "setup error")
+}
+
+// badConnDB is a DBInterface test double which fails first Exec calls with driver.ErrBadConn
+// and counts pings.
+type badConnDB struct {
+	*fakeDB
+	badConns int
+	pings    int
+}
+
+func (db *badConnDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if db.badConns > 0 {
+		db.badConns--
+		db.queries = append(db.queries, query)
+		return nil, driver.ErrBadConn
+	}
+	return db.fakeDB.Exec(query, args...)
+}
+
+func (db *badConnDB) PingContext(ctx context.Context) error {
+	db.pings++
+	return nil
+}
+
+// multiRowsDB is a DBInterface test double which reports 2 affected rows for any Exec call.
+type multiRowsDB struct {
+	*fakeDB
+}
+
+func (db multiRowsDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	db.queries = append(db.queries, query)
+	return driver.RowsAffected(2), nil
+}
+
+func (s *ReformSuite) TestPanicOnMultiAffected() {
+	db := reform.NewDBFromInterface(multiRowsDB{new(fakeDB)}, mysql.Dialect, nil)
+	person := &models.Person{ID: 1, Name: "Denis Mills"}
+	s.True(db.PanicOnMultiAffected)
+	s.PanicsWithValue("reform: 2 rows by UPDATE by primary key. Please report this bug.", func() { db.Update(person) })
+	s.PanicsWithValue("reform: 2 rows by DELETE by primary key. Please report this bug.", func() { db.Delete(person) })
+
+	db.PanicOnMultiAffected = false
+	s.Equal(reform.ErrMultipleRowsAffected, db.Update(person))
+	s.Equal(reform.ErrMultipleRowsAffected, db.Delete(person))
+}
+
+func (s *ReformSuite) TestWithReconnect() {
+	// recovers on retry
+	fake := &badConnDB{fakeDB: new(fakeDB), badConns: 1}
+	db := reform.NewDBFromInterface(fake, s.q.Dialect, nil).WithReconnect(reform.ReconnectPolicy{Retry: true})
+	_, err := db.Exec("DELETE FROM people")
+	s.Equal(errFake, err)
+	s.Equal(1, fake.pings)
+	s.Equal([]string{"DELETE FROM people", "DELETE FROM people"}, fake.queries)
+
+	// fails twice
+	fake = &badConnDB{fakeDB: new(fakeDB), badConns: 2}
+	db = reform.NewDBFromInterface(fake, s.q.Dialect, nil).WithReconnect(reform.ReconnectPolicy{Retry: true})
+	_, err = db.Exec("DELETE FROM people")
+	s.Equal(driver.ErrBadConn, err)
+	s.Equal(1, fake.pings)
+	s.Len(fake.queries, 2)
+
+	// without retry
+	fake = &badConnDB{fakeDB: new(fakeDB), badConns: 1}
+	db = reform.NewDBFromInterface(fake, s.q.Dialect, nil).WithReconnect(reform.ReconnectPolicy{})
+	_, err = db.Exec("DELETE FROM people")
+	s.Equal(driver.ErrBadConn, err)
+	s.Equal(1, fake.pings)
+	s.NoError(db.Ping())
+	s.Equal(2, fake.pings)
+
+	// not a connection error
+	fake = &badConnDB{fakeDB: new(fakeDB)}
+	db = reform.NewDBFromInterface(fake, s.q.Dialect, nil).WithReconnect(reform.ReconnectPolicy{Retry: true})
+	_, err = db.Exec("DELETE FROM people")
+	s.Equal(errFake, err)
+	s.Equal(0, fake.pings)
+	s.Len(fake.queries, 1)
+
+	s.True(s.q.IsConnectionError(driver.ErrBadConn))
+	s.False(s.q.IsConnectionError(errFake))
+}
+
+func (s *ReformSuite) TestWithTx() {
+	metrics := new(countMetrics)
+	logger := reform.NewPrintfLogger(s.T().Logf)
+	q := reform.NewDB(nil, s.q.Dialect, logger).Querier
+	q.Metrics = metrics
+
+	tq := q.WithTx(new(sql.Tx))
+	s.False(tq == q)
+	s.Equal(s.q.Dialect, tq.Dialect)
+	s.True(tq.Logger == logger)
+	s.True(tq.Metrics == metrics)
+}
+
+func (s *ReformSuite) TestReplicatedDB() {
+	primary, replica1, replica2 := new(fakeDB), new(fakeDB), new(fakeDB)
+	db := reform.NewReplicatedDB(reform.NewDBFromInterface(primary, postgresql.Dialect, nil), replica1, replica2)
+
+	for i := 0; i < 3; i++ {
+		_, err := db.Reader().SelectAllFrom(models.PersonTable, "")
+		s.Equal(errFake, err)
+	}
+	s.Len(replica1.queries, 2)
+	s.Len(replica2.queries, 1)
+	s.Empty(primary.queries)
+
+	err := db.Writer().Insert(&models.PersonProject{PersonID: 1, ProjectID: "baron"})
+	s.Equal(errFake, err)
+	_, err = db.Writer().DeleteFrom(models.PersonTable, "")
+	s.Equal(errFake, err)
+	s.Len(primary.queries, 2)
+	s.Contains(primary.queries[0], "INSERT INTO")
+	s.Contains(primary.queries[1], "DELETE FROM")
+
+	// transactions use primary
+	_, err = db.Begin()
+	s.Equal(errFake, err)
+
+	db.Picker = func(n int) int { return n - 1 }
+	_, err = db.Reader().SelectAllFrom(models.PersonTable, "")
+	s.Equal(errFake, err)
+	s.Len(replica2.queries, 2)
+	s.Equal(0, reform.RandomPicker(1))
+
+	// without replicas reads use primary
+	db = reform.NewReplicatedDB(reform.NewDBFromInterface(primary, postgresql.Dialect, nil))
+	s.True(db.Reader() == db.Writer())
+}
+
+func (s *ReformSuite) TestWithContext() {
+	s.Equal(context.Background(), s.q.Context())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := s.q.WithContext(ctx)
+	s.False(q == s.q.Querier)
+	s.Equal(ctx, q.Context())
+	s.Equal(context.Background(), s.q.Context())
+
+	_, err := q.FindByPrimaryKeyFrom(models.PersonTable, 1)
+	s.NoError(err)
+
+	cancel()
+	_, err = q.FindByPrimaryKeyFrom(models.PersonTable, 1)
+	s.Equal(context.Canceled, err)
+	_, err = q.SelectAllFrom(models.PersonTable, "")
+	s.Equal(context.Canceled, err)
+	err = q.Insert(&models.Person{Name: "Alice"})
+	s.Equal(context.Canceled, err)
+	s.True(reform.IsCancelled(err))
+
+	// other instances are not affected
+	_, err = s.q.FindByPrimaryKeyFrom(models.PersonTable, 1)
+	s.NoError(err)
+
+	s.PanicsWithValue("reform: nil context", func() { s.q.WithContext(nil) })
+}
+
+func (s *ReformSuite) TestSchemaOverride() {
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, postgresql.Dialect, nil)
+	db.SchemaOverride = "tenant_42"
+	s.Equal(`"tenant_42"."people"`, db.QualifiedView(models.PersonTable))
+
+	_, err := db.SelectAllFrom(models.PersonTable, "WHERE id = $1", 1)
+	s.Equal(errFake, err)
+	db.SchemaOverride = ""
+	_, err = db.SelectAllFrom(models.PersonTable, "WHERE id = $1", 1)
+	s.Equal(errFake, err)
+
+	columns := `"people"."id", "people"."group_id", "people"."name", "people"."email", ` +
+		`"people"."created_at", "people"."updated_at"`
+	s.Equal([]string{
+		`SELECT ` + strings.Replace(columns, `"people"`, `"tenant_42"."people"`, -1) +
+			` FROM "tenant_42"."people" WHERE id = $1`,
+		`SELECT ` + columns + ` FROM "people" WHERE id = $1`,
+	}, fake.queries)
+}
+
+func (s *ReformSuite) TestWithTableName() {
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, postgresql.Dialect, nil)
+	_, err := db.SelectAllFromNamed(models.PersonTable, "people_2025", "WHERE id > $1", 1)
+	s.Equal(errFake, err)
+	err = db.InsertInto(&models.PersonProject{PersonID: 1, ProjectID: "baron"}, "person_project_2025")
+	s.Equal(errFake, err)
+
+	q, err := db.WithTableName(models.PersonTable, "people_2025")
+	s.Require().NoError(err)
+	s.Panics(func() { q.FindByPrimaryKeyFrom(models.PersonTable, 1) })
+	_, err = q.SelectAllFrom(models.ProjectTable, "")
+	s.Equal(errFake, err)
+
+	columns := `"people_2025"."id", "people_2025"."group_id", "people_2025"."name", "people_2025"."email", ` +
+		`"people_2025"."created_at", "people_2025"."updated_at"`
+	s.Equal([]string{
+		`SELECT ` + columns + ` FROM "people_2025" WHERE id > $1`,
+		`INSERT INTO "person_project_2025" ("person_id", "project_id") VALUES ($1, $2)`,
+		`SELECT ` + columns + ` FROM "people_2025" WHERE "people_2025"."id" = $1 LIMIT 1`,
+		`SELECT "projects"."name", "projects"."id", "projects"."start", "projects"."end" FROM "projects" `,
+	}, fake.queries)
+
+	// original name is used without override
+	fake.queries = nil
+	_, err = db.SelectAllFrom(models.PersonTable, "")
+	s.Equal(errFake, err)
+	s.Require().Len(fake.queries, 1)
+	s.Contains(fake.queries[0], `FROM "people" `)
+
+	for _, name := range []string{"", "people; DROP TABLE people", `people"`, "public.people", "2025_people"} {
+		_, err = db.SelectAllFromNamed(models.PersonTable, name, "")
+		s.EqualError(err, fmt.Sprintf("reform: unsafe table name %q", name))
+	}
+}
+
+func (s *ReformSuite) TestLocation() {
+	vlat, err := time.LoadLocation("Asia/Vladivostok")
+	s.Require().NoError(err)
+	hst, err := time.LoadLocation("US/Hawaii")
+	s.Require().NoError(err)
+
+	created := time.Now().In(hst).Truncate(time.Second)
+	s.q.Location = vlat
+	person := &models.DefaultedPerson{Name: "location", CreatedAt: created}
+	err = s.q.Insert(person)
+	s.Require().NoError(err)
+	s.Equal(hst, person.CreatedAt.Location()) // struct is not changed
+
+	err = s.q.Reload(person)
+	s.Require().NoError(err)
+	s.Equal(vlat, person.CreatedAt.Location())
+	s.True(created.Equal(person.CreatedAt), "%s != %s", created, person.CreatedAt)
+
+	s.q.Location = nil
+	err = s.q.Reload(person)
+	s.Require().NoError(err)
+	s.True(created.Equal(person.CreatedAt), "%s != %s", created, person.CreatedAt)
+}
+
 func (s *ReformSuite) TestTimezones() {
 	setIdentityInsert(s.T(), s.q, "people", true)
 
@@ -0,0 +1,195 @@
+package reform
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// maxPooledBufferSize is a maximum capacity of query buffer returned to the pool,
+// so occasional huge queries (like InsertMulti ones) do not pin memory.
+const maxPooledBufferSize = 64 * 1024
+
+// bufferPool is a pool of buffers used to build queries.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns buffer to the pool.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBufferSize {
+		bufferPool.Put(buf)
+	}
+}
+
+// valuesPool is a pool of slices used for values of ValuesFiller structs by insert methods.
+var valuesPool = sync.Pool{
+	New: func() interface{} {
+		return new([]interface{})
+	},
+}
+
+// getValues returns str's values. If str implements ValuesFiller, they are stored in a pooled slice
+// which should be returned with putValues after use; otherwise, returned pointer is nil.
+func getValues(str Struct) ([]interface{}, *[]interface{}) {
+	vf, ok := str.(ValuesFiller)
+	if !ok {
+		return str.Values(), nil
+	}
+
+	p := valuesPool.Get().(*[]interface{})
+	*p = vf.ValuesInto((*p)[:0])
+	return *p, p
+}
+
+// putValues clears slice returned by getValues, so values are not retained, and returns it to the pool.
+// It does nothing for nil p.
+func putValues(p *[]interface{}) {
+	if p == nil {
+		return
+	}
+	values := (*p)[:cap(*p)]
+	for i := range values {
+		values[i] = nil
+	}
+	*p = values[:0]
+	valuesPool.Put(p)
+}
+
+// writeJoined writes elements of a separated by sep to buf, like strings.Join does.
+func writeJoined(buf *bytes.Buffer, a []string, sep string) {
+	for i, s := range a {
+		if i != 0 {
+			buf.WriteString(sep)
+		}
+		buf.WriteString(s)
+	}
+}
+
+// writePlaceholders writes count comma-separated placeholders starting from start to buf,
+// like strings.Join(q.Placeholders(start, count), ", ") does.
+func (q *Querier) writePlaceholders(buf *bytes.Buffer, start, count int) {
+	for i := 0; i < count; i++ {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(q.Placeholder(start + i))
+	}
+}
+
+// writeQualifiedView writes quoted qualified view name to buf, like QualifiedView does.
+func (q *Querier) writeQualifiedView(buf *bytes.Buffer, view View) {
+	if schema := q.viewSchema(view); schema != "" {
+		buf.WriteString(q.QuoteIdentifier(schema))
+		buf.WriteByte('.')
+	}
+	buf.WriteString(q.QuoteIdentifier(q.viewName(view)))
+}
+
+// isNil returns true if v is nil or nil pointer.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// BuildInsert returns INSERT query for str and its arguments without executing it,
+// so it can be composed into a larger query (for example, a CTE).
+// Placeholders are numbered from start, so they can follow caller's own parameters;
+// start is ignored for dialects with not numbered placeholders (like "?").
+// Columns are selected like Insert does. BeforeInsertQ or BeforeInsert hooks are called and enums are checked,
+// but primary key field is not filled.
+func (q *Querier) BuildInsert(str Struct, start int) (string, []interface{}, error) {
+	if err := q.beforeInsert(str); err != nil {
+		return "", nil, err
+	}
+
+	view := str.View()
+	columns, values := insertColumnsAndValues(str, str.Values())
+	if err := q.toDB(view, columns, values); err != nil {
+		return "", nil, err
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString("INSERT INTO ")
+	q.writeQualifiedView(buf, view)
+	if len(columns) == 0 && q.DefaultValuesMethod() != EmptyLists {
+		buf.WriteString(" DEFAULT VALUES")
+		return buf.String(), nil, nil
+	}
+	buf.WriteString(" (")
+	for i, c := range columns {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(q.QuoteIdentifier(c))
+	}
+	buf.WriteString(") VALUES (")
+	q.writePlaceholders(buf, start, len(columns))
+	buf.WriteByte(')')
+	return buf.String(), values, nil
+}
+
+// BuildWhere returns conditions for given columns (or fields) and values joined with " AND ",
+// and arguments for them, without "WHERE" keyword, so they can be composed into a larger query.
+// Conditions are in view's columns order; nil values (including nil pointers) produce "IS NULL" conditions without arguments.
+// Placeholders are numbered from start, so they can follow caller's own parameters;
+// start is ignored for dialects with not numbered placeholders (like "?").
+func (q *Querier) BuildWhere(view View, start int, conds map[string]interface{}) (string, []interface{}, error) {
+	if len(conds) == 0 {
+		return "", nil, fmt.Errorf("reform: BuildWhere requires at least one condition")
+	}
+
+	condCols := make(map[string]interface{}, len(conds))
+	for c, v := range conds {
+		col, ok := view.HasCol(c)
+		if !ok {
+			return "", nil, fmt.Errorf("reform: unexpected columns: [%s]", c)
+		}
+		condCols[col] = v
+	}
+
+	// keep columns order
+	columns := make([]string, 0, len(condCols))
+	values := make([]interface{}, 0, len(condCols))
+	for _, c := range view.Columns() {
+		if v, ok := condCols[c]; ok {
+			columns = append(columns, c)
+			values = append(values, v)
+		}
+	}
+	if err := q.toDB(view, columns, values); err != nil {
+		return "", nil, err
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	args := make([]interface{}, 0, len(values))
+	for i, c := range columns {
+		if i != 0 {
+			buf.WriteString(" AND ")
+		}
+		buf.WriteString(q.QuoteIdentifier(c))
+		if isNil(values[i]) {
+			buf.WriteString(" IS NULL")
+			continue
+		}
+		buf.WriteString(" = ")
+		buf.WriteString(q.Placeholder(start + len(args)))
+		args = append(args, values[i])
+	}
+	return buf.String(), args, nil
+}
@@ -0,0 +1,118 @@
+//go:build go1.9
+// +build go1.9
+
+package reform
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// connDBTX is a DBTX which runs queries on a single *sql.Conn with given context.
+// Context methods use their own context instead (see Querier.WithContext).
+type connDBTX struct {
+	ctx  context.Context
+	conn *sql.Conn
+}
+
+func (c connDBTX) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.conn.ExecContext(c.ctx, query, args...)
+}
+
+func (c connDBTX) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.conn.QueryContext(c.ctx, query, args...)
+}
+
+func (c connDBTX) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.conn.QueryRowContext(c.ctx, query, args...)
+}
+
+func (c connDBTX) Prepare(query string) (*sql.Stmt, error) {
+	return c.conn.PrepareContext(c.ctx, query)
+}
+
+func (c connDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.conn.ExecContext(ctx, query, args...)
+}
+
+func (c connDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.conn.QueryContext(ctx, query, args...)
+}
+
+func (c connDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.conn.QueryRowContext(ctx, query, args...)
+}
+
+func (c connDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return c.conn.PrepareContext(ctx, query)
+}
+
+// inTransaction implements Querier's inTransaction for q which runs queries on this connection:
+// f is called with Querier for a new transaction started on it.
+func (c connDBTX) inTransaction(q *Querier, f func(q *Querier) error) error {
+	ctx := c.ctx
+	if q.ctx != nil {
+		ctx = q.ctx
+	}
+	tx, err := (&Conn{Querier: q, conn: c.conn}).BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return runInTransaction(tx, func(t *TX) error {
+		return f(t.Querier)
+	})
+}
+
+// Conn represents a single database connection pinned from DB's pool.
+// All queries run on the same connection, so session-scoped state (like temporary tables,
+// session variables or advisory locks) is preserved between them.
+type Conn struct {
+	*Querier
+	conn *sql.Conn
+}
+
+// Conn returns a single connection from DB's pool. Given context is used for all queries on it.
+// Connection must be returned to the pool with Close.
+// It returns error if DB was created with DBInterface without Conn method.
+func (db *DB) Conn(ctx context.Context) (*Conn, error) {
+	c, ok := db.db.(interface {
+		Conn(ctx context.Context) (*sql.Conn, error)
+	})
+	if !ok {
+		return nil, errors.New("reform: DBInterface does not support Conn")
+	}
+
+	conn, err := c.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{
+		Querier: db.Querier.withDBTX(connDBTX{ctx: ctx, conn: conn}),
+		conn:    conn,
+	}, nil
+}
+
+// BeginTx starts a transaction on this connection with given context and options.
+func (c *Conn) BeginTx(ctx context.Context, opts *sql.TxOptions) (*TX, error) {
+	start := time.Now()
+	c.logBefore("BEGIN", nil)
+	tx, err := c.conn.BeginTx(ctx, opts)
+	c.logAfter("BEGIN", nil, time.Now().Sub(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return &TX{
+		Querier: c.Querier.withDBTX(tx),
+		tx:      tx,
+	}, nil
+}
+
+// Close returns connection to DB's pool. Session-scoped state is not reset by reform.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// check interface
+var _ DBTX = new(Conn)
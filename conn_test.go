@@ -0,0 +1,60 @@
+//go:build go1.9
+// +build go1.9
+
+package reform_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/empirefox/reform"
+	"github.com/empirefox/reform/dialects/postgresql"
+	"github.com/empirefox/reform/internal/test/models"
+)
+
+func (s *ReformSuite) TestConn() {
+	_, err := reform.NewDBFromInterface(new(fakeDB), s.q.Dialect, nil).Conn(context.Background())
+	s.EqualError(err, "reform: DBInterface does not support Conn")
+
+	// release the only connection
+	err = s.q.Rollback()
+	s.Require().NoError(err)
+	s.q = nil
+
+	conn, err := DB.Conn(context.Background())
+	s.Require().NoError(err)
+	defer func() {
+		s.NoError(conn.Close())
+	}()
+
+	person, err := conn.FindByPrimaryKeyFrom(models.PersonTable, 1)
+	s.NoError(err)
+	s.Equal("Denis Mills", person.(*models.Person).Name)
+
+	// chunked InsertMulti fails on the second statement, and the first one is rolled back
+	conn.MaxInsertMultiRows = 1
+	err = conn.InsertMulti(
+		&models.Project{ID: "conn", Name: "Conn", Start: time.Now()},
+		&models.Project{ID: "conn", Name: "Conn again", Start: time.Now()},
+	)
+	s.Error(err)
+	_, err = conn.FindByPrimaryKeyFrom(models.ProjectTable, "conn")
+	s.Equal(reform.ErrNoRows, err)
+
+	if conn.Dialect != postgresql.Dialect {
+		return
+	}
+
+	// advisory lock is held by connection between queries
+	_, err = conn.Exec("SELECT pg_advisory_lock(42)")
+	s.Require().NoError(err)
+	var n int
+	query := "SELECT COUNT(*) FROM pg_locks WHERE locktype = 'advisory' AND objid = 42 AND pid = pg_backend_pid()"
+	s.NoError(conn.QueryRow(query).Scan(&n))
+	s.Equal(1, n)
+	var unlocked bool
+	s.NoError(conn.QueryRow("SELECT pg_advisory_unlock(42)").Scan(&unlocked))
+	s.True(unlocked)
+	s.NoError(conn.QueryRow(query).Scan(&n))
+	s.Equal(0, n)
+}
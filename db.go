@@ -1,7 +1,9 @@
 package reform
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"time"
 )
 
@@ -35,6 +37,36 @@ func NewDBFromInterface(db DBInterface, dialect Dialect, logger Logger) *DB {
 	}
 }
 
+// Ping verifies a connection to the database is still alive, establishing a connection if necessary.
+// It returns error if DB was created with DBInterface without PingContext method.
+func (db *DB) Ping() error {
+	return db.PingContext(context.Background())
+}
+
+// PingContext verifies a connection to the database is still alive, establishing a connection if necessary.
+// It returns error if DB was created with DBInterface without PingContext method.
+func (db *DB) PingContext(ctx context.Context) error {
+	p, ok := db.db.(interface {
+		PingContext(ctx context.Context) error
+	})
+	if !ok {
+		return errors.New("reform: DBInterface does not support PingContext")
+	}
+	return p.PingContext(ctx)
+}
+
+// Stats returns database statistics.
+// It returns zero value if DB was created with DBInterface without Stats method.
+func (db *DB) Stats() sql.DBStats {
+	s, ok := db.db.(interface {
+		Stats() sql.DBStats
+	})
+	if !ok {
+		return sql.DBStats{}
+	}
+	return s.Stats()
+}
+
 // Begin starts a transaction.
 func (db *DB) Begin() (*TX, error) {
 	start := time.Now()
@@ -44,7 +76,33 @@ func (db *DB) Begin() (*TX, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewTX(tx, db.Dialect, db.Logger), nil
+	return &TX{
+		Querier: db.Querier.withDBTX(tx),
+		tx:      tx,
+	}, nil
+}
+
+// BeginTx starts a transaction with given context and options.
+// It returns error if DB was created with DBInterface without BeginTx method.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*TX, error) {
+	b, ok := db.db.(interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return nil, errors.New("reform: DBInterface does not support BeginTx")
+	}
+
+	start := time.Now()
+	db.logBefore("BEGIN", nil)
+	tx, err := b.BeginTx(ctx, opts)
+	db.logAfter("BEGIN", nil, time.Now().Sub(start), err)
+	if err != nil {
+		return nil, err
+	}
+	return &TX{
+		Querier: db.Querier.withDBTX(tx),
+		tx:      tx,
+	}, nil
 }
 
 // InTransaction wraps function execution in transaction, rolling back it in case of error or panic,
@@ -54,7 +112,40 @@ func (db *DB) InTransaction(f func(t *TX) error) error {
 	if err != nil {
 		return err
 	}
+	return runInTransaction(tx, f)
+}
+
+// InTransactionOpts is like InTransaction, but starts transaction with BeginTx with given context and options,
+// so isolation level and read-only mode can be set.
+func (db *DB) InTransactionOpts(ctx context.Context, opts *sql.TxOptions, f func(t *TX) error) error {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return runInTransaction(tx, f)
+}
 
+// InTransactionWith is like InTransactionOpts with default options, but calls setup before f
+// in the same transaction. It can be used to set transaction-scoped session state before queries,
+// for example, with "SET LOCAL app.tenant_id = ..." for PostgreSQL row-level security policies,
+// as it is guaranteed to be executed on the same connection. If setup returns error, f is not called,
+// and transaction is rolled back.
+func (db *DB) InTransactionWith(ctx context.Context, setup func(t *TX) error, f func(t *TX) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return runInTransaction(tx, func(t *TX) error {
+		if err := setup(t); err != nil {
+			return err
+		}
+		return f(t)
+	})
+}
+
+// runInTransaction calls f with started transaction tx, rolling back it in case of error or panic,
+// committing otherwise.
+func runInTransaction(tx *TX, f func(t *TX) error) error {
 	var committed bool
 	defer func() {
 		if !committed {
@@ -63,7 +154,7 @@ func (db *DB) InTransaction(f func(t *TX) error) error {
 		}
 	}()
 
-	err = f(tx)
+	err := f(tx)
 	if err == nil {
 		err = tx.Commit()
 	}
@@ -0,0 +1,119 @@
+// Package db2 implements reform.Dialect for IBM Db2.
+package db2 // import "github.com/empirefox/reform/dialects/db2"
+
+import (
+	"strings"
+
+	"github.com/empirefox/reform"
+)
+
+type db2 struct{}
+
+func (db2) Placeholder(index int) string {
+	return "?"
+}
+
+func (db2) Placeholders(start, count int) []string {
+	res := make([]string, count)
+	for i := 0; i < count; i++ {
+		res[i] = "?"
+	}
+	return res
+}
+
+func (db2) QuoteIdentifier(identifier string) string {
+	return reform.QuoteIdentifierParts(identifier, '"', '"')
+}
+
+func (db2) FoldIdentifier(identifier string) string {
+	return strings.ToUpper(identifier)
+}
+
+func (db2) LastInsertIdMethod() reform.LastInsertIdMethod {
+	return reform.FinalTable
+}
+
+func (db2) SelectLimitMethod() reform.SelectLimitMethod {
+	return reform.FetchFirst
+}
+
+func (db2) DefaultValuesMethod() reform.DefaultValuesMethod {
+	return reform.DefaultValues
+}
+
+func (db2) ColumnDefaultMethod() reform.ColumnDefaultMethod {
+	return reform.DefaultKeyword
+}
+
+func (db2) LockForUpdateMethod() reform.LockForUpdateMethod {
+	return reform.ForUpdate
+}
+
+func (db2) MaxPlaceholders() int {
+	return 32767
+}
+
+func (db2) SliceArgMethod() reform.SliceArgMethod {
+	return reform.ExpandSliceArg
+}
+
+func (db2) NullsOrderingMethod() reform.NullsOrderingMethod {
+	return reform.NullsFirstLast
+}
+
+func (db2) InputOrderMethod() reform.InputOrderMethod {
+	return reform.OrderCase
+}
+
+func (db2) NamedArgMethod() reform.NamedArgMethod {
+	return reform.NoNamedArgs
+}
+
+func (db2) UpsertMethod() reform.UpsertMethod {
+	return reform.NoUpsert
+}
+
+func (db2) SupportsReturning() bool {
+	return false
+}
+
+func (db2) SupportsOutput() bool {
+	return false
+}
+
+func (d db2) SupportsOnConflict() bool {
+	return d.UpsertMethod() == reform.OnConflict
+}
+
+func (d db2) SupportsMerge() bool {
+	return d.UpsertMethod() == reform.Merge
+}
+
+func (db2) SupportsUpdateFromValues() bool {
+	return false
+}
+
+func (db2) IsConnectionError(err error) bool {
+	return reform.IsConnectionError(err)
+}
+
+func (db2) BoolLiteral(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (db2) ExplainPrefix(analyze bool) string {
+	return ""
+}
+
+func (db2) GoquAdapter() string {
+	return ""
+}
+
+// Dialect implements reform.Dialect for IBM Db2.
+var Dialect db2
+
+// check interface
+var _ reform.Dialect = Dialect
@@ -18,7 +18,11 @@ func (mssql) Placeholders(start, count int) []string {
 }
 
 func (mssql) QuoteIdentifier(identifier string) string {
-	return "[" + identifier + "]"
+	return reform.QuoteIdentifierParts(identifier, '[', ']')
+}
+
+func (mssql) FoldIdentifier(identifier string) string {
+	return identifier
 }
 
 func (mssql) LastInsertIdMethod() reform.LastInsertIdMethod {
@@ -33,6 +37,78 @@ func (mssql) DefaultValuesMethod() reform.DefaultValuesMethod {
 	return reform.DefaultValues
 }
 
+func (mssql) ColumnDefaultMethod() reform.ColumnDefaultMethod {
+	return reform.DefaultKeyword
+}
+
+func (mssql) LockForUpdateMethod() reform.LockForUpdateMethod {
+	return reform.UpdLock
+}
+
+func (mssql) MaxPlaceholders() int {
+	// limit is 2100 parameters, but sp_executesql's own @stmt and @params count toward it
+	return 2098
+}
+
+func (mssql) SliceArgMethod() reform.SliceArgMethod {
+	return reform.ExpandSliceArg
+}
+
+func (mssql) NullsOrderingMethod() reform.NullsOrderingMethod {
+	return reform.NullsCase
+}
+
+func (mssql) InputOrderMethod() reform.InputOrderMethod {
+	return reform.OrderCase
+}
+
+func (mssql) NamedArgMethod() reform.NamedArgMethod {
+	return reform.AtNamedArgs
+}
+
+func (mssql) UpsertMethod() reform.UpsertMethod {
+	return reform.Merge
+}
+
+func (mssql) SupportsReturning() bool {
+	return false
+}
+
+func (mssql) SupportsOutput() bool {
+	return true
+}
+
+func (d mssql) SupportsOnConflict() bool {
+	return d.UpsertMethod() == reform.OnConflict
+}
+
+func (d mssql) SupportsMerge() bool {
+	return d.UpsertMethod() == reform.Merge
+}
+
+func (mssql) SupportsUpdateFromValues() bool {
+	return false
+}
+
+func (mssql) IsConnectionError(err error) bool {
+	return reform.IsConnectionError(err)
+}
+
+func (mssql) BoolLiteral(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+func (mssql) ExplainPrefix(analyze bool) string {
+	return "SET SHOWPLAN_TEXT ON"
+}
+
+func (mssql) GoquAdapter() string {
+	return ""
+}
+
 // Dialect implements reform.Dialect for Microsoft SQL Server.
 var Dialect mssql
 
@@ -2,6 +2,8 @@
 package mysql // import "github.com/empirefox/reform/dialects/mysql"
 
 import (
+	_ "gopkg.in/doug-martin/goqu.v3/adapters/mysql"
+
 	"github.com/empirefox/reform"
 )
 
@@ -20,7 +22,11 @@ func (mysql) Placeholders(start, count int) []string {
 }
 
 func (mysql) QuoteIdentifier(identifier string) string {
-	return "`" + identifier + "`"
+	return reform.QuoteIdentifierParts(identifier, '`', '`')
+}
+
+func (mysql) FoldIdentifier(identifier string) string {
+	return identifier
 }
 
 func (mysql) LastInsertIdMethod() reform.LastInsertIdMethod {
@@ -35,6 +41,81 @@ func (mysql) DefaultValuesMethod() reform.DefaultValuesMethod {
 	return reform.EmptyLists
 }
 
+func (mysql) ColumnDefaultMethod() reform.ColumnDefaultMethod {
+	return reform.DefaultKeyword
+}
+
+func (mysql) LockForUpdateMethod() reform.LockForUpdateMethod {
+	return reform.ForUpdate
+}
+
+func (mysql) MaxPlaceholders() int {
+	return 65535
+}
+
+func (mysql) SliceArgMethod() reform.SliceArgMethod {
+	return reform.ExpandSliceArg
+}
+
+func (mysql) NullsOrderingMethod() reform.NullsOrderingMethod {
+	return reform.NullsCase
+}
+
+func (mysql) InputOrderMethod() reform.InputOrderMethod {
+	return reform.FieldFunc
+}
+
+func (mysql) NamedArgMethod() reform.NamedArgMethod {
+	return reform.NoNamedArgs
+}
+
+func (mysql) UpsertMethod() reform.UpsertMethod {
+	return reform.OnDuplicateKeyUpdate
+}
+
+func (mysql) SupportsReturning() bool {
+	return false
+}
+
+func (mysql) SupportsOutput() bool {
+	return false
+}
+
+func (d mysql) SupportsOnConflict() bool {
+	return d.UpsertMethod() == reform.OnConflict
+}
+
+func (d mysql) SupportsMerge() bool {
+	return d.UpsertMethod() == reform.Merge
+}
+
+func (mysql) SupportsUpdateFromValues() bool {
+	return false
+}
+
+func (mysql) IsConnectionError(err error) bool {
+	// github.com/go-sql-driver/mysql's ErrInvalidConn
+	return reform.IsConnectionError(err) || err.Error() == "invalid connection"
+}
+
+func (mysql) BoolLiteral(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (mysql) ExplainPrefix(analyze bool) string {
+	if analyze {
+		return "EXPLAIN ANALYZE "
+	}
+	return "EXPLAIN "
+}
+
+func (mysql) GoquAdapter() string {
+	return "mysql"
+}
+
 // Dialect implements reform.Dialect for MySQL.
 var Dialect mysql
 
@@ -0,0 +1,70 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/empirefox/reform"
+)
+
+// CopyFrom copies structs into table with PostgreSQL's "COPY ... FROM STDIN" command
+// and returns a number of copied rows. It is much faster than Querier.InsertMulti for large amounts of rows.
+//
+// It works only inside a transaction and with drivers which support COPY via prepared statements,
+// like github.com/lib/pq. All structs should belong to table, and all records should either have or not have primary key set. If records do not have primary keys set,
+// primary key column is omitted. Unlike Querier.InsertMulti, it does not call BeforeInserter
+// and does not check enum values.
+func CopyFrom(tx *reform.TX, table reform.Table, structs ...reform.Struct) (int64, error) {
+	if tx.Dialect != Dialect {
+		return 0, fmt.Errorf("postgresql: CopyFrom requires PostgreSQL dialect, got %T", tx.Dialect)
+	}
+	if len(structs) == 0 {
+		return 0, nil
+	}
+
+	record, _ := structs[0].(reform.Record)
+	for _, str := range structs {
+		if str.View() != table {
+			return 0, fmt.Errorf("postgresql: different tables in CopyFrom: %s and %s", table.Name(), str.View().Name())
+		}
+		if rec, _ := str.(reform.Record); record != nil && record.HasPK() != rec.HasPK() {
+			return 0, fmt.Errorf("postgresql: PK in present in one struct and absent in other: first: %s, second: %s",
+				record, rec)
+		}
+	}
+
+	cutPK := record != nil && !record.HasPK()
+	pk := int(table.PKColumnIndex())
+
+	var columns []string
+	for i, c := range table.Columns() {
+		if cutPK && i == pk {
+			continue
+		}
+		columns = append(columns, Dialect.QuoteIdentifier(c))
+	}
+
+	query := fmt.Sprintf("COPY %s (%s) FROM STDIN", tx.QualifiedView(table), strings.Join(columns, ", "))
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, str := range structs {
+		values := str.Values()
+		if cutPK {
+			values = append(values[:pk], values[pk+1:]...)
+		}
+		if _, err = stmt.Exec(values...); err != nil {
+			return 0, err
+		}
+	}
+
+	// empty Exec flushes buffered rows
+	res, err := stmt.Exec()
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
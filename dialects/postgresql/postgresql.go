@@ -3,6 +3,9 @@ package postgresql // import "github.com/empirefox/reform/dialects/postgresql"
 
 import (
 	"strconv"
+	"strings"
+
+	_ "gopkg.in/doug-martin/goqu.v3/adapters/postgres"
 
 	"github.com/empirefox/reform"
 )
@@ -22,7 +25,11 @@ func (postgresql) Placeholders(start, count int) []string {
 }
 
 func (postgresql) QuoteIdentifier(identifier string) string {
-	return `"` + identifier + `"`
+	return reform.QuoteIdentifierParts(identifier, '"', '"')
+}
+
+func (postgresql) FoldIdentifier(identifier string) string {
+	return strings.ToLower(identifier)
 }
 
 func (postgresql) LastInsertIdMethod() reform.LastInsertIdMethod {
@@ -37,6 +44,101 @@ func (postgresql) DefaultValuesMethod() reform.DefaultValuesMethod {
 	return reform.DefaultValues
 }
 
+func (postgresql) ColumnDefaultMethod() reform.ColumnDefaultMethod {
+	return reform.DefaultKeyword
+}
+
+func (postgresql) LockForUpdateMethod() reform.LockForUpdateMethod {
+	return reform.ForUpdate
+}
+
+func (postgresql) MaxPlaceholders() int {
+	return 65535
+}
+
+func (postgresql) SliceArgMethod() reform.SliceArgMethod {
+	return reform.ArraySliceArg
+}
+
+func (postgresql) NullsOrderingMethod() reform.NullsOrderingMethod {
+	return reform.NullsFirstLast
+}
+
+func (postgresql) InputOrderMethod() reform.InputOrderMethod {
+	return reform.ArrayPosition
+}
+
+func (postgresql) NamedArgMethod() reform.NamedArgMethod {
+	return reform.NoNamedArgs
+}
+
+func (postgresql) UpsertMethod() reform.UpsertMethod {
+	return reform.OnConflict
+}
+
+func (postgresql) SupportsReturning() bool {
+	return true
+}
+
+func (postgresql) SupportsOutput() bool {
+	return false
+}
+
+func (d postgresql) SupportsOnConflict() bool {
+	return d.UpsertMethod() == reform.OnConflict
+}
+
+func (d postgresql) SupportsMerge() bool {
+	return d.UpsertMethod() == reform.Merge
+}
+
+func (postgresql) SupportsUpdateFromValues() bool {
+	return true
+}
+
+func (postgresql) IsConnectionError(err error) bool {
+	return reform.IsConnectionError(err) || IsConnectionSQLState(err)
+}
+
+func (postgresql) BoolLiteral(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (postgresql) ExplainPrefix(analyze bool) string {
+	if analyze {
+		return "EXPLAIN ANALYZE "
+	}
+	return "EXPLAIN "
+}
+
+func (postgresql) GoquAdapter() string {
+	return "postgres"
+}
+
+// IsConnectionSQLState returns true if err has SQLSTATE (returned by SQLState method, like lib/pq's errors do)
+// of "connection exception" class or of administrator's shutdown.
+func IsConnectionSQLState(err error) bool {
+	e, ok := err.(interface {
+		SQLState() string
+	})
+	if !ok {
+		return false
+	}
+
+	state := e.SQLState()
+	switch {
+	case strings.HasPrefix(state, "08"):
+		return true
+	case state == "57P01", state == "57P02", state == "57P03":
+		return true
+	default:
+		return false
+	}
+}
+
 // Dialect implements reform.Dialect for PostgreSQL.
 var Dialect postgresql
 
@@ -0,0 +1,127 @@
+// Package redshift implements reform.Dialect for Amazon Redshift.
+//
+// Redshift uses PostgreSQL protocol, but does not support RETURNING clause and has no way to get
+// primary key of last inserted row. Insert does not fill primary key fields, so set them explicitly
+// or use InsertMulti for bulk inserts.
+package redshift // import "github.com/empirefox/reform/dialects/redshift"
+
+import (
+	"strconv"
+	"strings"
+
+	_ "gopkg.in/doug-martin/goqu.v3/adapters/postgres"
+
+	"github.com/empirefox/reform"
+	"github.com/empirefox/reform/dialects/postgresql"
+)
+
+type redshift struct{}
+
+func (redshift) Placeholder(index int) string {
+	return "$" + strconv.Itoa(index)
+}
+
+func (redshift) Placeholders(start, count int) []string {
+	res := make([]string, count)
+	for i := 0; i < count; i++ {
+		res[i] = "$" + strconv.Itoa(start+i)
+	}
+	return res
+}
+
+func (redshift) QuoteIdentifier(identifier string) string {
+	return reform.QuoteIdentifierParts(identifier, '"', '"')
+}
+
+func (redshift) FoldIdentifier(identifier string) string {
+	return strings.ToLower(identifier)
+}
+
+func (redshift) LastInsertIdMethod() reform.LastInsertIdMethod {
+	return reform.NoLastInsertId
+}
+
+func (redshift) SelectLimitMethod() reform.SelectLimitMethod {
+	return reform.Limit
+}
+
+func (redshift) DefaultValuesMethod() reform.DefaultValuesMethod {
+	return reform.DefaultValues
+}
+
+func (redshift) ColumnDefaultMethod() reform.ColumnDefaultMethod {
+	return reform.DefaultKeyword
+}
+
+func (redshift) LockForUpdateMethod() reform.LockForUpdateMethod {
+	return reform.NoLockForUpdate
+}
+
+func (redshift) MaxPlaceholders() int {
+	return 65535
+}
+
+func (redshift) SliceArgMethod() reform.SliceArgMethod {
+	return reform.ExpandSliceArg
+}
+
+func (redshift) NullsOrderingMethod() reform.NullsOrderingMethod {
+	return reform.NullsFirstLast
+}
+
+func (redshift) InputOrderMethod() reform.InputOrderMethod {
+	return reform.OrderCase
+}
+
+func (redshift) NamedArgMethod() reform.NamedArgMethod {
+	return reform.NoNamedArgs
+}
+
+func (redshift) UpsertMethod() reform.UpsertMethod {
+	return reform.NoUpsert
+}
+
+func (redshift) SupportsReturning() bool {
+	return false
+}
+
+func (redshift) SupportsOutput() bool {
+	return false
+}
+
+func (d redshift) SupportsOnConflict() bool {
+	return d.UpsertMethod() == reform.OnConflict
+}
+
+func (d redshift) SupportsMerge() bool {
+	return d.UpsertMethod() == reform.Merge
+}
+
+func (redshift) SupportsUpdateFromValues() bool {
+	return false
+}
+
+func (redshift) IsConnectionError(err error) bool {
+	return reform.IsConnectionError(err) || postgresql.IsConnectionSQLState(err)
+}
+
+func (redshift) BoolLiteral(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (redshift) ExplainPrefix(analyze bool) string {
+	return "EXPLAIN "
+}
+
+func (redshift) GoquAdapter() string {
+	return "postgres"
+}
+
+// Dialect implements reform.Dialect for Amazon Redshift.
+var Dialect redshift
+
+// check interface
+var _ reform.Dialect = Dialect
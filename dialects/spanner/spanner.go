@@ -0,0 +1,123 @@
+// Package spanner implements reform.Dialect for Google Cloud Spanner (GoogleSQL dialect).
+//
+// Queries use positional "@p1"-style parameters, which database/sql drivers for Spanner
+// (like github.com/googleapis/go-sql-spanner) bind to arguments in order,
+// so no named arguments are required.
+package spanner // import "github.com/empirefox/reform/dialects/spanner"
+
+import (
+	"strconv"
+
+	"github.com/empirefox/reform"
+)
+
+type spanner struct{}
+
+func (spanner) Placeholder(index int) string {
+	return "@p" + strconv.Itoa(index)
+}
+
+func (spanner) Placeholders(start, count int) []string {
+	res := make([]string, count)
+	for i := 0; i < count; i++ {
+		res[i] = "@p" + strconv.Itoa(start+i)
+	}
+	return res
+}
+
+func (spanner) QuoteIdentifier(identifier string) string {
+	return reform.QuoteIdentifierParts(identifier, '`', '`')
+}
+
+func (spanner) FoldIdentifier(identifier string) string {
+	return identifier
+}
+
+func (spanner) LastInsertIdMethod() reform.LastInsertIdMethod {
+	return reform.ThenReturn
+}
+
+func (spanner) SelectLimitMethod() reform.SelectLimitMethod {
+	return reform.Limit
+}
+
+func (spanner) DefaultValuesMethod() reform.DefaultValuesMethod {
+	return reform.EmptyLists
+}
+
+func (spanner) ColumnDefaultMethod() reform.ColumnDefaultMethod {
+	return reform.DefaultKeyword
+}
+
+func (spanner) LockForUpdateMethod() reform.LockForUpdateMethod {
+	return reform.ForUpdate
+}
+
+func (spanner) MaxPlaceholders() int {
+	return 950
+}
+
+func (spanner) SliceArgMethod() reform.SliceArgMethod {
+	return reform.ExpandSliceArg
+}
+
+func (spanner) NullsOrderingMethod() reform.NullsOrderingMethod {
+	return reform.NullsFirstLast
+}
+
+func (spanner) InputOrderMethod() reform.InputOrderMethod {
+	return reform.OrderCase
+}
+
+func (spanner) NamedArgMethod() reform.NamedArgMethod {
+	return reform.AtNamedArgs
+}
+
+func (spanner) UpsertMethod() reform.UpsertMethod {
+	return reform.NoUpsert
+}
+
+func (spanner) SupportsReturning() bool {
+	return false
+}
+
+func (spanner) SupportsOutput() bool {
+	return false
+}
+
+func (d spanner) SupportsOnConflict() bool {
+	return d.UpsertMethod() == reform.OnConflict
+}
+
+func (d spanner) SupportsMerge() bool {
+	return d.UpsertMethod() == reform.Merge
+}
+
+func (spanner) SupportsUpdateFromValues() bool {
+	return false
+}
+
+func (spanner) IsConnectionError(err error) bool {
+	return reform.IsConnectionError(err)
+}
+
+func (spanner) BoolLiteral(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (spanner) ExplainPrefix(analyze bool) string {
+	return ""
+}
+
+func (spanner) GoquAdapter() string {
+	return ""
+}
+
+// Dialect implements reform.Dialect for Google Cloud Spanner.
+var Dialect spanner
+
+// check interface
+var _ reform.Dialect = Dialect
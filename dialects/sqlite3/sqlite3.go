@@ -2,6 +2,8 @@
 package sqlite3 // import "github.com/empirefox/reform/dialects/sqlite3"
 
 import (
+	_ "gopkg.in/doug-martin/goqu.v3/adapters/sqlite3"
+
 	"github.com/empirefox/reform"
 )
 
@@ -20,7 +22,11 @@ func (sqlite3) Placeholders(start, count int) []string {
 }
 
 func (sqlite3) QuoteIdentifier(identifier string) string {
-	return `"` + identifier + `"`
+	return reform.QuoteIdentifierParts(identifier, '"', '"')
+}
+
+func (sqlite3) FoldIdentifier(identifier string) string {
+	return identifier
 }
 
 func (sqlite3) LastInsertIdMethod() reform.LastInsertIdMethod {
@@ -35,6 +41,78 @@ func (sqlite3) DefaultValuesMethod() reform.DefaultValuesMethod {
 	return reform.DefaultValues
 }
 
+func (sqlite3) ColumnDefaultMethod() reform.ColumnDefaultMethod {
+	return reform.OmitColumn
+}
+
+func (sqlite3) LockForUpdateMethod() reform.LockForUpdateMethod {
+	return reform.NoLockForUpdate
+}
+
+func (sqlite3) MaxPlaceholders() int {
+	return 999
+}
+
+func (sqlite3) SliceArgMethod() reform.SliceArgMethod {
+	return reform.ExpandSliceArg
+}
+
+func (sqlite3) NullsOrderingMethod() reform.NullsOrderingMethod {
+	return reform.NullsFirstLast
+}
+
+func (sqlite3) InputOrderMethod() reform.InputOrderMethod {
+	return reform.OrderCase
+}
+
+func (sqlite3) NamedArgMethod() reform.NamedArgMethod {
+	return reform.AtNamedArgs
+}
+
+func (sqlite3) UpsertMethod() reform.UpsertMethod {
+	return reform.OnConflict
+}
+
+func (sqlite3) SupportsReturning() bool {
+	// RETURNING requires SQLite 3.35+
+	return false
+}
+
+func (sqlite3) SupportsOutput() bool {
+	return false
+}
+
+func (d sqlite3) SupportsOnConflict() bool {
+	return d.UpsertMethod() == reform.OnConflict
+}
+
+func (d sqlite3) SupportsMerge() bool {
+	return d.UpsertMethod() == reform.Merge
+}
+
+func (sqlite3) SupportsUpdateFromValues() bool {
+	return false
+}
+
+func (sqlite3) IsConnectionError(err error) bool {
+	return reform.IsConnectionError(err)
+}
+
+func (sqlite3) BoolLiteral(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (sqlite3) ExplainPrefix(analyze bool) string {
+	return "EXPLAIN QUERY PLAN "
+}
+
+func (sqlite3) GoquAdapter() string {
+	return "sqlite3"
+}
+
 // Dialect implements reform.Dialect for SQLite3.
 var Dialect sqlite3
 
@@ -0,0 +1,151 @@
+// Package testdialect implements reform.Dialect for unit testing of query generation.
+// Its dialects do not match any real database; use them with fake DBInterface implementations
+// to make assertions on generated SQL independent from placeholders and quoting of real dialects.
+package testdialect // import "github.com/empirefox/reform/dialects/testdialect"
+
+import (
+	"strconv"
+
+	"github.com/empirefox/reform"
+)
+
+type testDialect struct{}
+
+func (testDialect) Placeholder(index int) string {
+	return "{" + strconv.Itoa(index) + "}"
+}
+
+func (testDialect) Placeholders(start, count int) []string {
+	res := make([]string, count)
+	for i := 0; i < count; i++ {
+		res[i] = "{" + strconv.Itoa(start+i) + "}"
+	}
+	return res
+}
+
+func (testDialect) QuoteIdentifier(identifier string) string {
+	return reform.QuoteIdentifierParts(identifier, '|', '|')
+}
+
+func (testDialect) FoldIdentifier(identifier string) string {
+	return identifier
+}
+
+func (testDialect) LastInsertIdMethod() reform.LastInsertIdMethod {
+	return reform.LastInsertId
+}
+
+func (testDialect) SelectLimitMethod() reform.SelectLimitMethod {
+	return reform.Limit
+}
+
+func (testDialect) DefaultValuesMethod() reform.DefaultValuesMethod {
+	return reform.DefaultValues
+}
+
+func (testDialect) ColumnDefaultMethod() reform.ColumnDefaultMethod {
+	return reform.DefaultKeyword
+}
+
+func (testDialect) LockForUpdateMethod() reform.LockForUpdateMethod {
+	return reform.ForUpdate
+}
+
+func (testDialect) MaxPlaceholders() int {
+	return 0
+}
+
+func (testDialect) SliceArgMethod() reform.SliceArgMethod {
+	return reform.ExpandSliceArg
+}
+
+func (testDialect) NullsOrderingMethod() reform.NullsOrderingMethod {
+	return reform.NullsFirstLast
+}
+
+func (testDialect) InputOrderMethod() reform.InputOrderMethod {
+	return reform.OrderCase
+}
+
+func (testDialect) NamedArgMethod() reform.NamedArgMethod {
+	return reform.NoNamedArgs
+}
+
+func (testDialect) UpsertMethod() reform.UpsertMethod {
+	return reform.OnConflict
+}
+
+func (testDialect) SupportsReturning() bool {
+	return false
+}
+
+func (testDialect) SupportsOutput() bool {
+	return false
+}
+
+func (d testDialect) SupportsOnConflict() bool {
+	return d.UpsertMethod() == reform.OnConflict
+}
+
+func (d testDialect) SupportsMerge() bool {
+	return d.UpsertMethod() == reform.Merge
+}
+
+func (testDialect) SupportsUpdateFromValues() bool {
+	return false
+}
+
+func (testDialect) IsConnectionError(err error) bool {
+	return reform.IsConnectionError(err)
+}
+
+func (testDialect) BoolLiteral(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (testDialect) ExplainPrefix(analyze bool) string {
+	return "EXPLAIN "
+}
+
+func (testDialect) GoquAdapter() string {
+	return ""
+}
+
+// nopDialect is testDialect with "?" placeholders and without identifiers quoting.
+type nopDialect struct {
+	testDialect
+}
+
+func (nopDialect) Placeholder(index int) string {
+	return "?"
+}
+
+func (nopDialect) Placeholders(start, count int) []string {
+	res := make([]string, count)
+	for i := 0; i < count; i++ {
+		res[i] = "?"
+	}
+	return res
+}
+
+func (nopDialect) QuoteIdentifier(identifier string) string {
+	return identifier
+}
+
+// Dialect implements reform.Dialect with predictable markers: placeholders are "{1}", "{2}", etc.,
+// and identifiers are quoted as |identifier| (part by part for qualified ones, like |schema|.|table|).
+// Other methods return values of the most common SQL: "LIMIT", "FOR UPDATE", "ON CONFLICT", etc.
+var Dialect testDialect
+
+// NopDialect is like Dialect, but uses "?" placeholders and does not quote identifiers,
+// for the simplest assertions.
+var NopDialect nopDialect
+
+// check interface
+var (
+	_ reform.Dialect = Dialect
+	_ reform.Dialect = NopDialect
+)
@@ -0,0 +1,54 @@
+package reform
+
+import (
+	"context"
+	"net"
+)
+
+// queryCanceledSQLState is SQLSTATE of PostgreSQL's query_canceled error, returned both for cancel requests
+// and for statement_timeout. They can't be reliably distinguished, as error message depends on server's lc_messages.
+const queryCanceledSQLState = "57014"
+
+// sqlState returns SQLSTATE of err or any error it wraps (returned by SQLState method, like lib/pq's errors do),
+// or empty string.
+func sqlState(err error) string {
+	var e interface {
+		SQLState() string
+	}
+	if !errorsAs(err, &e) {
+		return ""
+	}
+	return e.SQLState()
+}
+
+// IsNoRows returns true if err is or wraps ErrNoRows.
+// Unlike direct comparison, it works for errors wrapped with fmt.Errorf's "%w".
+func IsNoRows(err error) bool {
+	return errorsIs(err, ErrNoRows)
+}
+
+// IsCancelled returns true if err is or wraps context.Canceled, or if it is a database error
+// for a cancelled query (like PostgreSQL's query_canceled SQLSTATE).
+// Note that PostgreSQL uses the same SQLSTATE for statement_timeout, so IsTimeout returns true for it too.
+// Such errors are never ErrNoRows.
+func IsCancelled(err error) bool {
+	if errorsIs(err, context.Canceled) {
+		return true
+	}
+	return sqlState(err) == queryCanceledSQLState
+}
+
+// IsTimeout returns true if err is or wraps context.DeadlineExceeded, net.Error with Timeout() true,
+// or if it is a database error for a query which may be cancelled by timeout (like PostgreSQL's query_canceled
+// SQLSTATE, which is used both for statement_timeout and cancel requests, so IsCancelled returns true for it too).
+// Such errors are never ErrNoRows.
+func IsTimeout(err error) bool {
+	if errorsIs(err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	if errorsAs(err, &ne) && ne.Timeout() {
+		return true
+	}
+	return sqlState(err) == queryCanceledSQLState
+}
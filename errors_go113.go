@@ -0,0 +1,18 @@
+//go:build go1.13
+// +build go1.13
+
+package reform
+
+import (
+	"errors"
+)
+
+// errorsIs is errors.Is.
+func errorsIs(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// errorsAs is errors.As.
+func errorsAs(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
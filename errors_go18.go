@@ -0,0 +1,50 @@
+//go:build !go1.13
+// +build !go1.13
+
+package reform
+
+import (
+	"reflect"
+)
+
+// errorsIs is a simplified errors.Is for Go before 1.13: it returns true if err or any error it wraps
+// (returned by Unwrap method) is equal to target.
+func errorsIs(err, target error) bool {
+	if err == nil || target == nil {
+		return err == target
+	}
+
+	comparable := reflect.TypeOf(target).Comparable()
+	for err != nil {
+		if comparable && err == target {
+			return true
+		}
+		err = unwrap(err)
+	}
+	return false
+}
+
+// errorsAs is a simplified errors.As for Go before 1.13: it sets target (a non-nil pointer to interface
+// or type implementing error) to the first error in err's chain which is assignable to it, and returns true.
+func errorsAs(err error, target interface{}) bool {
+	val := reflect.ValueOf(target).Elem()
+	for err != nil {
+		if reflect.TypeOf(err).AssignableTo(val.Type()) {
+			val.Set(reflect.ValueOf(err))
+			return true
+		}
+		err = unwrap(err)
+	}
+	return false
+}
+
+// unwrap returns error wrapped by err with Unwrap method, or nil.
+func unwrap(err error) error {
+	u, ok := err.(interface {
+		Unwrap() error
+	})
+	if !ok {
+		return nil
+	}
+	return u.Unwrap()
+}
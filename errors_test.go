@@ -0,0 +1,66 @@
+//go:build go1.13
+// +build go1.13
+
+package reform_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/empirefox/reform"
+	"github.com/empirefox/reform/internal/test/models"
+)
+
+// sqlStateError is an error with SQLSTATE, like lib/pq's errors.
+type sqlStateError struct {
+	state, msg string
+}
+
+func (e sqlStateError) Error() string    { return e.msg }
+func (e sqlStateError) SQLState() string { return e.state }
+
+// timeoutError is a net.Error with Timeout() true.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func (s *ReformSuite) TestErrorClassification() {
+	_, err := s.q.FindByPrimaryKeyFrom(models.PersonTable, -1)
+	s.True(reform.IsNoRows(err))
+	s.True(reform.IsNoRows(fmt.Errorf("wrapped: %w", err)))
+	s.False(reform.IsCancelled(err))
+	s.False(reform.IsTimeout(err))
+
+	for _, e := range []error{context.Canceled, fmt.Errorf("wrapped: %w", context.Canceled)} {
+		s.True(reform.IsCancelled(e), "%v", e)
+		s.False(reform.IsTimeout(e), "%v", e)
+		s.False(reform.IsNoRows(e), "%v", e)
+	}
+	for _, err := range []error{context.DeadlineExceeded, timeoutError{}} {
+		for _, e := range []error{err, fmt.Errorf("wrapped: %w", err)} {
+			s.True(reform.IsTimeout(e), "%v", e)
+			s.False(reform.IsCancelled(e), "%v", e)
+			s.False(reform.IsNoRows(e), "%v", e)
+		}
+	}
+
+	// query_canceled is used both for cancel requests and statement_timeout, with localized messages
+	userCancel := sqlStateError{"57014", "pq: canceling statement due to user request"}
+	statementTimeout := sqlStateError{"57014", "pq: canceling statement due to statement timeout"}
+	localizedTimeout := sqlStateError{"57014", "pq: Anweisung wird abgebrochen wegen Zeitüberschreitung"}
+	for _, err := range []error{userCancel, statementTimeout, localizedTimeout} {
+		for _, e := range []error{err, fmt.Errorf("wrapped: %w", err)} {
+			s.True(reform.IsCancelled(e), "%v", e)
+			s.True(reform.IsTimeout(e), "%v", e)
+			s.False(reform.IsNoRows(e), "%v", e)
+		}
+	}
+	for _, e := range []error{nil, errFake, driver.ErrBadConn, sqlStateError{"23505", "duplicate key"}} {
+		s.False(reform.IsNoRows(e), "%v", e)
+		s.False(reform.IsCancelled(e), "%v", e)
+		s.False(reform.IsTimeout(e), "%v", e)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build go1.18
+// +build go1.18
+
+package reform
+
+// Get queries T's table with primary key and returns found record of type T.
+// If record implements AfterFinder, it also calls AfterFind().
+// T must be a pointer type which View and Table methods can be called on zero (nil) value,
+// like ones generated by reform tool.
+//
+// If there are no rows in result, it returns zero value and ErrNoRows. It also may return QueryRow(), Scan()
+// and AfterFinder errors.
+func Get[T Record](q *Querier, pk interface{}) (T, error) {
+	var zero T
+	record := zero.Table().NewRecord().(T)
+	if err := q.FindByPrimaryKeyTo(record, pk); err != nil {
+		return zero, err
+	}
+	return record, nil
+}
+
+// SelectAll queries T's view with tail and args and returns a slice of found structs of type T.
+// See SelectAllFrom for details. T must be a pointer type which View method can be called
+// on zero (nil) value, like ones generated by reform tool.
+func SelectAll[T Struct](q *Querier, tail string, args ...interface{}) ([]T, error) {
+	var zero T
+	structs, err := q.SelectAllFrom(zero.View(), tail, args...)
+	if structs == nil {
+		return nil, err
+	}
+
+	res := make([]T, len(structs))
+	for i, str := range structs {
+		res[i] = str.(T)
+	}
+	return res, err
+}
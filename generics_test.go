@@ -0,0 +1,33 @@
+//go:build go1.18
+// +build go1.18
+
+package reform_test
+
+import (
+	"github.com/AlekSi/pointer"
+
+	"github.com/empirefox/reform"
+	. "github.com/empirefox/reform/internal/test/models"
+)
+
+func (s *ReformSuite) TestGet() {
+	person, err := reform.Get[*Person](s.q.Querier, 1)
+	s.NoError(err)
+	s.Equal(&Person{ID: 1, GroupID: pointer.ToInt32(65534), Name: "Denis Mills", CreatedAt: goCreated}, person)
+
+	project, err := reform.Get[*Project](s.q.Querier, "no_such_project")
+	s.Equal(reform.ErrNoRows, err)
+	s.Nil(project)
+}
+
+func (s *ReformSuite) TestSelectAll() {
+	people, err := reform.SelectAll[*Person](s.q.Querier, "WHERE name = "+s.q.Placeholder(1)+" ORDER BY id", "Elfrieda Abbott")
+	s.NoError(err)
+	s.Require().Len(people, 2)
+	s.Equal(int32(102), people[0].ID)
+	s.Equal(int32(103), people[1].ID)
+
+	people, err = reform.SelectAll[*Person](s.q.Querier, "WHERE id < 0")
+	s.NoError(err)
+	s.Nil(people)
+}
@@ -0,0 +1,14 @@
+package bogus
+
+//go:generate reform
+
+// BogusEmbedded is used for testing.
+type BogusEmbedded struct {
+	Bogus string `reform:"bogus"`
+}
+
+// Bogus11 is used for testing. reform:bogus
+type Bogus11 struct {
+	BogusEmbedded `reform:",embed"`
+	Bogus         string `reform:"bogus2"` // field with the same name as embedded struct's field should generate error
+}
@@ -0,0 +1,8 @@
+package bogus
+
+//go:generate reform
+
+// Bogus12 is used for testing. reform:bogus
+type Bogus12 struct {
+	Bogus string `reform:"bogus,null"` // non-nullable field with "reform:" tag and null label should generate error
+}
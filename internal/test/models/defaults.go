@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+//go:generate reform
+
+// DefaultedPerson represents row in table people with group_id omitted from INSERT when it is zero,
+// so database default is used.
+// (reform:people).
+type DefaultedPerson struct {
+	ID        int32     `reform:"id,pk"`
+	GroupID   int32     `reform:"group_id,omitempty"`
+	Name      string    `reform:"name"`
+	CreatedAt time.Time `reform:"created_at"`
+}
@@ -0,0 +1,148 @@
+package models
+
+// generated with github.com/empirefox/reform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/empirefox/reform"
+	"github.com/empirefox/reform/parse"
+)
+
+type defaultedPersonTable struct {
+	*reform.ViewBase
+	s parse.StructInfo
+	z []interface{}
+}
+
+// Schema returns a schema name in SQL database ("").
+func (v *defaultedPersonTable) Schema() string {
+	return v.s.SQLSchema
+}
+
+// Name returns a view or table name in SQL database ("people").
+func (v *defaultedPersonTable) Name() string {
+	return v.s.SQLName
+}
+
+// Columns returns a new slice of column names for that view or table in SQL database.
+func (v *defaultedPersonTable) Columns() []string {
+	return []string{"id", "group_id", "name", "created_at"}
+}
+
+// NewStruct makes a new struct for that view or table.
+func (v *defaultedPersonTable) NewStruct() reform.Struct {
+	return new(DefaultedPerson)
+}
+
+// NewRecord makes a new record for that table.
+func (v *defaultedPersonTable) NewRecord() reform.Record {
+	return new(DefaultedPerson)
+}
+
+// PKColumnIndex returns an index of primary key column for that table in SQL database.
+func (v *defaultedPersonTable) PKColumnIndex() uint {
+	return uint(v.s.PKFieldIndex)
+}
+
+// DefaultedPersonTable represents people view or table in SQL database.
+var DefaultedPersonTable = &defaultedPersonTable{
+	s: parse.StructInfo{Type: "DefaultedPerson", SQLSchema: "", SQLName: "people", Fields: []parse.FieldInfo{{Name: "ID", PKType: "int32", Column: "id", GoType: "int32", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "GroupID", PKType: "", Column: "group_id", GoType: "int32", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: true}, {Name: "Name", PKType: "", Column: "name", GoType: "string", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "CreatedAt", PKType: "", Column: "created_at", GoType: "time.Time", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}}, PKFieldIndex: 0},
+	z: new(DefaultedPerson).Values(),
+}
+
+// String returns a string representation of this struct or record.
+func (s DefaultedPerson) String() string {
+	res := make([]string, 4)
+	res[0] = "ID: " + reform.Inspect(s.ID, true)
+	res[1] = "GroupID: " + reform.Inspect(s.GroupID, true)
+	res[2] = "Name: " + reform.Inspect(s.Name, true)
+	res[3] = "CreatedAt: " + reform.Inspect(s.CreatedAt, true)
+	return strings.Join(res, ", ")
+}
+
+// Values returns a slice of struct or record field values.
+// Returned interface{} values are never untyped nils.
+func (s *DefaultedPerson) Values() []interface{} {
+	return []interface{}{
+		s.ID,
+		s.GroupID,
+		s.Name,
+		s.CreatedAt,
+	}
+}
+
+// ValuesInto appends struct or record field values to values and returns the extended slice.
+// It allows reusing slices instead of allocating a new one with Values.
+// Appended interface{} values are never untyped nils.
+func (s *DefaultedPerson) ValuesInto(values []interface{}) []interface{} {
+	return append(values,
+		s.ID,
+		s.GroupID,
+		s.Name,
+		s.CreatedAt,
+	)
+}
+
+// Pointers returns a slice of pointers to struct or record fields.
+// Returned interface{} values are never untyped nils.
+func (s *DefaultedPerson) Pointers() []interface{} {
+	return []interface{}{
+		&s.ID,
+		&s.GroupID,
+		&s.Name,
+		&s.CreatedAt,
+	}
+}
+
+// View returns View object for that struct.
+func (s *DefaultedPerson) View() reform.View {
+	return DefaultedPersonTable
+}
+
+// Table returns Table object for that record.
+func (s *DefaultedPerson) Table() reform.Table {
+	return DefaultedPersonTable
+}
+
+// PKValue returns a value of primary key for that record.
+// Returned interface{} value is never untyped nil.
+func (s *DefaultedPerson) PKValue() interface{} {
+	return s.ID
+}
+
+// PKPointer returns a pointer to primary key field for that record.
+// Returned interface{} value is never untyped nil.
+func (s *DefaultedPerson) PKPointer() interface{} {
+	return &s.ID
+}
+
+// HasPK returns true if record has non-zero primary key set, false otherwise.
+func (s *DefaultedPerson) HasPK() bool {
+	return s.ID != DefaultedPersonTable.z[DefaultedPersonTable.s.PKFieldIndex]
+}
+
+// SetPK sets record primary key.
+func (s *DefaultedPerson) SetPK(pk interface{}) {
+	if i64, ok := pk.(int64); ok {
+		s.ID = int32(i64)
+	} else {
+		s.ID = pk.(int32)
+	}
+}
+
+// check interfaces
+var (
+	_ reform.View         = DefaultedPersonTable
+	_ reform.Struct       = new(DefaultedPerson)
+	_ reform.ValuesFiller = new(DefaultedPerson)
+	_ reform.Table        = DefaultedPersonTable
+	_ reform.Record       = new(DefaultedPerson)
+	_ fmt.Stringer        = new(DefaultedPerson)
+)
+
+func init() {
+	parse.AssertUpToDate(&DefaultedPersonTable.s, new(DefaultedPerson))
+	DefaultedPersonTable.ViewBase = reform.NewViewBase(&DefaultedPersonTable.s)
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+//go:generate reform
+
+// AuditFields contains common audit columns, it is embedded into other structs.
+type AuditFields struct {
+	CreatedBy string    `reform:"created_by"`
+	CreatedAt time.Time `reform:"created_at"`
+}
+
+// Audited represents row in table audited with embedded audit columns.
+// (reform:audited).
+type Audited struct {
+	ID          int32 `reform:"id,pk"`
+	AuditFields `reform:",embed"`
+	Name        string `reform:"name"`
+}
@@ -0,0 +1,148 @@
+package models
+
+// generated with github.com/empirefox/reform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/empirefox/reform"
+	"github.com/empirefox/reform/parse"
+)
+
+type auditedTable struct {
+	*reform.ViewBase
+	s parse.StructInfo
+	z []interface{}
+}
+
+// Schema returns a schema name in SQL database ("").
+func (v *auditedTable) Schema() string {
+	return v.s.SQLSchema
+}
+
+// Name returns a view or table name in SQL database ("audited").
+func (v *auditedTable) Name() string {
+	return v.s.SQLName
+}
+
+// Columns returns a new slice of column names for that view or table in SQL database.
+func (v *auditedTable) Columns() []string {
+	return []string{"id", "created_by", "created_at", "name"}
+}
+
+// NewStruct makes a new struct for that view or table.
+func (v *auditedTable) NewStruct() reform.Struct {
+	return new(Audited)
+}
+
+// NewRecord makes a new record for that table.
+func (v *auditedTable) NewRecord() reform.Record {
+	return new(Audited)
+}
+
+// PKColumnIndex returns an index of primary key column for that table in SQL database.
+func (v *auditedTable) PKColumnIndex() uint {
+	return uint(v.s.PKFieldIndex)
+}
+
+// AuditedTable represents audited view or table in SQL database.
+var AuditedTable = &auditedTable{
+	s: parse.StructInfo{Type: "Audited", SQLSchema: "", SQLName: "audited", Fields: []parse.FieldInfo{{Name: "ID", PKType: "int32", Column: "id", GoType: "int32", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "CreatedBy", PKType: "", Column: "created_by", GoType: "string", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "CreatedAt", PKType: "", Column: "created_at", GoType: "time.Time", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "Name", PKType: "", Column: "name", GoType: "string", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}}, PKFieldIndex: 0},
+	z: new(Audited).Values(),
+}
+
+// String returns a string representation of this struct or record.
+func (s Audited) String() string {
+	res := make([]string, 4)
+	res[0] = "ID: " + reform.Inspect(s.ID, true)
+	res[1] = "CreatedBy: " + reform.Inspect(s.CreatedBy, true)
+	res[2] = "CreatedAt: " + reform.Inspect(s.CreatedAt, true)
+	res[3] = "Name: " + reform.Inspect(s.Name, true)
+	return strings.Join(res, ", ")
+}
+
+// Values returns a slice of struct or record field values.
+// Returned interface{} values are never untyped nils.
+func (s *Audited) Values() []interface{} {
+	return []interface{}{
+		s.ID,
+		s.CreatedBy,
+		s.CreatedAt,
+		s.Name,
+	}
+}
+
+// ValuesInto appends struct or record field values to values and returns the extended slice.
+// It allows reusing slices instead of allocating a new one with Values.
+// Appended interface{} values are never untyped nils.
+func (s *Audited) ValuesInto(values []interface{}) []interface{} {
+	return append(values,
+		s.ID,
+		s.CreatedBy,
+		s.CreatedAt,
+		s.Name,
+	)
+}
+
+// Pointers returns a slice of pointers to struct or record fields.
+// Returned interface{} values are never untyped nils.
+func (s *Audited) Pointers() []interface{} {
+	return []interface{}{
+		&s.ID,
+		&s.CreatedBy,
+		&s.CreatedAt,
+		&s.Name,
+	}
+}
+
+// View returns View object for that struct.
+func (s *Audited) View() reform.View {
+	return AuditedTable
+}
+
+// Table returns Table object for that record.
+func (s *Audited) Table() reform.Table {
+	return AuditedTable
+}
+
+// PKValue returns a value of primary key for that record.
+// Returned interface{} value is never untyped nil.
+func (s *Audited) PKValue() interface{} {
+	return s.ID
+}
+
+// PKPointer returns a pointer to primary key field for that record.
+// Returned interface{} value is never untyped nil.
+func (s *Audited) PKPointer() interface{} {
+	return &s.ID
+}
+
+// HasPK returns true if record has non-zero primary key set, false otherwise.
+func (s *Audited) HasPK() bool {
+	return s.ID != AuditedTable.z[AuditedTable.s.PKFieldIndex]
+}
+
+// SetPK sets record primary key.
+func (s *Audited) SetPK(pk interface{}) {
+	if i64, ok := pk.(int64); ok {
+		s.ID = int32(i64)
+	} else {
+		s.ID = pk.(int32)
+	}
+}
+
+// check interfaces
+var (
+	_ reform.View         = AuditedTable
+	_ reform.Struct       = new(Audited)
+	_ reform.ValuesFiller = new(Audited)
+	_ reform.Table        = AuditedTable
+	_ reform.Record       = new(Audited)
+	_ fmt.Stringer        = new(Audited)
+)
+
+func init() {
+	parse.AssertUpToDate(&AuditedTable.s, new(Audited))
+	AuditedTable.ViewBase = reform.NewViewBase(&AuditedTable.s)
+}
@@ -47,7 +47,7 @@ func (v *personTable) PKColumnIndex() uint {
 
 // PersonTable represents people view or table in SQL database.
 var PersonTable = &personTable{
-	s: parse.StructInfo{Type: "Person", SQLSchema: "", SQLName: "people", Fields: []parse.FieldInfo{{Name: "ID", PKType: "int32", Column: "id"}, {Name: "GroupID", PKType: "", Column: "group_id"}, {Name: "Name", PKType: "", Column: "name"}, {Name: "Email", PKType: "", Column: "email"}, {Name: "CreatedAt", PKType: "", Column: "created_at"}, {Name: "UpdatedAt", PKType: "", Column: "updated_at"}}, PKFieldIndex: 0},
+	s: parse.StructInfo{Type: "Person", SQLSchema: "", SQLName: "people", Fields: []parse.FieldInfo{{Name: "ID", PKType: "int32", Column: "id", GoType: "int32", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "GroupID", PKType: "", Column: "group_id", GoType: "*int32", SQLType: "", Nullable: true, ReadOnly: false, OmitEmpty: false}, {Name: "Name", PKType: "", Column: "name", GoType: "string", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "Email", PKType: "", Column: "email", GoType: "*string", SQLType: "", Nullable: true, ReadOnly: false, OmitEmpty: false}, {Name: "CreatedAt", PKType: "", Column: "created_at", GoType: "time.Time", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "UpdatedAt", PKType: "", Column: "updated_at", GoType: "*time.Time", SQLType: "", Nullable: true, ReadOnly: false, OmitEmpty: false}}, PKFieldIndex: 0},
 	z: new(Person).Values(),
 }
 
@@ -76,6 +76,20 @@ func (s *Person) Values() []interface{} {
 	}
 }
 
+// ValuesInto appends struct or record field values to values and returns the extended slice.
+// It allows reusing slices instead of allocating a new one with Values.
+// Appended interface{} values are never untyped nils.
+func (s *Person) ValuesInto(values []interface{}) []interface{} {
+	return append(values,
+		s.ID,
+		s.GroupID,
+		s.Name,
+		s.Email,
+		s.CreatedAt,
+		s.UpdatedAt,
+	)
+}
+
 // Pointers returns a slice of pointers to struct or record fields.
 // Returned interface{} values are never untyped nils.
 func (s *Person) Pointers() []interface{} {
@@ -127,11 +141,12 @@ func (s *Person) SetPK(pk interface{}) {
 
 // check interfaces
 var (
-	_ reform.View   = PersonTable
-	_ reform.Struct = new(Person)
-	_ reform.Table  = PersonTable
-	_ reform.Record = new(Person)
-	_ fmt.Stringer  = new(Person)
+	_ reform.View         = PersonTable
+	_ reform.Struct       = new(Person)
+	_ reform.ValuesFiller = new(Person)
+	_ reform.Table        = PersonTable
+	_ reform.Record       = new(Person)
+	_ fmt.Stringer        = new(Person)
 )
 
 type projectTable struct {
@@ -171,7 +186,7 @@ func (v *projectTable) PKColumnIndex() uint {
 
 // ProjectTable represents projects view or table in SQL database.
 var ProjectTable = &projectTable{
-	s: parse.StructInfo{Type: "Project", SQLSchema: "", SQLName: "projects", Fields: []parse.FieldInfo{{Name: "Name", PKType: "", Column: "name"}, {Name: "ID", PKType: "string", Column: "id"}, {Name: "Start", PKType: "", Column: "start"}, {Name: "End", PKType: "", Column: "end"}}, PKFieldIndex: 1},
+	s: parse.StructInfo{Type: "Project", SQLSchema: "", SQLName: "projects", Fields: []parse.FieldInfo{{Name: "Name", PKType: "", Column: "name", GoType: "string", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "ID", PKType: "string", Column: "id", GoType: "string", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "Start", PKType: "", Column: "start", GoType: "time.Time", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "End", PKType: "", Column: "end", GoType: "*time.Time", SQLType: "", Nullable: true, ReadOnly: false, OmitEmpty: false}}, PKFieldIndex: 1},
 	z: new(Project).Values(),
 }
 
@@ -196,6 +211,18 @@ func (s *Project) Values() []interface{} {
 	}
 }
 
+// ValuesInto appends struct or record field values to values and returns the extended slice.
+// It allows reusing slices instead of allocating a new one with Values.
+// Appended interface{} values are never untyped nils.
+func (s *Project) ValuesInto(values []interface{}) []interface{} {
+	return append(values,
+		s.Name,
+		s.ID,
+		s.Start,
+		s.End,
+	)
+}
+
 // Pointers returns a slice of pointers to struct or record fields.
 // Returned interface{} values are never untyped nils.
 func (s *Project) Pointers() []interface{} {
@@ -245,11 +272,12 @@ func (s *Project) SetPK(pk interface{}) {
 
 // check interfaces
 var (
-	_ reform.View   = ProjectTable
-	_ reform.Struct = new(Project)
-	_ reform.Table  = ProjectTable
-	_ reform.Record = new(Project)
-	_ fmt.Stringer  = new(Project)
+	_ reform.View         = ProjectTable
+	_ reform.Struct       = new(Project)
+	_ reform.ValuesFiller = new(Project)
+	_ reform.Table        = ProjectTable
+	_ reform.Record       = new(Project)
+	_ fmt.Stringer        = new(Project)
 )
 
 type personProjectView struct {
@@ -279,7 +307,7 @@ func (v *personProjectView) NewStruct() reform.Struct {
 
 // PersonProjectView represents person_project view or table in SQL database.
 var PersonProjectView = &personProjectView{
-	s: parse.StructInfo{Type: "PersonProject", SQLSchema: "", SQLName: "person_project", Fields: []parse.FieldInfo{{Name: "PersonID", PKType: "", Column: "person_id"}, {Name: "ProjectID", PKType: "", Column: "project_id"}}, PKFieldIndex: -1},
+	s: parse.StructInfo{Type: "PersonProject", SQLSchema: "", SQLName: "person_project", Fields: []parse.FieldInfo{{Name: "PersonID", PKType: "", Column: "person_id", GoType: "int32", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "ProjectID", PKType: "", Column: "project_id", GoType: "string", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}}, PKFieldIndex: -1},
 	z: new(PersonProject).Values(),
 }
 
@@ -300,6 +328,16 @@ func (s *PersonProject) Values() []interface{} {
 	}
 }
 
+// ValuesInto appends struct or record field values to values and returns the extended slice.
+// It allows reusing slices instead of allocating a new one with Values.
+// Appended interface{} values are never untyped nils.
+func (s *PersonProject) ValuesInto(values []interface{}) []interface{} {
+	return append(values,
+		s.PersonID,
+		s.ProjectID,
+	)
+}
+
 // Pointers returns a slice of pointers to struct or record fields.
 // Returned interface{} values are never untyped nils.
 func (s *PersonProject) Pointers() []interface{} {
@@ -316,9 +354,10 @@ func (s *PersonProject) View() reform.View {
 
 // check interfaces
 var (
-	_ reform.View   = PersonProjectView
-	_ reform.Struct = new(PersonProject)
-	_ fmt.Stringer  = new(PersonProject)
+	_ reform.View         = PersonProjectView
+	_ reform.Struct       = new(PersonProject)
+	_ reform.ValuesFiller = new(PersonProject)
+	_ fmt.Stringer        = new(PersonProject)
 )
 
 type iDOnlyTable struct {
@@ -358,7 +397,7 @@ func (v *iDOnlyTable) PKColumnIndex() uint {
 
 // IDOnlyTable represents id_only view or table in SQL database.
 var IDOnlyTable = &iDOnlyTable{
-	s: parse.StructInfo{Type: "IDOnly", SQLSchema: "", SQLName: "id_only", Fields: []parse.FieldInfo{{Name: "ID", PKType: "int32", Column: "id"}}, PKFieldIndex: 0},
+	s: parse.StructInfo{Type: "IDOnly", SQLSchema: "", SQLName: "id_only", Fields: []parse.FieldInfo{{Name: "ID", PKType: "int32", Column: "id", GoType: "int32", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}}, PKFieldIndex: 0},
 	z: new(IDOnly).Values(),
 }
 
@@ -377,6 +416,15 @@ func (s *IDOnly) Values() []interface{} {
 	}
 }
 
+// ValuesInto appends struct or record field values to values and returns the extended slice.
+// It allows reusing slices instead of allocating a new one with Values.
+// Appended interface{} values are never untyped nils.
+func (s *IDOnly) ValuesInto(values []interface{}) []interface{} {
+	return append(values,
+		s.ID,
+	)
+}
+
 // Pointers returns a slice of pointers to struct or record fields.
 // Returned interface{} values are never untyped nils.
 func (s *IDOnly) Pointers() []interface{} {
@@ -423,11 +471,12 @@ func (s *IDOnly) SetPK(pk interface{}) {
 
 // check interfaces
 var (
-	_ reform.View   = IDOnlyTable
-	_ reform.Struct = new(IDOnly)
-	_ reform.Table  = IDOnlyTable
-	_ reform.Record = new(IDOnly)
-	_ fmt.Stringer  = new(IDOnly)
+	_ reform.View         = IDOnlyTable
+	_ reform.Struct       = new(IDOnly)
+	_ reform.ValuesFiller = new(IDOnly)
+	_ reform.Table        = IDOnlyTable
+	_ reform.Record       = new(IDOnly)
+	_ fmt.Stringer        = new(IDOnly)
 )
 
 type legacyPersonTable struct {
@@ -467,7 +516,7 @@ func (v *legacyPersonTable) PKColumnIndex() uint {
 
 // LegacyPersonTable represents people view or table in SQL database.
 var LegacyPersonTable = &legacyPersonTable{
-	s: parse.StructInfo{Type: "LegacyPerson", SQLSchema: "legacy", SQLName: "people", Fields: []parse.FieldInfo{{Name: "ID", PKType: "int32", Column: "id"}, {Name: "Name", PKType: "", Column: "name"}}, PKFieldIndex: 0},
+	s: parse.StructInfo{Type: "LegacyPerson", SQLSchema: "legacy", SQLName: "people", Fields: []parse.FieldInfo{{Name: "ID", PKType: "int32", Column: "id", GoType: "int32", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "Name", PKType: "", Column: "name", GoType: "*string", SQLType: "", Nullable: true, ReadOnly: false, OmitEmpty: false}}, PKFieldIndex: 0},
 	z: new(LegacyPerson).Values(),
 }
 
@@ -488,6 +537,16 @@ func (s *LegacyPerson) Values() []interface{} {
 	}
 }
 
+// ValuesInto appends struct or record field values to values and returns the extended slice.
+// It allows reusing slices instead of allocating a new one with Values.
+// Appended interface{} values are never untyped nils.
+func (s *LegacyPerson) ValuesInto(values []interface{}) []interface{} {
+	return append(values,
+		s.ID,
+		s.Name,
+	)
+}
+
 // Pointers returns a slice of pointers to struct or record fields.
 // Returned interface{} values are never untyped nils.
 func (s *LegacyPerson) Pointers() []interface{} {
@@ -535,11 +594,12 @@ func (s *LegacyPerson) SetPK(pk interface{}) {
 
 // check interfaces
 var (
-	_ reform.View   = LegacyPersonTable
-	_ reform.Struct = new(LegacyPerson)
-	_ reform.Table  = LegacyPersonTable
-	_ reform.Record = new(LegacyPerson)
-	_ fmt.Stringer  = new(LegacyPerson)
+	_ reform.View         = LegacyPersonTable
+	_ reform.Struct       = new(LegacyPerson)
+	_ reform.ValuesFiller = new(LegacyPerson)
+	_ reform.Table        = LegacyPersonTable
+	_ reform.Record       = new(LegacyPerson)
+	_ fmt.Stringer        = new(LegacyPerson)
 )
 
 type extraTable struct {
@@ -579,7 +639,7 @@ func (v *extraTable) PKColumnIndex() uint {
 
 // ExtraTable represents extra view or table in SQL database.
 var ExtraTable = &extraTable{
-	s: parse.StructInfo{Type: "Extra", SQLSchema: "", SQLName: "extra", Fields: []parse.FieldInfo{{Name: "ID", PKType: "Integer", Column: "id"}, {Name: "Name", PKType: "", Column: "name"}, {Name: "Bytes", PKType: "", Column: "bytes"}, {Name: "Bytes2", PKType: "", Column: "bytes2"}, {Name: "Byte", PKType: "", Column: "byte"}, {Name: "Array", PKType: "", Column: "array"}}, PKFieldIndex: 0},
+	s: parse.StructInfo{Type: "Extra", SQLSchema: "", SQLName: "extra", Fields: []parse.FieldInfo{{Name: "ID", PKType: "Integer", Column: "id", GoType: "Integer", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "Name", PKType: "", Column: "name", GoType: "*String", SQLType: "", Nullable: true, ReadOnly: false, OmitEmpty: false}, {Name: "Bytes", PKType: "", Column: "bytes", GoType: "[]uint8", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "Bytes2", PKType: "", Column: "bytes2", GoType: "Bytes", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "Byte", PKType: "", Column: "byte", GoType: "*uint8", SQLType: "", Nullable: true, ReadOnly: false, OmitEmpty: false}, {Name: "Array", PKType: "", Column: "array", GoType: "[512]uint8", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}}, PKFieldIndex: 0},
 	z: new(Extra).Values(),
 }
 
@@ -608,6 +668,20 @@ func (s *Extra) Values() []interface{} {
 	}
 }
 
+// ValuesInto appends struct or record field values to values and returns the extended slice.
+// It allows reusing slices instead of allocating a new one with Values.
+// Appended interface{} values are never untyped nils.
+func (s *Extra) ValuesInto(values []interface{}) []interface{} {
+	return append(values,
+		s.ID,
+		s.Name,
+		s.Bytes,
+		s.Bytes2,
+		s.Byte,
+		s.Array,
+	)
+}
+
 // Pointers returns a slice of pointers to struct or record fields.
 // Returned interface{} values are never untyped nils.
 func (s *Extra) Pointers() []interface{} {
@@ -659,11 +733,12 @@ func (s *Extra) SetPK(pk interface{}) {
 
 // check interfaces
 var (
-	_ reform.View   = ExtraTable
-	_ reform.Struct = new(Extra)
-	_ reform.Table  = ExtraTable
-	_ reform.Record = new(Extra)
-	_ fmt.Stringer  = new(Extra)
+	_ reform.View         = ExtraTable
+	_ reform.Struct       = new(Extra)
+	_ reform.ValuesFiller = new(Extra)
+	_ reform.Table        = ExtraTable
+	_ reform.Record       = new(Extra)
+	_ fmt.Stringer        = new(Extra)
 )
 
 func init() {
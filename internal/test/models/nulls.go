@@ -0,0 +1,19 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+//go:generate reform
+
+// NullPerson represents row in table people with nullable columns mapped to sql.Null* types.
+// (reform:people).
+type NullPerson struct {
+	ID        int32          `reform:"id,pk"`
+	GroupID   sql.NullInt64  `reform:"group_id"`
+	Name      string         `reform:"name"`
+	Email     sql.NullString `reform:"email,null"`
+	CreatedAt time.Time      `reform:"created_at"`
+	UpdatedAt *time.Time     `reform:"updated_at"`
+}
@@ -0,0 +1,156 @@
+package models
+
+// generated with github.com/empirefox/reform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/empirefox/reform"
+	"github.com/empirefox/reform/parse"
+)
+
+type nullPersonTable struct {
+	*reform.ViewBase
+	s parse.StructInfo
+	z []interface{}
+}
+
+// Schema returns a schema name in SQL database ("").
+func (v *nullPersonTable) Schema() string {
+	return v.s.SQLSchema
+}
+
+// Name returns a view or table name in SQL database ("people").
+func (v *nullPersonTable) Name() string {
+	return v.s.SQLName
+}
+
+// Columns returns a new slice of column names for that view or table in SQL database.
+func (v *nullPersonTable) Columns() []string {
+	return []string{"id", "group_id", "name", "email", "created_at", "updated_at"}
+}
+
+// NewStruct makes a new struct for that view or table.
+func (v *nullPersonTable) NewStruct() reform.Struct {
+	return new(NullPerson)
+}
+
+// NewRecord makes a new record for that table.
+func (v *nullPersonTable) NewRecord() reform.Record {
+	return new(NullPerson)
+}
+
+// PKColumnIndex returns an index of primary key column for that table in SQL database.
+func (v *nullPersonTable) PKColumnIndex() uint {
+	return uint(v.s.PKFieldIndex)
+}
+
+// NullPersonTable represents people view or table in SQL database.
+var NullPersonTable = &nullPersonTable{
+	s: parse.StructInfo{Type: "NullPerson", SQLSchema: "", SQLName: "people", Fields: []parse.FieldInfo{{Name: "ID", PKType: "int32", Column: "id", GoType: "int32", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "GroupID", PKType: "", Column: "group_id", GoType: "sql.NullInt64", SQLType: "", Nullable: true, ReadOnly: false, OmitEmpty: false}, {Name: "Name", PKType: "", Column: "name", GoType: "string", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "Email", PKType: "", Column: "email", GoType: "sql.NullString", SQLType: "", Nullable: true, ReadOnly: false, OmitEmpty: false}, {Name: "CreatedAt", PKType: "", Column: "created_at", GoType: "time.Time", SQLType: "", Nullable: false, ReadOnly: false, OmitEmpty: false}, {Name: "UpdatedAt", PKType: "", Column: "updated_at", GoType: "*time.Time", SQLType: "", Nullable: true, ReadOnly: false, OmitEmpty: false}}, PKFieldIndex: 0},
+	z: new(NullPerson).Values(),
+}
+
+// String returns a string representation of this struct or record.
+func (s NullPerson) String() string {
+	res := make([]string, 6)
+	res[0] = "ID: " + reform.Inspect(s.ID, true)
+	res[1] = "GroupID: " + reform.Inspect(s.GroupID, true)
+	res[2] = "Name: " + reform.Inspect(s.Name, true)
+	res[3] = "Email: " + reform.Inspect(s.Email, true)
+	res[4] = "CreatedAt: " + reform.Inspect(s.CreatedAt, true)
+	res[5] = "UpdatedAt: " + reform.Inspect(s.UpdatedAt, true)
+	return strings.Join(res, ", ")
+}
+
+// Values returns a slice of struct or record field values.
+// Returned interface{} values are never untyped nils.
+func (s *NullPerson) Values() []interface{} {
+	return []interface{}{
+		s.ID,
+		s.GroupID,
+		s.Name,
+		s.Email,
+		s.CreatedAt,
+		s.UpdatedAt,
+	}
+}
+
+// ValuesInto appends struct or record field values to values and returns the extended slice.
+// It allows reusing slices instead of allocating a new one with Values.
+// Appended interface{} values are never untyped nils.
+func (s *NullPerson) ValuesInto(values []interface{}) []interface{} {
+	return append(values,
+		s.ID,
+		s.GroupID,
+		s.Name,
+		s.Email,
+		s.CreatedAt,
+		s.UpdatedAt,
+	)
+}
+
+// Pointers returns a slice of pointers to struct or record fields.
+// Returned interface{} values are never untyped nils.
+func (s *NullPerson) Pointers() []interface{} {
+	return []interface{}{
+		&s.ID,
+		&s.GroupID,
+		&s.Name,
+		&s.Email,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	}
+}
+
+// View returns View object for that struct.
+func (s *NullPerson) View() reform.View {
+	return NullPersonTable
+}
+
+// Table returns Table object for that record.
+func (s *NullPerson) Table() reform.Table {
+	return NullPersonTable
+}
+
+// PKValue returns a value of primary key for that record.
+// Returned interface{} value is never untyped nil.
+func (s *NullPerson) PKValue() interface{} {
+	return s.ID
+}
+
+// PKPointer returns a pointer to primary key field for that record.
+// Returned interface{} value is never untyped nil.
+func (s *NullPerson) PKPointer() interface{} {
+	return &s.ID
+}
+
+// HasPK returns true if record has non-zero primary key set, false otherwise.
+func (s *NullPerson) HasPK() bool {
+	return s.ID != NullPersonTable.z[NullPersonTable.s.PKFieldIndex]
+}
+
+// SetPK sets record primary key.
+func (s *NullPerson) SetPK(pk interface{}) {
+	if i64, ok := pk.(int64); ok {
+		s.ID = int32(i64)
+	} else {
+		s.ID = pk.(int32)
+	}
+}
+
+// check interfaces
+var (
+	_ reform.View         = NullPersonTable
+	_ reform.Struct       = new(NullPerson)
+	_ reform.ValuesFiller = new(NullPerson)
+	_ reform.Table        = NullPersonTable
+	_ reform.Record       = new(NullPerson)
+	_ fmt.Stringer        = new(NullPerson)
+)
+
+func init() {
+	parse.AssertUpToDate(&NullPersonTable.s, new(NullPerson))
+	NullPersonTable.ViewBase = reform.NewViewBase(&NullPersonTable.s)
+}
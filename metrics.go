@@ -0,0 +1,18 @@
+package reform
+
+import (
+	"time"
+)
+
+// Metrics is responsible to collect metrics of Querier's operations.
+// Operation is one of "insert", "update", "upsert", "delete", "select" or "exec" (for DsExec); table is a view or table name in SQL database.
+type Metrics interface {
+	// IncOp increments a number of operations.
+	IncOp(op, table string)
+
+	// IncError increments a number of failed operations.
+	IncError(op, table string)
+
+	// ObserveDuration observes operation duration.
+	ObserveDuration(op, table string, d time.Duration)
+}
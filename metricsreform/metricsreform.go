@@ -0,0 +1,79 @@
+// Package metricsreform implements reform.Metrics with Prometheus.
+package metricsreform // import "github.com/empirefox/reform/metricsreform"
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/empirefox/reform"
+)
+
+// Metrics implements reform.Metrics with Prometheus metrics labeled by operation and table name.
+// It also implements prometheus.Collector and should be registered, for example, with prometheus.MustRegister.
+type Metrics struct {
+	ops       *prometheus.CounterVec
+	errors    *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+}
+
+// New creates new Metrics with given namespace (may be empty).
+func New(namespace string) *Metrics {
+	labels := []string{"op", "table"}
+	return &Metrics{
+		ops: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "reform",
+			Name:      "operations_total",
+			Help:      "Total number of operations.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "reform",
+			Name:      "errors_total",
+			Help:      "Total number of failed operations.",
+		}, labels),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "reform",
+			Name:      "duration_seconds",
+			Help:      "Operations duration in seconds.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+	}
+}
+
+// IncOp implements reform.Metrics.
+func (m *Metrics) IncOp(op, table string) {
+	m.ops.WithLabelValues(op, table).Inc()
+}
+
+// IncError implements reform.Metrics.
+func (m *Metrics) IncError(op, table string) {
+	m.errors.WithLabelValues(op, table).Inc()
+}
+
+// ObserveDuration implements reform.Metrics.
+func (m *Metrics) ObserveDuration(op, table string, d time.Duration) {
+	m.durations.WithLabelValues(op, table).Observe(d.Seconds())
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.ops.Describe(ch)
+	m.errors.Describe(ch)
+	m.durations.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.ops.Collect(ch)
+	m.errors.Collect(ch)
+	m.durations.Collect(ch)
+}
+
+// check interfaces
+var (
+	_ reform.Metrics       = new(Metrics)
+	_ prometheus.Collector = new(Metrics)
+)
@@ -13,9 +13,14 @@ import (
 
 // FieldInfo represents information about struct field.
 type FieldInfo struct {
-	Name   string // field name as defined in source file, e.g. Name
-	PKType string // primary key field type as defined in source file, e.g. string
-	Column string // SQL database column name from "reform:" struct field tag, e.g. name
+	Name      string // field name as defined in source file, e.g. Name
+	PKType    string // primary key field type as defined in source file, e.g. string
+	Column    string // SQL database column name from "reform:" struct field tag, e.g. name
+	GoType    string // field type with resolved byte and rune aliases, e.g. *string
+	SQLType   string // SQL database column type from "type=" label in "reform:" struct field tag, e.g. varchar(255)
+	Nullable  bool   // true for pointer and sql.Null* fields, and fields with "null" label in "reform:" struct field tag
+	ReadOnly  bool   // true for fields with "readonly" label in "reform:" struct field tag
+	OmitEmpty bool   // true for fields with "omitempty" label in "reform:" struct field tag
 }
 
 // StructInfo represents information about struct.
@@ -49,6 +54,17 @@ func (s *StructInfo) PKField() FieldInfo {
 	return s.Fields[s.PKFieldIndex]
 }
 
+// NonPKFields returns a new slice of fields except primary key field.
+func (s *StructInfo) NonPKFields() []FieldInfo {
+	res := make([]FieldInfo, 0, len(s.Fields))
+	for i, f := range s.Fields {
+		if i != s.PKFieldIndex {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
 // AssertUpToDate checks that given StructInfo matches given object.
 // It is used during program initialization to check that generated files are up-to-date.
 func AssertUpToDate(si *StructInfo, obj interface{}) {
@@ -61,39 +77,132 @@ func AssertUpToDate(si *StructInfo, obj interface{}) {
 	if err != nil {
 		panic(msg + err.Error())
 	}
+
+	// GoType is not compared as its spelling in source file may differ from runtime one.
+	// Files generated by older versions do not contain GoType and other tag information at all.
+	if len(si.Fields) == len(si2.Fields) {
+		old := true
+		for _, f := range si.Fields {
+			if f.GoType != "" {
+				old = false
+				break
+			}
+		}
+		for i, f := range si.Fields {
+			si2.Fields[i].GoType = f.GoType
+			if old {
+				si2.Fields[i].SQLType = f.SQLType
+				si2.Fields[i].Nullable = f.Nullable
+				si2.Fields[i].ReadOnly = f.ReadOnly
+				si2.Fields[i].OmitEmpty = f.OmitEmpty
+			}
+		}
+	}
 	if !reflect.DeepEqual(si, si2) {
 		panic(msg)
 	}
 }
 
-// parseStructFieldTag is used by both file and runtime parsers
-func parseStructFieldTag(tag string) (sqlName string, isPK bool) {
-	parts := strings.Split(tag, ",")
-	if len(parts) == 0 || len(parts) > 2 {
-		return
+// embedTag is a "reform:" tag value of embedded struct field which fields are flattened into parent struct.
+const embedTag = ",embed"
+
+// fieldTag represents parsed "reform:" struct field tag.
+type fieldTag struct {
+	column    string
+	pk        bool
+	null      bool
+	readOnly  bool
+	omitEmpty bool
+	sqlType   string
+}
+
+// sqlTypeLabel is a "reform:" tag label for SQL type. It should be the last one, as SQL type may contain commas.
+const sqlTypeLabel = ",type="
+
+// parseStructFieldTag is used by both file and runtime parsers.
+// It returns zero value for invalid tag.
+func parseStructFieldTag(tag string) (res fieldTag) {
+	var sqlType string
+	if i := strings.Index(tag, sqlTypeLabel); i >= 0 {
+		tag, sqlType = tag[:i], tag[i+len(sqlTypeLabel):]
+		if sqlType == "" {
+			return
+		}
 	}
 
-	if len(parts) == 2 {
-		switch parts[1] {
+	parts := strings.Split(tag, ",")
+	for _, label := range parts[1:] {
+		switch label {
 		case "pk":
-			isPK = true
+			res.pk = true
+		case "null":
+			res.null = true
+		case "readonly":
+			res.readOnly = true
+		case "omitempty":
+			res.omitEmpty = true
 		default:
-			return
+			return fieldTag{}
 		}
 	}
 
-	sqlName = parts[0]
+	res.column = parts[0]
+	res.sqlType = sqlType
 	return
 }
 
+// nonNullableGoTypes contains field types which can't hold SQL NULL value.
+var nonNullableGoTypes = map[string]struct{}{
+	"bool":       {},
+	"string":     {},
+	"int":        {},
+	"int8":       {},
+	"int16":      {},
+	"int32":      {},
+	"int64":      {},
+	"uint":       {},
+	"uint8":      {},
+	"uint16":     {},
+	"uint32":     {},
+	"uint64":     {},
+	"float32":    {},
+	"float64":    {},
+	"complex64":  {},
+	"complex128": {},
+	"time.Time":  {},
+}
+
+// isNullable is used by both file and runtime parsers.
+// It returns true if field of given type with given "null" label can hold SQL NULL value.
+// It returns error for basic types, which can't hold it even with that label.
+func isNullable(typ, name, goType string, null bool) (bool, error) {
+	if strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "sql.Null") {
+		return true, nil
+	}
+	if !null {
+		return false, nil
+	}
+	if _, ok := nonNullableGoTypes[goType]; ok {
+		return false, fmt.Errorf(`reform: %s has non-nullable field %s with "null" label in "reform:" tag, it is not allowed`, typ, name)
+	}
+	return true, nil
+}
+
 // checkFields is used by both file and runtime parsers
 func checkFields(res *StructInfo) error {
 	if len(res.Fields) == 0 {
 		return fmt.Errorf(`reform: %s has no fields with "reform:" tag, it is not allowed`, res.Type)
 	}
 
+	names := make(map[string]string)
 	dupes := make(map[string]string)
 	for _, f := range res.Fields {
+		if c2, ok := names[f.Name]; ok {
+			return fmt.Errorf(`reform: %s has duplicate field name %s promoted from embedded struct (used by columns %s and %s), it is not allowed`,
+				res.Type, f.Name, c2, f.Column)
+		}
+		names[f.Name] = f.Column
+
 		if f2, ok := dupes[f.Column]; ok {
 			return fmt.Errorf(`reform: %s has field %s with "reform:" tag with duplicate column name %s (used by %s), it is not allowed`,
 				res.Type, f.Name, f.Column, f2)
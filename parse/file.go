@@ -5,6 +5,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"reflect"
 	"regexp"
 	"strings"
@@ -23,13 +24,38 @@ func fileGoType(x ast.Expr) string {
 	}
 }
 
-func parseStructTypeSpec(ts *ast.TypeSpec, str *ast.StructType) (*StructInfo, error) {
-	res := &StructInfo{
-		Type:         ts.Name.Name,
-		PKFieldIndex: -1,
+// fileTypeString returns a string representation of any field type like reflect.Type's String method does:
+// byte and rune aliases are resolved.
+func fileTypeString(x ast.Expr) string {
+	switch t := x.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "byte":
+			return "uint8"
+		case "rune":
+			return "int32"
+		default:
+			return t.Name
+		}
+	case *ast.StarExpr:
+		return "*" + fileTypeString(t.X)
+	case *ast.SelectorExpr:
+		return fileTypeString(t.X) + "." + t.Sel.Name
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + fileTypeString(t.Elt)
+		}
+		return "[" + types.ExprString(t.Len) + "]" + fileTypeString(t.Elt)
+	case *ast.MapType:
+		return "map[" + fileTypeString(t.Key) + "]" + fileTypeString(t.Value)
+	default:
+		return types.ExprString(x)
 	}
+}
 
-	var n int
+// parseStructFields appends information about fields of str to res.
+// Fields of embedded structs declared in the same file (given by structs) are flattened.
+func parseStructFields(res *StructInfo, str *ast.StructType, structs map[string]*ast.StructType) error {
 	for _, f := range str.Fields.List {
 		// consider only fields with "reform:" tag
 		if f.Tag == nil {
@@ -44,9 +70,22 @@ func parseStructTypeSpec(ts *ast.TypeSpec, str *ast.StructType) (*StructInfo, er
 			continue
 		}
 
-		// check for anonymous fields
+		// check for anonymous fields, flatten embedded structs
 		if len(f.Names) == 0 {
-			return nil, fmt.Errorf(`reform: %s has anonymous field %s with "reform:" tag, it is not allowed`, res.Type, f.Type)
+			if tag != embedTag {
+				return fmt.Errorf(`reform: %s has anonymous field %s with "reform:" tag, it is not allowed`, res.Type, f.Type)
+			}
+			var embedded *ast.StructType
+			if ident, ok := f.Type.(*ast.Ident); ok {
+				embedded = structs[ident.Name]
+			}
+			if embedded == nil {
+				return fmt.Errorf(`reform: %s has embedded field %s with "reform:" tag which is not a struct declared in the same file, it is not allowed`, res.Type, f.Type)
+			}
+			if err := parseStructFields(res, embedded, structs); err != nil {
+				return err
+			}
+			continue
 		}
 		if len(f.Names) != 1 {
 			panic(fmt.Sprintf("reform: %d names: %#v. Please report this bug.", len(f.Names), f.Names))
@@ -55,34 +94,59 @@ func parseStructTypeSpec(ts *ast.TypeSpec, str *ast.StructType) (*StructInfo, er
 		// check for exported name
 		name := f.Names[0]
 		if !name.IsExported() {
-			return nil, fmt.Errorf(`reform: %s has non-exported field %s with "reform:" tag, it is not allowed`, res.Type, name.Name)
+			return fmt.Errorf(`reform: %s has non-exported field %s with "reform:" tag, it is not allowed`, res.Type, name.Name)
 		}
 
 		// parse tag and type
-		column, isPK := parseStructFieldTag(tag)
-		if column == "" {
-			return nil, fmt.Errorf(`reform: %s has field %s with invalid "reform:" tag value, it is not allowed`, res.Type, name.Name)
+		ft := parseStructFieldTag(tag)
+		if ft.column == "" {
+			return fmt.Errorf(`reform: %s has field %s with invalid "reform:" tag value, it is not allowed`, res.Type, name.Name)
 		}
 		var pkType string
-		if isPK {
+		goType := fileTypeString(f.Type)
+		if ft.pk {
 			pkType = fileGoType(f.Type)
 			if strings.HasPrefix(pkType, "*") {
-				return nil, fmt.Errorf(`reform: %s has pointer field %s with with "pk" label in "reform:" tag, it is not allowed`, res.Type, name.Name)
+				return fmt.Errorf(`reform: %s has pointer field %s with with "pk" label in "reform:" tag, it is not allowed`, res.Type, name.Name)
 			}
 			if res.PKFieldIndex >= 0 {
-				return nil, fmt.Errorf(`reform: %s has field %s with with duplicate "pk" label in "reform:" tag (first used by %s), it is not allowed`, res.Type, name.Name, res.Fields[res.PKFieldIndex].Name)
+				return fmt.Errorf(`reform: %s has field %s with with duplicate "pk" label in "reform:" tag (first used by %s), it is not allowed`, res.Type, name.Name, res.Fields[res.PKFieldIndex].Name)
 			}
 		}
+		if ft.omitEmpty && strings.HasPrefix(goType, "*") {
+			return fmt.Errorf(`reform: %s has pointer field %s with with "omitempty" label in "reform:" tag, it is not allowed`, res.Type, name.Name)
+		}
+		nullable, err := isNullable(res.Type, name.Name, goType, ft.null)
+		if err != nil {
+			return err
+		}
 
 		res.Fields = append(res.Fields, FieldInfo{
-			Name:   name.Name,
-			PKType: pkType,
-			Column: column,
+			Name:      name.Name,
+			PKType:    pkType,
+			Column:    ft.column,
+			GoType:    goType,
+			SQLType:   ft.sqlType,
+			Nullable:  nullable,
+			ReadOnly:  ft.readOnly,
+			OmitEmpty: ft.omitEmpty,
 		})
-		if isPK {
-			res.PKFieldIndex = n
+		if ft.pk {
+			res.PKFieldIndex = len(res.Fields) - 1
 		}
-		n++
+	}
+
+	return nil
+}
+
+func parseStructTypeSpec(ts *ast.TypeSpec, str *ast.StructType, structs map[string]*ast.StructType) (*StructInfo, error) {
+	res := &StructInfo{
+		Type:         ts.Name.Name,
+		PKFieldIndex: -1,
+	}
+
+	if err := parseStructFields(res, str, structs); err != nil {
+		return nil, err
 	}
 
 	if len(res.Fields) == 0 {
@@ -106,6 +170,22 @@ func File(path string) ([]StructInfo, error) {
 		return nil, err
 	}
 
+	// collect all top-level struct type declarations for embedded structs
+	structs := make(map[string]*ast.StructType)
+	for _, decl := range fileNode.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok {
+				if str, ok := ts.Type.(*ast.StructType); ok {
+					structs[ts.Name.Name] = str
+				}
+			}
+		}
+	}
+
 	// consider only top-level struct type declarations with magic comment
 	var res []StructInfo
 	for _, decl := range fileNode.Decls {
@@ -154,7 +234,7 @@ func File(path string) ([]StructInfo, error) {
 			}
 
 			// ast.Print(fset, ts)
-			s, err := parseStructTypeSpec(ts, str)
+			s, err := parseStructTypeSpec(ts, str, structs)
 			if err != nil {
 				return nil, err
 			}
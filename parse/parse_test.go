@@ -17,12 +17,12 @@ var (
 		Type:    "Person",
 		SQLName: "people",
 		Fields: []FieldInfo{
-			{Name: "ID", PKType: "int32", Column: "id"},
-			{Name: "GroupID", Column: "group_id"},
-			{Name: "Name", Column: "name"},
-			{Name: "Email", Column: "email"},
-			{Name: "CreatedAt", Column: "created_at"},
-			{Name: "UpdatedAt", Column: "updated_at"},
+			{Name: "ID", PKType: "int32", Column: "id", GoType: "int32"},
+			{Name: "GroupID", Column: "group_id", GoType: "*int32", Nullable: true},
+			{Name: "Name", Column: "name", GoType: "string"},
+			{Name: "Email", Column: "email", GoType: "*string", Nullable: true},
+			{Name: "CreatedAt", Column: "created_at", GoType: "time.Time"},
+			{Name: "UpdatedAt", Column: "updated_at", GoType: "*time.Time", Nullable: true},
 		},
 		PKFieldIndex: 0,
 	}
@@ -31,10 +31,10 @@ var (
 		Type:    "Project",
 		SQLName: "projects",
 		Fields: []FieldInfo{
-			{Name: "Name", Column: "name"},
-			{Name: "ID", PKType: "string", Column: "id"},
-			{Name: "Start", Column: "start"},
-			{Name: "End", Column: "end"},
+			{Name: "Name", Column: "name", GoType: "string"},
+			{Name: "ID", PKType: "string", Column: "id", GoType: "string"},
+			{Name: "Start", Column: "start", GoType: "time.Time"},
+			{Name: "End", Column: "end", GoType: "*time.Time", Nullable: true},
 		},
 		PKFieldIndex: 1,
 	}
@@ -43,8 +43,8 @@ var (
 		Type:    "PersonProject",
 		SQLName: "person_project",
 		Fields: []FieldInfo{
-			{Name: "PersonID", Column: "person_id"},
-			{Name: "ProjectID", Column: "project_id"},
+			{Name: "PersonID", Column: "person_id", GoType: "int32"},
+			{Name: "ProjectID", Column: "project_id", GoType: "string"},
 		},
 		PKFieldIndex: -1,
 	}
@@ -53,7 +53,7 @@ var (
 		Type:    "IDOnly",
 		SQLName: "id_only",
 		Fields: []FieldInfo{
-			{Name: "ID", PKType: "int32", Column: "id"},
+			{Name: "ID", PKType: "int32", Column: "id", GoType: "int32"},
 		},
 		PKFieldIndex: 0,
 	}
@@ -63,8 +63,8 @@ var (
 		SQLSchema: "legacy",
 		SQLName:   "people",
 		Fields: []FieldInfo{
-			{Name: "ID", PKType: "int32", Column: "id"},
-			{Name: "Name", Column: "name"},
+			{Name: "ID", PKType: "int32", Column: "id", GoType: "int32"},
+			{Name: "Name", Column: "name", GoType: "*string", Nullable: true},
 		},
 		PKFieldIndex: 0,
 	}
@@ -73,12 +73,50 @@ var (
 		Type:    "Extra",
 		SQLName: "extra",
 		Fields: []FieldInfo{
-			{Name: "ID", PKType: "Integer", Column: "id"},
-			{Name: "Name", Column: "name"},
-			{Name: "Bytes", Column: "bytes"},
-			{Name: "Bytes2", Column: "bytes2"},
-			{Name: "Byte", Column: "byte"},
-			{Name: "Array", Column: "array"},
+			{Name: "ID", PKType: "Integer", Column: "id", GoType: "Integer"},
+			{Name: "Name", Column: "name", GoType: "*String", Nullable: true},
+			{Name: "Bytes", Column: "bytes", GoType: "[]uint8"},
+			{Name: "Bytes2", Column: "bytes2", GoType: "Bytes"},
+			{Name: "Byte", Column: "byte", GoType: "*uint8", Nullable: true},
+			{Name: "Array", Column: "array", GoType: "[512]uint8"},
+		},
+		PKFieldIndex: 0,
+	}
+
+	audited = StructInfo{
+		Type:    "Audited",
+		SQLName: "audited",
+		Fields: []FieldInfo{
+			{Name: "ID", PKType: "int32", Column: "id", GoType: "int32"},
+			{Name: "CreatedBy", Column: "created_by", GoType: "string"},
+			{Name: "CreatedAt", Column: "created_at", GoType: "time.Time"},
+			{Name: "Name", Column: "name", GoType: "string"},
+		},
+		PKFieldIndex: 0,
+	}
+
+	defaultedPerson = StructInfo{
+		Type:    "DefaultedPerson",
+		SQLName: "people",
+		Fields: []FieldInfo{
+			{Name: "ID", PKType: "int32", Column: "id", GoType: "int32"},
+			{Name: "GroupID", Column: "group_id", GoType: "int32", OmitEmpty: true},
+			{Name: "Name", Column: "name", GoType: "string"},
+			{Name: "CreatedAt", Column: "created_at", GoType: "time.Time"},
+		},
+		PKFieldIndex: 0,
+	}
+
+	nullPerson = StructInfo{
+		Type:    "NullPerson",
+		SQLName: "people",
+		Fields: []FieldInfo{
+			{Name: "ID", PKType: "int32", Column: "id", GoType: "int32"},
+			{Name: "GroupID", Column: "group_id", GoType: "sql.NullInt64", Nullable: true},
+			{Name: "Name", Column: "name", GoType: "string"},
+			{Name: "Email", Column: "email", GoType: "sql.NullString", Nullable: true},
+			{Name: "CreatedAt", Column: "created_at", GoType: "time.Time"},
+			{Name: "UpdatedAt", Column: "updated_at", GoType: "*time.Time", Nullable: true},
 		},
 		PKFieldIndex: 0,
 	}
@@ -96,20 +134,42 @@ func TestFileGood(t *testing.T) {
 	assert.Equal(t, extra, s[5])
 }
 
+func TestFileEmbedded(t *testing.T) {
+	s, err := File("../internal/test/models/embedded.go")
+	assert.NoError(t, err)
+	require.Len(t, s, 1)
+	assert.Equal(t, audited, s[0])
+}
+
+func TestFileDefaults(t *testing.T) {
+	s, err := File("../internal/test/models/defaults.go")
+	assert.NoError(t, err)
+	require.Len(t, s, 1)
+	assert.Equal(t, defaultedPerson, s[0])
+}
+
+func TestFileNulls(t *testing.T) {
+	s, err := File("../internal/test/models/nulls.go")
+	assert.NoError(t, err)
+	require.Len(t, s, 1)
+	assert.Equal(t, nullPerson, s[0])
+}
+
 func TestFileBogus(t *testing.T) {
 	dir := filepath.FromSlash("../internal/test/models/bogus/")
 	for file, msg := range map[string]error{
-		"bogus1.go": errors.New(`reform: Bogus1 has anonymous field BogusType with "reform:" tag, it is not allowed`),
-		"bogus2.go": errors.New(`reform: Bogus2 has anonymous field bogusType with "reform:" tag, it is not allowed`),
-		"bogus3.go": errors.New(`reform: Bogus3 has non-exported field bogus with "reform:" tag, it is not allowed`),
-		"bogus4.go": errors.New(`reform: Bogus4 has field Bogus with invalid "reform:" tag value, it is not allowed`),
-		"bogus5.go": errors.New(`reform: Bogus5 has field Bogus with invalid "reform:" tag value, it is not allowed`),
-		"bogus6.go": errors.New(`reform: Bogus6 has no fields with "reform:" tag, it is not allowed`),
-		"bogus7.go": errors.New(`reform: Bogus7 has pointer field Bogus with with "pk" label in "reform:" tag, it is not allowed`),
-		// "bogus8.go": errors.New(`reform: Bogus8 has pointer field Bogus with with "omitempty" label in "reform:" tag, it is not allowed`),
-		"bogus8.go":  errors.New(`reform: Bogus8 has field Bogus with invalid "reform:" tag value, it is not allowed`),
+		"bogus1.go":  errors.New(`reform: Bogus1 has anonymous field BogusType with "reform:" tag, it is not allowed`),
+		"bogus2.go":  errors.New(`reform: Bogus2 has anonymous field bogusType with "reform:" tag, it is not allowed`),
+		"bogus3.go":  errors.New(`reform: Bogus3 has non-exported field bogus with "reform:" tag, it is not allowed`),
+		"bogus4.go":  errors.New(`reform: Bogus4 has field Bogus with invalid "reform:" tag value, it is not allowed`),
+		"bogus5.go":  errors.New(`reform: Bogus5 has field Bogus with invalid "reform:" tag value, it is not allowed`),
+		"bogus6.go":  errors.New(`reform: Bogus6 has no fields with "reform:" tag, it is not allowed`),
+		"bogus7.go":  errors.New(`reform: Bogus7 has pointer field Bogus with with "pk" label in "reform:" tag, it is not allowed`),
+		"bogus8.go":  errors.New(`reform: Bogus8 has pointer field Bogus with with "omitempty" label in "reform:" tag, it is not allowed`),
 		"bogus9.go":  errors.New(`reform: Bogus9 has field Bogus2 with "reform:" tag with duplicate column name bogus (used by Bogus1), it is not allowed`),
 		"bogus10.go": errors.New(`reform: Bogus10 has field Bogus2 with with duplicate "pk" label in "reform:" tag (first used by Bogus1), it is not allowed`),
+		"bogus11.go": errors.New(`reform: Bogus11 has duplicate field name Bogus promoted from embedded struct (used by columns bogus and bogus2), it is not allowed`),
+		"bogus12.go": errors.New(`reform: Bogus12 has non-nullable field Bogus with "null" label in "reform:" tag, it is not allowed`),
 
 		"bogus_ignore.go": nil,
 	} {
@@ -139,21 +199,34 @@ func TestObjectGood(t *testing.T) {
 	s, err = Object(new(models.Extra), "", "extra")
 	assert.NoError(t, err)
 	assert.Equal(t, &extra, s)
+
+	s, err = Object(new(models.Audited), "", "audited")
+	assert.NoError(t, err)
+	assert.Equal(t, &audited, s)
+
+	s, err = Object(new(models.DefaultedPerson), "", "people")
+	assert.NoError(t, err)
+	assert.Equal(t, &defaultedPerson, s)
+
+	s, err = Object(new(models.NullPerson), "", "people")
+	assert.NoError(t, err)
+	assert.Equal(t, &nullPerson, s)
 }
 
 func TestObjectBogus(t *testing.T) {
 	for obj, msg := range map[interface{}]error{
-		new(bogus.Bogus1): errors.New(`reform: Bogus1 has anonymous field BogusType with "reform:" tag, it is not allowed`),
-		new(bogus.Bogus2): errors.New(`reform: Bogus2 has anonymous field bogusType with "reform:" tag, it is not allowed`),
-		new(bogus.Bogus3): errors.New(`reform: Bogus3 has non-exported field bogus with "reform:" tag, it is not allowed`),
-		new(bogus.Bogus4): errors.New(`reform: Bogus4 has field Bogus with invalid "reform:" tag value, it is not allowed`),
-		new(bogus.Bogus5): errors.New(`reform: Bogus5 has field Bogus with invalid "reform:" tag value, it is not allowed`),
-		new(bogus.Bogus6): errors.New(`reform: Bogus6 has no fields with "reform:" tag, it is not allowed`),
-		new(bogus.Bogus7): errors.New(`reform: Bogus7 has pointer field Bogus with with "pk" label in "reform:" tag, it is not allowed`),
-		// new(bogus.Bogus8): errors.New(`reform: Bogus8 has pointer field Bogus with with "omitempty" label in "reform:" tag, it is not allowed`),
-		new(bogus.Bogus8):  errors.New(`reform: Bogus8 has field Bogus with invalid "reform:" tag value, it is not allowed`),
+		new(bogus.Bogus1):  errors.New(`reform: Bogus1 has anonymous field BogusType with "reform:" tag, it is not allowed`),
+		new(bogus.Bogus2):  errors.New(`reform: Bogus2 has anonymous field bogusType with "reform:" tag, it is not allowed`),
+		new(bogus.Bogus3):  errors.New(`reform: Bogus3 has non-exported field bogus with "reform:" tag, it is not allowed`),
+		new(bogus.Bogus4):  errors.New(`reform: Bogus4 has field Bogus with invalid "reform:" tag value, it is not allowed`),
+		new(bogus.Bogus5):  errors.New(`reform: Bogus5 has field Bogus with invalid "reform:" tag value, it is not allowed`),
+		new(bogus.Bogus6):  errors.New(`reform: Bogus6 has no fields with "reform:" tag, it is not allowed`),
+		new(bogus.Bogus7):  errors.New(`reform: Bogus7 has pointer field Bogus with with "pk" label in "reform:" tag, it is not allowed`),
+		new(bogus.Bogus8):  errors.New(`reform: Bogus8 has pointer field Bogus with with "omitempty" label in "reform:" tag, it is not allowed`),
 		new(bogus.Bogus9):  errors.New(`reform: Bogus9 has field Bogus2 with "reform:" tag with duplicate column name bogus (used by Bogus1), it is not allowed`),
 		new(bogus.Bogus10): errors.New(`reform: Bogus10 has field Bogus2 with with duplicate "pk" label in "reform:" tag (first used by Bogus1), it is not allowed`),
+		new(bogus.Bogus11): errors.New(`reform: Bogus11 has duplicate field name Bogus promoted from embedded struct (used by columns bogus and bogus2), it is not allowed`),
+		new(bogus.Bogus12): errors.New(`reform: Bogus12 has non-nullable field Bogus with "null" label in "reform:" tag, it is not allowed`),
 
 		// new(bogus.BogusIgnore): do not test,
 	} {
@@ -166,14 +239,32 @@ func TestObjectBogus(t *testing.T) {
 func TestHelpers(t *testing.T) {
 	assert.Equal(t, []string{"id", "group_id", "name", "email", "created_at", "updated_at"}, person.Columns())
 	assert.True(t, person.IsTable())
-	assert.Equal(t, FieldInfo{Name: "ID", PKType: "int32", Column: "id"}, person.PKField())
+	assert.Equal(t, FieldInfo{Name: "ID", PKType: "int32", Column: "id", GoType: "int32"}, person.PKField())
+	assert.Equal(t, person.Fields[1:], person.NonPKFields())
 
 	assert.Equal(t, []string{"name", "id", "start", "end"}, project.Columns())
 	assert.True(t, project.IsTable())
-	assert.Equal(t, FieldInfo{Name: "ID", PKType: "string", Column: "id"}, project.PKField())
+	assert.Equal(t, FieldInfo{Name: "ID", PKType: "string", Column: "id", GoType: "string"}, project.PKField())
+	assert.Equal(t, []FieldInfo{project.Fields[0], project.Fields[2], project.Fields[3]}, project.NonPKFields())
 
 	assert.Equal(t, []string{"person_id", "project_id"}, personProject.Columns())
 	assert.False(t, personProject.IsTable())
+	assert.Equal(t, personProject.Fields, personProject.NonPKFields())
+}
+
+func TestParseStructFieldTag(t *testing.T) {
+	for tag, expected := range map[string]fieldTag{
+		"name":                              {column: "name"},
+		"id,pk":                             {column: "id", pk: true},
+		"email,null,readonly":               {column: "email", null: true, readOnly: true},
+		"price,readonly,type=numeric(10,2)": {column: "price", readOnly: true, sqlType: "numeric(10,2)"},
+		"group_id,omitempty":                {column: "group_id", omitEmpty: true},
+		"name,type=":                        {},
+		"name,foo":                          {},
+		"name,type=text,pk":                 {column: "name", sqlType: "text,pk"},
+	} {
+		assert.Equal(t, expected, parseStructFieldTag(tag), "%s", tag)
+	}
 }
 
 func TestAssertUpToDate(t *testing.T) {
@@ -193,4 +284,12 @@ func TestAssertUpToDate(t *testing.T) {
 		p.PKFieldIndex = 1
 		AssertUpToDate(&p, new(models.Person))
 	}()
+
+	// file generated by older version without GoType and other tag information
+	p := person
+	p.Fields = make([]FieldInfo, len(person.Fields))
+	for i, f := range person.Fields {
+		p.Fields[i] = FieldInfo{Name: f.Name, PKType: f.PKType, Column: f.Column}
+	}
+	AssertUpToDate(&p, new(models.Person))
 }
@@ -45,7 +45,22 @@ func Object(obj interface{}, schema, table string) (res *StructInfo, err error)
 		PKFieldIndex: -1,
 	}
 
-	var n int
+	err = objectFields(res, t, t)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkFields(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// objectFields appends information about fields of struct type t to res, flattening embedded structs.
+// structT is a type of parent struct.
+func objectFields(res *StructInfo, t reflect.Type, structT reflect.Type) error {
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
 		tag := f.Tag.Get("reform")
@@ -53,47 +68,63 @@ func Object(obj interface{}, schema, table string) (res *StructInfo, err error)
 			continue
 		}
 
-		// check for anonymous fields
+		// check for anonymous fields, flatten embedded structs
 		if f.Anonymous {
-			return nil, fmt.Errorf(`reform: %s has anonymous field %s with "reform:" tag, it is not allowed`, res.Type, f.Name)
+			if tag != embedTag {
+				return fmt.Errorf(`reform: %s has anonymous field %s with "reform:" tag, it is not allowed`, res.Type, f.Name)
+			}
+			if f.Type.Kind() != reflect.Struct {
+				return fmt.Errorf(`reform: %s has embedded field %s with "reform:" tag which is not a struct, it is not allowed`, res.Type, f.Name)
+			}
+			if err := objectFields(res, f.Type, structT); err != nil {
+				return err
+			}
+			continue
 		}
 
 		// check for exported name
 		if f.PkgPath != "" {
-			return nil, fmt.Errorf(`reform: %s has non-exported field %s with "reform:" tag, it is not allowed`, res.Type, f.Name)
+			return fmt.Errorf(`reform: %s has non-exported field %s with "reform:" tag, it is not allowed`, res.Type, f.Name)
 		}
 
 		// parse tag and type
-		column, isPK := parseStructFieldTag(tag)
-		if column == "" {
-			return nil, fmt.Errorf(`reform: %s has field %s with invalid "reform:" tag value, it is not allowed`, res.Type, f.Name)
+		ft := parseStructFieldTag(tag)
+		if ft.column == "" {
+			return fmt.Errorf(`reform: %s has field %s with invalid "reform:" tag value, it is not allowed`, res.Type, f.Name)
 		}
 		var pkType string
-		if isPK {
-			pkType = objectGoType(f.Type, t)
+		goType := objectGoType(f.Type, structT)
+		if ft.pk {
+			pkType = objectGoType(f.Type, structT)
 			if strings.HasPrefix(pkType, "*") {
-				return nil, fmt.Errorf(`reform: %s has pointer field %s with with "pk" label in "reform:" tag, it is not allowed`, res.Type, f.Name)
+				return fmt.Errorf(`reform: %s has pointer field %s with with "pk" label in "reform:" tag, it is not allowed`, res.Type, f.Name)
 			}
 			if res.PKFieldIndex >= 0 {
-				return nil, fmt.Errorf(`reform: %s has field %s with with duplicate "pk" label in "reform:" tag (first used by %s), it is not allowed`, res.Type, f.Name, res.Fields[res.PKFieldIndex].Name)
+				return fmt.Errorf(`reform: %s has field %s with with duplicate "pk" label in "reform:" tag (first used by %s), it is not allowed`, res.Type, f.Name, res.Fields[res.PKFieldIndex].Name)
 			}
 		}
+		if ft.omitEmpty && strings.HasPrefix(goType, "*") {
+			return fmt.Errorf(`reform: %s has pointer field %s with with "omitempty" label in "reform:" tag, it is not allowed`, res.Type, f.Name)
+		}
+		nullable, err := isNullable(res.Type, f.Name, goType, ft.null)
+		if err != nil {
+			return err
+		}
 
 		res.Fields = append(res.Fields, FieldInfo{
-			Name:   f.Name,
-			PKType: pkType,
-			Column: column,
+			Name:      f.Name,
+			PKType:    pkType,
+			Column:    ft.column,
+			GoType:    goType,
+			SQLType:   ft.sqlType,
+			Nullable:  nullable,
+			ReadOnly:  ft.readOnly,
+			OmitEmpty: ft.omitEmpty,
 		})
-		if isPK {
-			res.PKFieldIndex = n
+		if ft.pk {
+			res.PKFieldIndex = len(res.Fields) - 1
 		}
-		n++
 	}
 
-	err = checkFields(res)
-	if err != nil {
-		return nil, err
-	}
-
-	return
+	return nil
 }
@@ -1,25 +1,172 @@
 package reform
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"gopkg.in/doug-martin/goqu.v3"
 )
 
 // Querier performs queries and commands.
 type Querier struct {
 	dbtx DBTX
+	ctx  context.Context
+
+	// renamed view uses renamedTo name, see WithTableName
+	renamed   View
+	renamedTo string
+
+	// columns is shared by Queriers derived from the same DB or TX
+	columns *columnsCache
+
 	Dialect
 	Logger Logger
+
+	// QueryRewriter, if set, is called by Exec, Query and QueryRow with operation
+	// (lowercased first keyword of query, like "select", "insert", "update" or "delete")
+	// and final query after "$Field" expansion. Returned query is logged and executed instead.
+	// It can be used to add hints or comments (like Citus shard hints) or to rewrite table names.
+	// Since it runs after placeholders are generated and arguments are collected, returned query
+	// must keep the same placeholders in the same order.
+	QueryRewriter func(op string, query string) string
+
+	// Transformers, if set, maps views to column names to ColumnTransformers,
+	// which are applied to values by insert and update methods and after scanning by select methods.
+	Transformers map[View]map[string]ColumnTransformer
+
+	// Metrics, if set, collects counts, errors and durations of insert, update, delete and select operations.
+	Metrics Metrics
+
+	// Location, if set, is used for all time.Time and *time.Time fields read by select methods.
+	// Such values are converted to UTC by insert and update methods.
+	Location *time.Location
+
+	// MaxInsertMultiRows, if set, limits the number of rows inserted by a single statement
+	// by InsertMulti and InsertMultiReturning, in addition to dialect's MaxPlaceholders.
+	MaxInsertMultiRows int
+
+	// SchemaOverride, if set, is used instead of view's Schema() to qualify view names in all queries.
+	// It allows to use the same models with different schemas, for example, per tenant.
+	SchemaOverride string
+
+	// SkipBulkHooks, if true, makes InsertMulti* methods skip per-struct BeforeInserterQ, BeforeInserter
+	// and AfterInserter hooks, and bulk methods skip view's BulkHooker hooks.
+	// It can be used for performance of large imports. Enum values are still checked.
+	SkipBulkHooks bool
+
+	// PanicOnMultiAffected, if true (default), makes update and Delete methods panic
+	// if more than one row was affected by primary key, which means broken primary key constraint.
+	// If false, they return ErrMultipleRowsAffected instead; changes are not reverted.
+	PanicOnMultiAffected bool
 }
 
 func newQuerier(dbtx DBTX, dialect Dialect, logger Logger) *Querier {
 	return &Querier{
-		dbtx:    dbtx,
-		Dialect: dialect,
-		Logger:  logger,
+		dbtx:                 dbtx,
+		columns:              &columnsCache{m: make(map[columnsCacheKey]string)},
+		Dialect:              dialect,
+		Logger:               logger,
+		PanicOnMultiAffected: true,
 	}
 }
 
+// multiAffected panics or returns ErrMultipleRowsAffected for ra rows affected by op by primary key,
+// depending on PanicOnMultiAffected.
+func (q *Querier) multiAffected(op string, ra int64) error {
+	if q.PanicOnMultiAffected {
+		panic(fmt.Sprintf("reform: %d rows by %s by primary key. Please report this bug.", ra, op))
+	}
+	return ErrMultipleRowsAffected
+}
+
+// withDBTX returns a copy of q with the same settings for another DBTX.
+func (q *Querier) withDBTX(dbtx DBTX) *Querier {
+	c := *q
+	c.dbtx = dbtx
+	return &c
+}
+
+// WithTx returns a copy of q which runs queries in given transaction.
+// It shares Dialect, Logger and other settings with q, so the same code can transparently
+// run against a connection or a transaction.
+func (q *Querier) WithTx(tx *sql.Tx) *Querier {
+	return q.withDBTX(tx)
+}
+
+// WithContext returns a copy of q which runs all queries and commands with given context,
+// so cancellation and deadlines propagate to them without Context method variants.
+// Context is bound to the returned Querier instance only; q itself and other copies are not affected.
+// Transactions started by q's methods (like SaveMulti) are started with it too.
+// If underlying DBTX has no context-aware methods (like ExecContext of *sql.DB and *sql.Tx),
+// context is ignored.
+func (q *Querier) WithContext(ctx context.Context) *Querier {
+	if ctx == nil {
+		panic("reform: nil context")
+	}
+	c := *q
+	c.ctx = ctx
+	return &c
+}
+
+// tableNameRE matches safe unquoted table names.
+var tableNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WithTableName returns a copy of q which uses given name for view in all queries and commands instead of view's Name(),
+// while still using view's columns. It can be used for blue/green table swaps and partitioned tables
+// (like "orders_2025" for "orders" model). Schema is not changed, see SchemaOverride for that.
+// Name should be a safe unquoted identifier; it is quoted by dialect. Metrics still use view's Name().
+func (q *Querier) WithTableName(view View, name string) (*Querier, error) {
+	if !tableNameRE.MatchString(name) {
+		return nil, fmt.Errorf("reform: unsafe table name %q", name)
+	}
+	c := *q
+	c.renamed = view
+	c.renamedTo = name
+	return &c, nil
+}
+
+// viewName returns view's name, or name set by WithTableName.
+func (q *Querier) viewName(view View) string {
+	if q.renamedTo != "" && view == q.renamed {
+		return q.renamedTo
+	}
+	return view.Name()
+}
+
+// Context returns context bound to q by WithContext, or context.Background().
+func (q *Querier) Context() context.Context {
+	if q.ctx == nil {
+		return context.Background()
+	}
+	return q.ctx
+}
+
+// contextDBTX is a subset of DBTX's methods with context, implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type contextDBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// contextDBTX returns q's DBTX as contextDBTX if context was bound by WithContext and DBTX supports it.
+func (q *Querier) contextDBTX() (contextDBTX, bool) {
+	if q.ctx == nil {
+		return nil, false
+	}
+	c, ok := q.dbtx.(contextDBTX)
+	return c, ok
+}
+
 func (q *Querier) logBefore(query string, args []interface{}) {
 	if q.Logger != nil {
 		q.Logger.Before(query, args)
@@ -32,11 +179,155 @@ func (q *Querier) logAfter(query string, args []interface{}, d time.Duration, er
 	}
 }
 
+// inTransaction calls f with Querier for a new transaction if q is not a transaction itself
+// (on the same connection for Conn),
+// rolling back it in case of error or panic, committing otherwise.
+// If q is already a transaction, f is called with q.
+func (q *Querier) inTransaction(f func(q *Querier) error) error {
+	// Querier of Conn starts transaction on its connection
+	if c, ok := q.dbtx.(interface {
+		inTransaction(q *Querier, f func(q *Querier) error) error
+	}); ok {
+		return c.inTransaction(q, f)
+	}
+
+	db, ok := q.dbtx.(DBInterface)
+	if !ok {
+		return f(q)
+	}
+
+	d := &DB{Querier: q, db: db}
+	fn := func(t *TX) error {
+		return f(t.Querier)
+	}
+	if _, ok = db.(interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	}); ok && q.ctx != nil {
+		return d.InTransactionOpts(q.ctx, nil, fn)
+	}
+	return d.InTransaction(fn)
+}
+
+// observe reports operation op on view started at start to Metrics, if set.
+// ErrNoRows is not reported as error.
+func (q *Querier) observe(op string, view View, start time.Time, err *error) {
+	if q.Metrics == nil {
+		return
+	}
+
+	table := view.Name()
+	q.Metrics.IncOp(op, table)
+	q.Metrics.ObserveDuration(op, table, time.Now().Sub(start))
+	if *err != nil && *err != ErrNoRows {
+		q.Metrics.IncError(op, table)
+	}
+}
+
+// toDB replaces values of given view's columns with results of ColumnTransformer's ToDB.
+// If Location is set, it also converts time.Time and *time.Time values to UTC first.
+func (q *Querier) toDB(view View, columns []string, values []interface{}) error {
+	if q.Location != nil {
+		for i, v := range values {
+			switch v := v.(type) {
+			case time.Time:
+				values[i] = v.UTC()
+			case *time.Time:
+				if v != nil {
+					t := v.UTC()
+					values[i] = &t
+				}
+			}
+		}
+	}
+
+	transformers := q.Transformers[view]
+	if len(transformers) == 0 {
+		return nil
+	}
+
+	for i, c := range columns {
+		t, ok := transformers[c]
+		if !ok || t.ToDB == nil {
+			continue
+		}
+		v, err := t.ToDB(values[i])
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+	return nil
+}
+
+// fromDB replaces str's scanned field values with results of ColumnTransformer's FromDB.
+// If Location is set, it then converts time.Time and *time.Time fields to it.
+func (q *Querier) fromDB(str Struct) error {
+	if err := q.transformFromDB(str); err != nil {
+		return err
+	}
+
+	if q.Location != nil {
+		for _, p := range str.Pointers() {
+			switch p := p.(type) {
+			case *time.Time:
+				*p = p.In(q.Location)
+			case **time.Time:
+				if *p != nil {
+					t := (*p).In(q.Location)
+					*p = &t
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// transformFromDB replaces str's scanned field values with results of ColumnTransformer's FromDB.
+func (q *Querier) transformFromDB(str Struct) error {
+	view := str.View()
+	transformers := q.Transformers[view]
+	if len(transformers) == 0 {
+		return nil
+	}
+
+	pointers := str.Pointers()
+	for i, c := range view.Columns() {
+		t, ok := transformers[c]
+		if !ok || t.FromDB == nil {
+			continue
+		}
+		field := reflect.ValueOf(pointers[i]).Elem()
+		v, err := t.FromDB(field.Interface())
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+		rv := reflect.ValueOf(v)
+		if !rv.Type().AssignableTo(field.Type()) {
+			return fmt.Errorf("reform: FromDB for column %s returned %T, expected %s", c, v, field.Type())
+		}
+		field.Set(rv)
+	}
+	return nil
+}
+
+// viewSchema returns SchemaOverride, if set, or view's schema.
+func (q *Querier) viewSchema(view View) string {
+	if q.SchemaOverride != "" {
+		return q.SchemaOverride
+	}
+	return view.Schema()
+}
+
 // QualifiedView returns quoted qualified view name.
+// SchemaOverride, if set, is used instead of view's schema.
 func (q *Querier) QualifiedView(view View) string {
-	v := q.QuoteIdentifier(view.Name())
-	if view.Schema() != "" {
-		v = q.QuoteIdentifier(view.Schema()) + "." + v
+	v := q.QuoteIdentifier(q.viewName(view))
+	if schema := q.viewSchema(view); schema != "" {
+		v = q.QuoteIdentifier(schema) + "." + v
 	}
 	return v
 }
@@ -51,12 +342,558 @@ func (q *Querier) QualifiedColumns(view View) []string {
 	return res
 }
 
+// QualifiedColumnsAs returns a slice of quoted column names qualified with quoted alias instead of view name.
+// It is useful for queries where the same view is used several times, like self-joins.
+func (q *Querier) QualifiedColumnsAs(view View, alias string) []string {
+	a := q.QuoteIdentifier(alias)
+	res := view.Columns()
+	for i := 0; i < len(res); i++ {
+		res[i] = a + "." + q.QuoteIdentifier(res[i])
+	}
+	return res
+}
+
+// NullsOrder defines a position of NULL values in "ORDER BY".
+type NullsOrder int
+
+const (
+	// NullsDefault places NULL values as if they are larger than any other value:
+	// last for ascending order, first for descending order (like PostgreSQL does by default).
+	NullsDefault NullsOrder = iota
+
+	// NullsFirst places NULL values first.
+	NullsFirst
+
+	// NullsLast places NULL values last.
+	NullsLast
+)
+
+// Order describes a single "ORDER BY" item.
+type Order struct {
+	Column string     // column or field name
+	Desc   bool       // true for descending order
+	Nulls  NullsOrder // position of NULL values
+}
+
+// OrderBy returns "ORDER BY" clause for given view and orders with explicit position of NULL values,
+// so results are ordered the same way by all databases. It uses dialect's NullsOrderingMethod.
+// It returns empty string if no orders are given.
+func (q *Querier) OrderBy(view View, orders ...Order) string {
+	if len(orders) == 0 {
+		return ""
+	}
+
+	v := q.QualifiedView(view)
+	res := make([]string, len(orders))
+	for i, o := range orders {
+		col := v + "." + q.QuoteIdentifier(view.ToCol(o.Column))
+		dir := "ASC"
+		if o.Desc {
+			dir = "DESC"
+		}
+
+		nullsFirst := o.Desc
+		switch o.Nulls {
+		case NullsFirst:
+			nullsFirst = true
+		case NullsLast:
+			nullsFirst = false
+		}
+
+		switch q.NullsOrderingMethod() {
+		case NullsFirstLast:
+			nulls := "NULLS LAST"
+			if nullsFirst {
+				nulls = "NULLS FIRST"
+			}
+			res[i] = col + " " + dir + " " + nulls
+		case NullsCase:
+			n := 1
+			if nullsFirst {
+				n = 0
+			}
+			res[i] = fmt.Sprintf("CASE WHEN %s IS NULL THEN %d ELSE %d END, %s %s", col, n, 1-n, col, dir)
+		default:
+			panic("reform: Unhandled NullsOrderingMethod. Please report this bug.")
+		}
+	}
+	return "ORDER BY " + strings.Join(res, ", ")
+}
+
+// columnsCacheKey is a key for columnsCache.
+type columnsCacheKey struct {
+	view   View
+	schema string
+}
+
+// columnsCache contains joined quoted qualified column names per view and schema override.
+// It is kept per DB or TX, so it does not depend on comparability of Dialect.
+type columnsCache struct {
+	rw sync.RWMutex
+	m  map[columnsCacheKey]string
+}
+
+// qualifiedColumnsList returns quoted qualified column names for given view joined with ", ".
+// Result is cached per view and schema override, unless view is renamed by WithTableName.
+func (q *Querier) qualifiedColumnsList(view View) string {
+	if q.columns == nil || (q.renamedTo != "" && view == q.renamed) {
+		return strings.Join(q.QualifiedColumns(view), ", ")
+	}
+
+	key := columnsCacheKey{view: view, schema: q.SchemaOverride}
+	q.columns.rw.RLock()
+	res, ok := q.columns.m[key]
+	q.columns.rw.RUnlock()
+	if ok {
+		return res
+	}
+
+	res = strings.Join(q.QualifiedColumns(view), ", ")
+	q.columns.rw.Lock()
+	q.columns.m[key] = res
+	q.columns.rw.Unlock()
+	return res
+}
+
+// dsView contains goqu expressions for view's name and columns.
+// They are identifier expressions, so goqu quotes them like reform's QuoteIdentifier does
+// (given that goqu adapter matches dialect), and reserved words (like "order" or "select") can be used.
+type dsView struct {
+	from    goqu.IdentifierExpression
+	columns []interface{}
+}
+
+// dsViewCache contains *dsView per view. Expressions do not depend on goqu dialect,
+// which is taken from the dataset they are applied to.
+var (
+	dsViewCacheRW sync.RWMutex
+	dsViewCache   = make(map[View]*dsView)
+)
+
+// getDsView returns cached goqu expressions for given view.
+func getDsView(view View) *dsView {
+	dsViewCacheRW.RLock()
+	res, ok := dsViewCache[view]
+	dsViewCacheRW.RUnlock()
+	if ok {
+		return res
+	}
+
+	from := view.Name()
+	if view.Schema() != "" {
+		from = view.Schema() + "." + from
+	}
+	res = &dsView{
+		from:    goqu.I(from),
+		columns: make([]interface{}, len(view.IColumns())),
+	}
+	for i, c := range view.IColumns() {
+		if s, ok := c.(string); ok {
+			c = goqu.I(s)
+		}
+		res.columns[i] = c
+	}
+	dsViewCacheRW.Lock()
+	dsViewCache[view] = res
+	dsViewCacheRW.Unlock()
+	return res
+}
+
+// dsFromExpr returns goqu expression for view name qualified with schema.
+func (q *Querier) dsFromExpr(view View) goqu.IdentifierExpression {
+	if q.SchemaOverride != "" || q.renamedTo != "" {
+		from := q.viewName(view)
+		if schema := q.viewSchema(view); schema != "" {
+			from = schema + "." + from
+		}
+		return goqu.I(from)
+	}
+	return getDsView(view).from
+}
+
+// dsFrom returns a copy of ds with "FROM" clause set to view.
+func (q *Querier) dsFrom(ds *goqu.Dataset, view View) *goqu.Dataset {
+	return ds.From(q.dsFromExpr(view))
+}
+
+// dsSelectFrom returns a copy of ds with "FROM" clause set to view and all view's columns selected.
+func (q *Querier) dsSelectFrom(ds *goqu.Dataset, view View) *goqu.Dataset {
+	return ds.From(q.dsFromExpr(view)).Select(getDsView(view).columns...)
+}
+
+// Dataset returns a new goqu dataset with "FROM" clause set to view (qualified with SchemaOverride
+// or view's schema) and all view's columns selected. It uses goqu adapter of Querier's dialect,
+// so generated SQL matches it. It is not bound to database connection: refine it with Where, Order, Join, etc.
+// and pass to Ds* methods, like DsSelectAllFrom.
+//
+// Identifiers in datasets are quoted by goqu adapter, not by reform's dialect. They match only if dataset
+// is created with dialect's GoquAdapter (as Dataset does), for example, with goqu.New(q.GoquAdapter(), nil).
+// For dialects without goqu adapter (GoquAdapter returns empty string) goqu's default quoting with '"' is used,
+// so datasets should not be used with them if they quote identifiers differently (like MS SQL Server's "[]").
+// In conditions, use goqu.I for columns, so they are quoted too.
+func (q *Querier) Dataset(view View) *goqu.Dataset {
+	return q.dsSelectFrom(goqu.New(q.GoquAdapter(), nil).From(), view)
+}
+
+// DsMode defines a kind of statement built by DsToSQL.
+type DsMode int
+
+const (
+	// SelectDsMode builds "SELECT" of all view's columns, like DsSelectAllFrom does.
+	SelectDsMode DsMode = iota
+
+	// CountDsMode builds "SELECT COUNT(*)", like DsCount does.
+	CountDsMode
+
+	// DeleteDsMode builds "DELETE", like DsDelete does.
+	DeleteDsMode
+
+	// UpdateDsMode builds "UPDATE" with given update values.
+	UpdateDsMode
+)
+
+// DsToSQL returns query (with "$Field" references expanded) and args which Ds* methods execute for
+// view, ds and mode, without executing them. It can be used for logging and golden tests.
+// update is passed to goqu's ToUpdateSql (it can be goqu.Record or map) for UpdateDsMode, and ignored otherwise.
+func (q *Querier) DsToSQL(view View, ds *goqu.Dataset, mode DsMode, update interface{}) (query string, args []interface{}, err error) {
+	switch mode {
+	case SelectDsMode:
+		query, args, err = q.dsSelectFrom(ds, view).ToSql()
+	case CountDsMode:
+		query, args, err = q.dsFrom(ds, view).Select(goqu.COUNT(goqu.Star()).As("count")).ToSql()
+	case DeleteDsMode:
+		query, args, err = q.dsFrom(ds, view).ToDeleteSql()
+	case UpdateDsMode:
+		query, args, err = q.dsFrom(ds, view).ToUpdateSql(update)
+	default:
+		err = fmt.Errorf("reform: unexpected DsMode %d", mode)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return Expand(view, query), args, nil
+}
+
+// Expand replaces "$Field" references (and "$column" references) in query with view's column names,
+// like Querier's methods do for tails. It can be used for hand-written queries.
+// Unknown "$Name"s are replaced with names as is, matching ToCol's fallback.
+// Numbered placeholders (like PostgreSQL's "$1") are kept as is.
+func Expand(view View, query string) string {
+	return os.Expand(query, func(name string) string {
+		// os.Expand reads a single digit after "$", so "$12" is "$1" followed by "2"
+		if len(name) == 1 && name[0] >= '0' && name[0] <= '9' {
+			return "$" + name
+		}
+		return view.ToCol(name)
+	})
+}
+
+// Expand replaces "$Field" references in query with view's column names. See package-level Expand for details.
+func (q *Querier) Expand(view View, query string) string {
+	return Expand(view, query)
+}
+
+// isNameChar returns true if c can be used in the name of named argument.
+func isNameChar(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	default:
+		return false
+	}
+}
+
+// numberedPlaceholders returns true if dialect uses numbered placeholders (like PostgreSQL's "$1"),
+// false if it uses the same placeholder for all parameters (like "?").
+func (q *Querier) numberedPlaceholders() bool {
+	return q.Placeholder(1) != q.Placeholder(2)
+}
+
+// NamedTail replaces ":name" markers in tail with dialect's placeholders and returns
+// new tail and a slice of arguments for them taken from args.
+// For dialects with numbered placeholders (like PostgreSQL's "$1") repeated names reuse the same placeholder,
+// for other dialects arguments are repeated. "::" (like PostgreSQL's type cast) is left as is,
+// as are markers inside single-quoted string literals and dialect's quoted identifiers.
+//
+// It returns error if tail contains a name not present in args.
+func (q *Querier) NamedTail(tail string, args map[string]interface{}) (string, []interface{}, error) {
+	return q.replaceNames(tail, ':', args, false)
+}
+
+// NamedArgsTail is like NamedTail, but uses "@name" markers; "@@" (like MS SQL Server's "@@ROWCOUNT") is left as is.
+// For dialects with AtNamedArgs NamedArgMethod markers are left as is too, and arguments are returned
+// as sql.NamedArg values, one per name, in order of first appearance, so the driver binds them by name.
+// It allows calling stored procedures with named parameters, like "EXEC proc @a, @b".
+// For other dialects markers are replaced with placeholders like NamedTail does.
+//
+// It returns error if tail contains a name not present in args.
+func (q *Querier) NamedArgsTail(tail string, args map[string]interface{}) (string, []interface{}, error) {
+	return q.replaceNames(tail, '@', args, q.NamedArgMethod() == AtNamedArgs)
+}
+
+// replaceNames implements NamedTail and NamedArgsTail for given marker.
+// If named is true, markers are left as is, and sql.NamedArg arguments are returned.
+func (q *Querier) replaceNames(tail string, marker byte, args map[string]interface{}, named bool) (string, []interface{}, error) {
+	reuse := named || q.numberedPlaceholders()
+	quote := q.QuoteIdentifier("") // open and close characters, like `""` or "[]"
+	indexes := make(map[string]int, len(args))
+	var res []interface{}
+	var buf bytes.Buffer
+	for i := 0; i < len(tail); i++ {
+		c := tail[i]
+
+		// copy string literal or quoted identifier as is; doubled quotes are handled as two adjacent ones
+		var end byte
+		switch c {
+		case '\'':
+			end = c
+		case quote[0]:
+			end = quote[1]
+		}
+		if end != 0 {
+			j := strings.IndexByte(tail[i+1:], end)
+			if j < 0 {
+				j = len(tail)
+			} else {
+				j += i + 2
+			}
+			buf.WriteString(tail[i:j])
+			i = j - 1
+			continue
+		}
+
+		if c != marker {
+			buf.WriteByte(c)
+			continue
+		}
+		if i+1 < len(tail) && tail[i+1] == marker {
+			buf.WriteByte(marker)
+			buf.WriteByte(marker)
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(tail) && isNameChar(tail[j], j == i+1) {
+			j++
+		}
+		if j == i+1 {
+			buf.WriteByte(c)
+			continue
+		}
+
+		name := tail[i+1 : j]
+		arg, ok := args[name]
+		if !ok {
+			return "", nil, fmt.Errorf("reform: missing named argument: %s", name)
+		}
+		index, ok := indexes[name]
+		if !ok || !reuse {
+			if named {
+				arg = sql.Named(name, arg)
+			}
+			res = append(res, arg)
+			index = len(res)
+			indexes[name] = index
+		}
+		if named {
+			buf.WriteString(tail[i:j])
+		} else {
+			buf.WriteString(q.Placeholder(index))
+		}
+		i = j - 1
+	}
+
+	return buf.String(), res, nil
+}
+
+// isSliceArg returns true if arg is a slice (but not []byte) which should be expanded by SliceArgs.
+func isSliceArg(arg interface{}) bool {
+	t := reflect.TypeOf(arg)
+	return t != nil && t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
+
+// inPrefix matches "IN (" or "NOT IN (" before placeholder.
+var inPrefix = regexp.MustCompile(`(?i)\b(NOT\s+)?IN\s*\(\s*$`)
+
+// SliceArgs rewrites tail and args, so slice arguments (except []byte) can be used with "IN (placeholder)" conditions.
+// Depending on dialect's SliceArgMethod, each slice argument is either expanded into a list of placeholders,
+// or passed as a single array parameter with "IN (placeholder)" replaced by "= ANY(placeholder)"
+// and "NOT IN (placeholder)" replaced by "<> ALL(placeholder)". Other arguments are left as is.
+// Note that in the first case empty slice is replaced with NULL, so both IN and NOT IN conditions are not true.
+func (q *Querier) SliceArgs(tail string, args []interface{}) (string, []interface{}) {
+	numbered := q.numberedPlaceholders()
+	array := numbered && q.SliceArgMethod() == ArraySliceArg
+
+	// make new arguments, remember start index and count of them for each old argument
+	newArgs := make([]interface{}, 0, len(args))
+	starts := make([]int, len(args))
+	counts := make([]int, len(args))
+	for i, arg := range args {
+		starts[i] = len(newArgs) + 1
+		switch {
+		case !isSliceArg(arg):
+			newArgs = append(newArgs, arg)
+		case array:
+			newArgs = append(newArgs, arrayArg{arg})
+		default:
+			v := reflect.ValueOf(arg)
+			for j := 0; j < v.Len(); j++ {
+				newArgs = append(newArgs, v.Index(j).Interface())
+			}
+		}
+		counts[i] = len(newArgs) + 1 - starts[i]
+	}
+
+	// numbered placeholders are prefix, number and suffix, like "$1" or "{1}"
+	prefix := q.Placeholder(1)
+	var suffix string
+	if numbered {
+		if i := strings.LastIndex(prefix, "1"); i >= 0 {
+			prefix, suffix = prefix[:i], prefix[i+1:]
+		}
+	}
+
+	var buf bytes.Buffer
+	var next int // index of argument for next not numbered placeholder
+	for i := 0; i < len(tail); i++ {
+		if !strings.HasPrefix(tail[i:], prefix) {
+			buf.WriteByte(tail[i])
+			continue
+		}
+
+		// find argument index and placeholder end
+		index := -1
+		end := i + len(prefix)
+		if numbered {
+			for end < len(tail) && tail[end] >= '0' && tail[end] <= '9' {
+				end++
+			}
+			if n, err := strconv.Atoi(tail[i+len(prefix) : end]); err == nil && strings.HasPrefix(tail[end:], suffix) {
+				index = n - 1
+				end += len(suffix)
+			}
+		} else {
+			index = next
+			next++
+		}
+		if index < 0 || index >= len(args) {
+			buf.WriteString(tail[i:end])
+			i = end - 1
+			continue
+		}
+
+		switch {
+		case !isSliceArg(args[index]):
+			buf.WriteString(q.Placeholder(starts[index]))
+		case array:
+			if m := inPrefix.FindStringSubmatchIndex(buf.String()); m != nil {
+				buf.Truncate(m[0])
+				if m[2] < 0 {
+					buf.WriteString("= ANY(")
+				} else {
+					buf.WriteString("<> ALL(")
+				}
+			}
+			buf.WriteString(q.Placeholder(starts[index]))
+		case counts[index] == 0:
+			buf.WriteString("NULL")
+		default:
+			buf.WriteString(strings.Join(q.Placeholders(starts[index], counts[index]), ", "))
+		}
+		i = end - 1
+	}
+
+	return buf.String(), newArgs
+}
+
+// arrayArg is a driver.Valuer for slice argument passed as PostgreSQL array.
+type arrayArg struct {
+	slice interface{}
+}
+
+// Value returns PostgreSQL array literal for slice.
+func (a arrayArg) Value() (driver.Value, error) {
+	v := reflect.ValueOf(a.slice)
+	elems := make([]string, v.Len())
+	for i := range elems {
+		elem, err := arrayElem(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = elem
+	}
+	return "{" + strings.Join(elems, ",") + "}", nil
+}
+
+// arrayElem returns PostgreSQL array literal element for e.
+// driver.Valuer elements are converted with Value(), time.Time elements are formatted as RFC 3339.
+func arrayElem(e reflect.Value) (string, error) {
+	for {
+		if (e.Kind() == reflect.Ptr || e.Kind() == reflect.Interface) && e.IsNil() {
+			return "NULL", nil
+		}
+		if valuer, ok := e.Interface().(driver.Valuer); ok {
+			dv, err := valuer.Value()
+			if err != nil {
+				return "", err
+			}
+			if dv == nil {
+				return "NULL", nil
+			}
+			e = reflect.ValueOf(dv)
+			break
+		}
+		if e.Kind() != reflect.Ptr && e.Kind() != reflect.Interface {
+			break
+		}
+		e = e.Elem()
+	}
+
+	if t, ok := e.Interface().(time.Time); ok {
+		return `"` + t.Format(time.RFC3339Nano) + `"`, nil
+	}
+	switch {
+	case e.Kind() == reflect.String:
+		return `"` + arrayEscaper.Replace(e.String()) + `"`, nil
+	case e.Kind() == reflect.Slice && e.Type().Elem().Kind() == reflect.Uint8:
+		return `"` + arrayEscaper.Replace(string(e.Bytes())) + `"`, nil
+	default:
+		return fmt.Sprint(e.Interface()), nil
+	}
+}
+
+// arrayEscaper escapes PostgreSQL array literal element.
+var arrayEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// rewrite returns query rewritten by QueryRewriter, if it is set.
+func (q *Querier) rewrite(query string) string {
+	if q.QueryRewriter == nil {
+		return query
+	}
+
+	op := strings.TrimSpace(query)
+	if i := strings.IndexAny(op, " \t\r\n("); i >= 0 {
+		op = op[:i]
+	}
+	return q.QueryRewriter(strings.ToLower(op), query)
+}
+
 // Exec executes a query without returning any rows.
 // The args are for any placeholder parameters in the query.
 func (q *Querier) Exec(query string, args ...interface{}) (sql.Result, error) {
+	query = q.rewrite(query)
 	start := time.Now()
 	q.logBefore(query, args)
-	res, err := q.dbtx.Exec(query, args...)
+	var res sql.Result
+	var err error
+	if c, ok := q.contextDBTX(); ok {
+		res, err = c.ExecContext(q.ctx, query, args...)
+	} else {
+		res, err = q.dbtx.Exec(query, args...)
+	}
 	q.logAfter(query, args, time.Now().Sub(start), err)
 	return res, err
 }
@@ -64,9 +901,16 @@ func (q *Querier) Exec(query string, args ...interface{}) (sql.Result, error) {
 // Query executes a query that returns rows, typically a SELECT.
 // The args are for any placeholder parameters in the query.
 func (q *Querier) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	query = q.rewrite(query)
 	start := time.Now()
 	q.logBefore(query, args)
-	rows, err := q.dbtx.Query(query, args...)
+	var rows *sql.Rows
+	var err error
+	if c, ok := q.contextDBTX(); ok {
+		rows, err = c.QueryContext(q.ctx, query, args...)
+	} else {
+		rows, err = q.dbtx.Query(query, args...)
+	}
 	q.logAfter(query, args, time.Now().Sub(start), err)
 	return rows, err
 }
@@ -74,12 +918,44 @@ func (q *Querier) Query(query string, args ...interface{}) (*sql.Rows, error) {
 // QueryRow executes a query that is expected to return at most one row.
 // QueryRow always returns a non-nil value. Errors are deferred until Row's Scan method is called.
 func (q *Querier) QueryRow(query string, args ...interface{}) *sql.Row {
+	query = q.rewrite(query)
 	start := time.Now()
 	q.logBefore(query, args)
-	row := q.dbtx.QueryRow(query, args...)
+	var row *sql.Row
+	if c, ok := q.contextDBTX(); ok {
+		row = c.QueryRowContext(q.ctx, query, args...)
+	} else {
+		row = q.dbtx.QueryRow(query, args...)
+	}
 	q.logAfter(query, args, time.Now().Sub(start), nil)
 	return row
 }
 
+// Prepare creates a prepared statement for later queries or executions.
+// It returns error if underlying DBTX (like *sql.DB or *sql.Tx) does not support it.
+func (q *Querier) Prepare(query string) (*sql.Stmt, error) {
+	p, ok := q.dbtx.(interface {
+		Prepare(query string) (*sql.Stmt, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("reform: DBTX does not support Prepare")
+	}
+
+	query = q.rewrite(query)
+	start := time.Now()
+	q.logBefore(query, nil)
+	var stmt *sql.Stmt
+	var err error
+	if pc, ok := q.dbtx.(interface {
+		PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	}); ok && q.ctx != nil {
+		stmt, err = pc.PrepareContext(q.ctx, query)
+	} else {
+		stmt, err = p.Prepare(query)
+	}
+	q.logAfter(query, nil, time.Now().Sub(start), err)
+	return stmt, err
+}
+
 // check interface
 var _ DBTX = new(Querier)
@@ -1,9 +1,11 @@
 package reform
 
 import (
+	"database/sql"
 	"fmt"
-	"os"
+	"reflect"
 	"strings"
+	"time"
 
 	"gopkg.in/doug-martin/goqu.v3"
 )
@@ -22,7 +24,7 @@ func filteredColumnsAndValues(str Struct, columnsIn []string, isUpdate bool) (co
 	columns = make([]string, 0, len(columnsSet))
 	values = make([]interface{}, 0, len(columns))
 
-	record, _ := str.(Record)
+	record, _ := AsRecord(str)
 	var pk uint
 	if record != nil {
 		pk = view.(Table).PKColumnIndex()
@@ -54,15 +56,130 @@ func filteredColumnsAndValues(str Struct, columnsIn []string, isUpdate bool) (co
 	return
 }
 
-func (q *Querier) insert(str Struct, columns []string, values []interface{}) error {
+// enumValue returns a string representation of enum value, or false for NULL.
+func enumValue(value interface{}) (string, bool) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.String {
+		return v.String(), true
+	}
+	return fmt.Sprint(v.Interface()), true
+}
+
+// checkEnums checks str's values if it implements EnumValidator.
+func checkEnums(str Struct) error {
+	ev, ok := str.(EnumValidator)
+	if !ok {
+		return nil
+	}
+
+	view := str.View()
+	enums := make(map[string][]string)
+	for c, allowed := range ev.Enums() {
+		enums[view.ToCol(c)] = allowed
+	}
+
+	values := str.Values()
+	for i, c := range view.Columns() {
+		allowed, ok := enums[c]
+		if !ok {
+			continue
+		}
+		delete(enums, c)
+
+		value, ok := enumValue(values[i])
+		if !ok {
+			continue
+		}
+		var found bool
+		for _, a := range allowed {
+			if a == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &InvalidEnumError{Column: c, Value: value}
+		}
+	}
+
+	// make error for extra columns
+	if len(enums) > 0 {
+		columns := make([]string, 0, len(enums))
+		for c := range enums {
+			columns = append(columns, c)
+		}
+		return fmt.Errorf("reform: unexpected enum columns: %v", columns)
+	}
+
+	return nil
+}
+
+// isIntegerPK returns true if record's primary key field has integer type.
+func isIntegerPK(record Record) bool {
+	switch reflect.TypeOf(record.PKPointer()).Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// insert inserts str with given columns and values.
+// If fillPK is false, record's primary key field is not filled.
+func (q *Querier) insert(str Struct, columns []string, values []interface{}, fillPK bool) error {
+	return q.insertReturning(str, columns, values, fillPK, nil)
+}
+
+// insertReturning is like insert, but also scans returning columns back to str's fields
+// for dialects with Returning, OutputInserted, ThenReturn or FinalTable LastInsertIdMethod if fillPK is true.
+func (q *Querier) insertReturning(str Struct, columns []string, values []interface{}, fillPK bool, returning []string) (err error) {
+	defer q.observe("insert", str.View(), time.Now(), &err)
+
+	if err := q.toDB(str.View(), columns, values); err != nil {
+		return err
+	}
+
+	// handle Default sentinels
+	var defaults []bool
+	for i, v := range values {
+		if v != Default {
+			continue
+		}
+		if defaults == nil {
+			defaults = make([]bool, len(values))
+		}
+		defaults[i] = true
+	}
+	if defaults != nil && q.ColumnDefaultMethod() == OmitColumn {
+		var c []string
+		var v []interface{}
+		for i := range columns {
+			if !defaults[i] {
+				c = append(c, columns[i])
+				v = append(v, values[i])
+			}
+		}
+		columns, values, defaults = c, v, nil
+	}
+
 	for i, c := range columns {
 		columns[i] = q.QuoteIdentifier(c)
 	}
-	placeholders := q.Placeholders(1, len(columns))
 
 	view := str.View()
-	record, _ := str.(Record)
+	record, _ := AsRecord(str)
 	lastInsertIdMethod := q.LastInsertIdMethod()
+	if !fillPK {
+		lastInsertIdMethod = NoLastInsertId
+	}
 	defaultValuesMethod := q.DefaultValuesMethod()
 
 	var pk uint
@@ -70,30 +187,93 @@ func (q *Querier) insert(str Struct, columns []string, values []interface{}) err
 		pk = view.(Table).PKColumnIndex()
 	}
 
+	// quoted columns returned by query, and scan destinations for them
+	var ret []string
+	var dest []interface{}
+	switch lastInsertIdMethod {
+	case Returning, OutputInserted, ThenReturn, FinalTable:
+		if record != nil {
+			ret = append(ret, q.QuoteIdentifier(view.Columns()[pk]))
+			dest = append(dest, record.PKPointer())
+		}
+		if len(returning) != 0 {
+			pointers := str.Pointers()
+			for _, r := range returning {
+				for i, c := range view.Columns() {
+					if c == r {
+						ret = append(ret, q.QuoteIdentifier(c))
+						dest = append(dest, pointers[i])
+						break
+					}
+				}
+			}
+		}
+	}
+
 	// make query
-	query := "INSERT INTO " + q.QualifiedView(view)
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString("INSERT INTO ")
+	q.writeQualifiedView(buf, view)
 	if len(columns) != 0 || defaultValuesMethod == EmptyLists {
-		query += " (" + strings.Join(columns, ", ") + ")"
+		buf.WriteString(" (")
+		writeJoined(buf, columns, ", ")
+		buf.WriteByte(')')
 	}
-	if record != nil && lastInsertIdMethod == OutputInserted {
-		query += fmt.Sprintf(" OUTPUT INSERTED.%s", q.QuoteIdentifier(view.Columns()[pk]))
+	if len(ret) != 0 && lastInsertIdMethod == OutputInserted {
+		buf.WriteString(" OUTPUT INSERTED.")
+		writeJoined(buf, ret, ", INSERTED.")
 	}
-	if len(placeholders) != 0 || defaultValuesMethod == EmptyLists {
-		query += fmt.Sprintf(" VALUES (%s)", strings.Join(placeholders, ", "))
+	if len(columns) != 0 || defaultValuesMethod == EmptyLists {
+		buf.WriteString(" VALUES (")
+		if defaults == nil {
+			q.writePlaceholders(buf, 1, len(columns))
+		} else {
+			var args []interface{}
+			for i, v := range values {
+				if i != 0 {
+					buf.WriteString(", ")
+				}
+				if defaults[i] {
+					buf.WriteString("DEFAULT")
+					continue
+				}
+				args = append(args, v)
+				buf.WriteString(q.Placeholder(len(args)))
+			}
+			values = args
+		}
+		buf.WriteByte(')')
 	} else {
-		query += " DEFAULT VALUES"
+		buf.WriteString(" DEFAULT VALUES")
+	}
+	if len(ret) != 0 && lastInsertIdMethod == Returning {
+		buf.WriteString(" RETURNING ")
+		writeJoined(buf, ret, ", ")
+	}
+	if len(ret) != 0 && lastInsertIdMethod == ThenReturn {
+		buf.WriteString(" THEN RETURN ")
+		writeJoined(buf, ret, ", ")
 	}
-	if record != nil && lastInsertIdMethod == Returning {
-		query += fmt.Sprintf(" RETURNING %s", q.QuoteIdentifier(view.Columns()[pk]))
+	query := buf.String()
+	if len(ret) != 0 && lastInsertIdMethod == FinalTable {
+		query = "SELECT " + strings.Join(ret, ", ") + " FROM FINAL TABLE (" + query + ")"
 	}
 
 	switch lastInsertIdMethod {
 	case LastInsertId:
-		res, err := q.Exec(os.Expand(query, view.ToCol), values...)
+		// LastInsertId returns only integers, other primary keys should be set explicitly
+		intPK := record != nil && isIntegerPK(record)
+		if record != nil && !intPK && !record.HasPK() {
+			return fmt.Errorf("reform: %s has %s primary key which can't be received with LastInsertId, set it explicitly",
+				view.Name(), reflect.TypeOf(record.PKPointer()).Elem())
+		}
+
+		res, err := q.Exec(Expand(view, query), values...)
 		if err != nil {
 			return err
 		}
-		if record != nil {
+		if intPK {
 			id, err := res.LastInsertId()
 			if err != nil {
 				return err
@@ -102,299 +282,1539 @@ func (q *Querier) insert(str Struct, columns []string, values []interface{}) err
 		}
 		return nil
 
-	case Returning, OutputInserted:
+	case Returning, OutputInserted, ThenReturn, FinalTable:
 		var err error
-		if record != nil {
-			err = q.QueryRow(query, values...).Scan(record.PKPointer())
+		if len(dest) != 0 {
+			err = q.QueryRow(query, values...).Scan(dest...)
 		} else {
-			_, err = q.Exec(os.Expand(query, view.ToCol), values...)
+			_, err = q.Exec(Expand(view, query), values...)
 		}
 		return err
 
+	case NoLastInsertId:
+		_, err := q.Exec(Expand(view, query), values...)
+		return err
+
 	default:
 		panic("reform: Unhandled LastInsertIdMethod. Please report this bug.")
 	}
 }
 
-func (q *Querier) beforeInsert(str Struct) error {
-	if bi, ok := str.(BeforeInserter); ok {
-		err := bi.BeforeInsert()
-		if err != nil {
+// callBeforeInsert calls BeforeInsertQ or BeforeInsert hook if str implements it.
+func (q *Querier) callBeforeInsert(str Struct) error {
+	switch bi := str.(type) {
+	case BeforeInserterQ:
+		return bi.BeforeInsertQ(q)
+	case BeforeInserter:
+		return bi.BeforeInsert()
+	}
+	return nil
+}
+
+// callAfterInsert calls AfterInsert hook if str implements it.
+func (q *Querier) callAfterInsert(str Struct) error {
+	if ai, ok := str.(AfterInserter); ok {
+		return ai.AfterInsert()
+	}
+	return nil
+}
+
+// callAfterInsertMulti calls AfterInsert hooks for structs inserted by bulk method, unless SkipBulkHooks is set.
+func (q *Querier) callAfterInsertMulti(structs []Struct) error {
+	if q.SkipBulkHooks {
+		return nil
+	}
+	for _, str := range structs {
+		if err := q.callAfterInsert(str); err != nil {
 			return err
 		}
 	}
+	return nil
+}
+
+// beforeBulk calls view's BeforeBulk hook if view implements BulkHooker, unless SkipBulkHooks is set.
+func (q *Querier) beforeBulk(view View, op string) error {
+	if bh, ok := view.(BulkHooker); ok && !q.SkipBulkHooks {
+		return bh.BeforeBulk(q, op)
+	}
+	return nil
+}
+
+// afterBulk calls view's AfterBulk hook if view implements BulkHooker, unless SkipBulkHooks is set.
+func (q *Querier) afterBulk(view View, op string, rows uint) error {
+	if bh, ok := view.(BulkHooker); ok && !q.SkipBulkHooks {
+		return bh.AfterBulk(q, op, rows)
+	}
+	return nil
+}
 
+// callBeforeUpdate calls BeforeUpdateQ or BeforeUpdate hook if str implements it.
+func (q *Querier) callBeforeUpdate(str Struct) error {
+	switch bu := str.(type) {
+	case BeforeUpdaterQ:
+		return bu.BeforeUpdateQ(q)
+	case BeforeUpdater:
+		return bu.BeforeUpdate()
+	}
 	return nil
 }
 
+func (q *Querier) beforeInsert(str Struct) error {
+	if err := q.callBeforeInsert(str); err != nil {
+		return err
+	}
+
+	return checkEnums(str)
+}
+
 // Insert inserts a struct into SQL database table.
-// If str implements BeforeInserter, it calls BeforeInsert() before doing so.
+// If str implements BeforeInserterQ or BeforeInserter, it calls BeforeInsertQ(q) or BeforeInsert() before doing so.
+// If str implements EnumValidator, it checks enum values before doing so.
+// If str implements AfterInserter, it calls AfterInsert() after successful insert.
 //
-// It fills record's primary key field.
+// It fills record's primary key field, unless dialect's LastInsertIdMethod is NoLastInsertId.
+//
+// Columns with "omitempty" label in "reform:" struct field tag are omitted from generated INSERT statement
+// when fields have zero values, so database defaults are used. Note that such fields are not filled
+// with those defaults (use Reload for that), and zero values can't be inserted explicitly (use InsertColumns for that).
+// The same applies to columns with Default values, see dialect's ColumnDefaultMethod.
 func (q *Querier) Insert(str Struct) error {
 	err := q.beforeInsert(str)
 	if err != nil {
 		return err
 	}
 
-	view := str.View()
-	values := str.Values()
-	columns := view.Columns()
-	record, _ := str.(Record)
-
-	if record != nil {
-		pk := view.(Table).PKColumnIndex()
+	if err = q.insertStruct(str); err != nil {
+		return err
+	}
+	return q.callAfterInsert(str)
+}
 
-		// cut primary key
-		if !record.HasPK() {
-			values = append(values[:pk], values[pk+1:]...)
-			columns = append(columns[:pk], columns[pk+1:]...)
-		}
+// InsertRaw inserts a struct into SQL database table like Insert, but without calling
+// BeforeInsertQ or BeforeInsert hooks. It can be used for data migrations and imports
+// which should preserve exact source values.
+// If str implements EnumValidator, it still checks enum values before doing so.
+//
+// Only user hooks are skipped: it fills record's primary key field the same way Insert does.
+func (q *Querier) InsertRaw(str Struct) error {
+	if err := checkEnums(str); err != nil {
+		return err
 	}
 
-	return q.insert(str, columns, values)
+	return q.insertStruct(str)
 }
 
-// InsertColumns inserts a struct into SQL database table with specified columns.
-// Other columns are omitted from generated INSERT statement.
-// If str implements BeforeInserter, it calls BeforeInsert() before doing so.
-//
-// It fills record's primary key field.
-func (q *Querier) InsertColumns(str Struct, columns ...string) error {
-	err := q.beforeInsert(str)
+// InsertInto is like Insert, but inserts a struct into table with given name instead of struct's view Name(),
+// like partition "orders_2025" for "orders" table. See WithTableName for details.
+func (q *Querier) InsertInto(str Struct, tableName string) error {
+	nq, err := q.WithTableName(str.View(), tableName)
 	if err != nil {
 		return err
 	}
+	return nq.Insert(str)
+}
 
-	columns, values, err := filteredColumnsAndValues(str, columns, false)
-	if err != nil {
+// InsertReturningColumns is like Insert, but also scans given columns (or fields) of inserted row back
+// to str's fields, so server-computed values (like generated slugs or sequence-based codes) can be read
+// without reloading the whole row. For dialects with RETURNING or OUTPUT clauses (see Dialect's SupportsReturning
+// and SupportsOutput) it is done by the same query. For other dialects str should be a Record:
+// columns are reloaded with a separate query by primary key after insert, which requires filled primary key.
+// Columns with Transformers or Location are not converted.
+func (q *Querier) InsertReturningColumns(str Struct, columns ...string) error {
+	view := str.View()
+	cols := make([]string, len(columns))
+	for i, c := range columns {
+		col, ok := view.HasCol(c)
+		if !ok {
+			return fmt.Errorf("reform: unexpected column %s for %s", c, view.Name())
+		}
+		cols[i] = col
+	}
+
+	if !q.SupportsReturning() && !q.SupportsOutput() {
+		record, ok := AsRecord(str)
+		if !ok {
+			return fmt.Errorf("reform: InsertReturningColumns requires Record for this dialect")
+		}
+		if err := q.Insert(record); err != nil {
+			return err
+		}
+		return q.reloadColumns(record, cols)
+	}
+
+	if err := q.beforeInsert(str); err != nil {
 		return err
 	}
 
-	return q.insert(str, columns, values)
+	values, p := getValues(str)
+	defer putValues(p)
+	insertColumns, values := insertColumnsAndValues(str, values)
+	if err := q.insertReturning(str, insertColumns, values, true, cols); err != nil {
+		return err
+	}
+	return q.callAfterInsert(str)
 }
 
-// InsertMulti inserts several structs into SQL database table with single query.
-// If they implement BeforeInserter, it calls BeforeInsert() before doing so.
-//
-// All structs should belong to the same view/table.
-// All records should either have or not have primary key set.
-// It doesn't fill primary key fields.
-// Given all these limitations, most users should use Querier.Insert in a loop, not this method.
-func (q *Querier) InsertMulti(structs ...Struct) error {
-	if len(structs) == 0 {
+// reloadColumns queries given columns of record's row by primary key and scans them to record's fields.
+func (q *Querier) reloadColumns(record Record, columns []string) error {
+	if len(columns) == 0 {
 		return nil
 	}
-
-	// check that view is the same
-	view := structs[0].View()
-	for _, str := range structs {
-		if str.View() != view {
-			return fmt.Errorf("reform: different tables in InsertMulti: %s and %s", view.Name(), str.View().Name())
-		}
+	if !record.HasPK() {
+		return ErrNoPK
 	}
 
-	var err error
-	for _, str := range structs {
-		if bi, ok := str.(BeforeInserter); ok {
-			e := bi.BeforeInsert()
-			if err == nil {
-				err = e
-			}
-		}
+	pks := []interface{}{record.PKValue()}
+	if cr, ok := record.(CompositeRecord); ok {
+		pks = cr.PKValues()
 	}
+	table := record.Table()
+	tail, args, err := q.pkTail(table, pks)
 	if err != nil {
 		return err
 	}
 
-	// check if all PK are present or all are absent
-	record, _ := structs[0].(Record)
-	if record != nil {
-		for _, str := range structs {
-			rec, _ := str.(Record)
-			if record.HasPK() != rec.HasPK() {
-				return fmt.Errorf("reform: PK in present in one struct and absent in other: first: %s, second: %s",
-					record, rec)
+	name := q.QuoteIdentifier(q.viewName(table))
+	pointers := record.Pointers()
+	sel := make([]string, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i, c := range columns {
+		sel[i] = name + "." + q.QuoteIdentifier(c)
+		for j, tc := range table.Columns() {
+			if tc == c {
+				dest[i] = pointers[j]
+				break
 			}
 		}
 	}
 
-	columns := view.Columns()
-	for i, c := range columns {
-		columns[i] = q.QuoteIdentifier(c)
+	query := "SELECT "
+	if q.SelectLimitMethod() == SelectTop {
+		query += "TOP 1 "
 	}
+	query += strings.Join(sel, ", ") + " FROM " + q.QualifiedView(table) + " " + tail
+	return q.QueryRow(query, args...).Scan(dest...)
+}
 
-	var pk uint
-	if record != nil && !record.HasPK() {
-		pk = view.(Table).PKColumnIndex()
-		columns = append(columns[:pk], columns[pk+1:]...)
-	}
+// insertStruct inserts all struct's columns, skipping primary key column if it is not set
+// and "omitempty" columns with zero values.
+func (q *Querier) insertStruct(str Struct) error {
+	values, p := getValues(str)
+	defer putValues(p)
 
-	placeholders := q.Placeholders(1, len(columns)*len(structs))
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES ",
-		q.QualifiedView(view),
-		strings.Join(columns, ", "),
-	)
-	for i := 0; i < len(structs); i++ {
-		query += fmt.Sprintf("(%s), ", strings.Join(placeholders[len(columns)*i:len(columns)*(i+1)], ", "))
+	columns, values := insertColumnsAndValues(str, values)
+	return q.insert(str, columns, values, true)
+}
+
+// insertColumnsAndValues returns struct's columns and given struct's values for INSERT, skipping primary key column
+// if it is not set and "omitempty" columns with zero values. values may be modified.
+func insertColumnsAndValues(str Struct, values []interface{}) ([]string, []interface{}) {
+	view := str.View()
+	columns := view.Columns()
+	record, _ := AsRecord(str)
+
+	if record != nil {
+		pk := view.(Table).PKColumnIndex()
+
+		// cut primary key
+		if !record.HasPK() {
+			values = append(values[:pk], values[pk+1:]...)
+			columns = append(columns[:pk], columns[pk+1:]...)
+		}
 	}
-	query = query[:len(query)-2] // cut last ", "
 
-	values := make([]interface{}, 0, len(placeholders))
-	for _, str := range structs {
-		v := str.Values()
-		if record != nil && !record.HasPK() {
-			v = append(v[:pk], v[pk+1:]...)
+	// cut empty "omitempty" columns
+	for i := 0; i < len(columns); {
+		if view.OmitEmpty(columns[i]) && isZero(values[i]) {
+			values = append(values[:i], values[i+1:]...)
+			columns = append(columns[:i], columns[i+1:]...)
+			continue
 		}
-		values = append(values, v...)
+		i++
 	}
 
-	_, err = q.Exec(os.Expand(query, view.ToCol), values...)
-	return err
+	return columns, values
 }
 
-func (q *Querier) update(record Record, columns []string, values []interface{}) error {
-	for i, c := range columns {
-		columns[i] = q.QuoteIdentifier(c)
+// isZero returns true if v is nil or zero value of its type.
+func isZero(v interface{}) bool {
+	if v == nil {
+		return true
 	}
-	placeholders := q.Placeholders(1, len(columns))
+	return reflect.DeepEqual(v, reflect.Zero(reflect.TypeOf(v)).Interface())
+}
 
-	p := make([]string, len(columns))
-	for i, c := range columns {
-		p[i] = c + " = " + placeholders[i]
+// InsertWithPK inserts a record into SQL database table with primary key column and value,
+// even if HasPK returns false. It can be used for seeding and data migrations.
+// If record implements BeforeInserterQ or BeforeInserter, it calls BeforeInsertQ(q) or BeforeInsert() before doing so.
+// If record implements EnumValidator, it checks enum values before doing so.
+//
+// It never fills record's primary key field, and does not update database sequences.
+// For dialects with OutputInserted LastInsertIdMethod (Microsoft SQL Server) it wraps INSERT
+// with "SET IDENTITY_INSERT" statements in a single transaction, so table should have IDENTITY column.
+func (q *Querier) InsertWithPK(record Record) error {
+	err := q.beforeInsert(record)
+	if err != nil {
+		return err
 	}
+
 	table := record.Table()
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = %s",
-		q.QualifiedView(table),
-		strings.Join(p, ", "),
-		q.QuoteIdentifier(table.Columns()[table.PKColumnIndex()]),
-		q.Placeholder(len(columns)+1),
-	)
+	columns := table.Columns()
+	values := record.Values()
 
-	args := append(values, record.PKValue())
-	res, err := q.Exec(os.Expand(query, table.ToCol), args...)
-	if err != nil {
-		return err
+	if q.LastInsertIdMethod() != OutputInserted {
+		return q.insert(record, columns, values, false)
 	}
-	ra, err := res.RowsAffected()
+
+	return q.inTransaction(func(q *Querier) (err error) {
+		identityInsert := "SET IDENTITY_INSERT " + q.QualifiedView(table)
+		if _, err = q.Exec(identityInsert + " ON"); err != nil {
+			return err
+		}
+
+		// it is a session setting which is not reverted by rollback, so turn it off even if insert failed
+		defer func() {
+			if _, e := q.Exec(identityInsert + " OFF"); err == nil {
+				err = e
+			}
+		}()
+
+		return q.insert(record, columns, values, false)
+	})
+}
+
+// Upsert inserts a struct into SQL database table, or updates existing row which conflicts with it
+// on conflictColumns (columns or fields of primary key or unique constraint).
+// Only updateColumns (columns or fields) are updated; if they are not given,
+// all inserted columns except conflict columns are updated.
+// If str implements BeforeInserterQ or BeforeInserter, it calls BeforeInsertQ(q) or BeforeInsert() before doing so.
+// If str implements EnumValidator, it checks enum values before doing so.
+//
+// Generated SQL depends on dialect's UpsertMethod. OnDuplicateKeyUpdate ignores conflictColumns
+// and uses all table's unique indexes. Merge requires all conflict columns to be inserted.
+// It never fills record's primary key field.
+func (q *Querier) Upsert(str Struct, conflictColumns []string, updateColumns ...string) error {
+	return q.UpsertWhere(str, conflictColumns, "", updateColumns...)
+}
+
+// UpsertWhere is like Upsert, but also adds conflictWhere predicate (like "deleted_at IS NULL") to conflict target,
+// so partial unique indexes can be used. Predicate is used as is, but "$Field" references are expanded.
+// It is supported only by dialects with OnConflict UpsertMethod; empty predicate is allowed for all dialects.
+func (q *Querier) UpsertWhere(str Struct, conflictColumns []string, conflictWhere string, updateColumns ...string) (err error) {
+	view := str.View()
+	defer q.observe("upsert", view, time.Now(), &err)
+
+	query, values, err := q.upsertQuery(str, conflictColumns, conflictWhere, updateColumns)
 	if err != nil {
 		return err
 	}
-	if ra == 0 {
-		return ErrNoRows
+	_, err = q.Exec(Expand(view, query), values...)
+	return err
+}
+
+// upsertQuery calls str's hooks and returns upsert query with dialect's UpsertMethod and its args.
+// See UpsertWhere for details.
+func (q *Querier) upsertQuery(str Struct, conflictColumns []string, conflictWhere string, updateColumns []string) (string, []interface{}, error) {
+	view := str.View()
+	method := q.UpsertMethod()
+	if method == NoUpsert {
+		return "", nil, fmt.Errorf("reform: Upsert is not supported by this dialect")
 	}
-	if ra > 1 {
-		panic(fmt.Sprintf("reform: %d rows by UPDATE by primary key. Please report this bug.", ra))
+	if len(conflictColumns) == 0 {
+		return "", nil, fmt.Errorf("reform: Upsert requires conflict columns")
+	}
+	if conflictWhere != "" && method != OnConflict {
+		return "", nil, fmt.Errorf("reform: conflict predicate is not supported by this dialect")
 	}
-	return nil
-}
 
-func (q *Querier) beforeUpdate(record Record) error {
-	if !record.HasPK() {
-		return ErrNoPK
+	if err := q.beforeInsert(str); err != nil {
+		return "", nil, err
 	}
 
-	if bu, ok := record.(BeforeUpdater); ok {
-		err := bu.BeforeUpdate()
-		if err != nil {
-			return err
+	values := str.Values()
+	columns := view.Columns()
+	if record, _ := AsRecord(str); record != nil && !record.HasPK() {
+		// cut primary key
+		pk := view.(Table).PKColumnIndex()
+		values = append(values[:pk], values[pk+1:]...)
+		columns = append(columns[:pk], columns[pk+1:]...)
+	}
+	if err := q.toDB(view, columns, values); err != nil {
+		return "", nil, err
+	}
+
+	inserted := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		inserted[c] = true
+	}
+
+	conflict := make([]string, len(conflictColumns))
+	isConflict := make(map[string]bool, len(conflictColumns))
+	for i, c := range conflictColumns {
+		col, ok := view.HasCol(c)
+		if !ok {
+			return "", nil, fmt.Errorf("reform: unexpected columns: [%s]", c)
 		}
+		if method == Merge && !inserted[col] {
+			return "", nil, fmt.Errorf("reform: conflict column %s is not inserted", col)
+		}
+		conflict[i] = col
+		isConflict[col] = true
 	}
 
-	return nil
-}
+	var update []string
+	if len(updateColumns) == 0 {
+		for _, c := range columns {
+			if !isConflict[c] {
+				update = append(update, c)
+			}
+		}
+	} else {
+		for _, c := range updateColumns {
+			col, ok := view.HasCol(c)
+			if !ok {
+				return "", nil, fmt.Errorf("reform: unexpected columns: [%s]", c)
+			}
+			update = append(update, col)
+		}
+	}
 
-// Update updates all columns of row specified by primary key in SQL database table with given record.
-// If record implements BeforeUpdater, it calls BeforeUpdate() before doing so.
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = q.QuoteIdentifier(c)
+	}
+	placeholders := strings.Join(q.Placeholders(1, len(columns)), ", ")
+
+	var query string
+	switch method {
+	case OnConflict:
+		for i, c := range conflict {
+			conflict[i] = q.QuoteIdentifier(c)
+		}
+		action := "DO NOTHING"
+		if len(update) != 0 {
+			set := make([]string, len(update))
+			for i, c := range update {
+				set[i] = fmt.Sprintf("%s = EXCLUDED.%s", q.QuoteIdentifier(c), q.QuoteIdentifier(c))
+			}
+			action = "DO UPDATE SET " + strings.Join(set, ", ")
+		}
+		target := "(" + strings.Join(conflict, ", ") + ")"
+		if conflictWhere != "" {
+			target += " WHERE " + conflictWhere
+		}
+		query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT %s %s",
+			q.QualifiedView(view), strings.Join(quoted, ", "), placeholders, target, action)
+
+	case OnDuplicateKeyUpdate:
+		if len(update) == 0 {
+			// no-op update to ignore conflict
+			update = conflict[:1]
+		}
+		set := make([]string, len(update))
+		for i, c := range update {
+			set[i] = fmt.Sprintf("%s = VALUES(%s)", q.QuoteIdentifier(c), q.QuoteIdentifier(c))
+		}
+		query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON DUPLICATE KEY UPDATE %s",
+			q.QualifiedView(view), strings.Join(quoted, ", "), placeholders, strings.Join(set, ", "))
+
+	case Merge:
+		on := make([]string, len(conflict))
+		for i, c := range conflict {
+			on[i] = fmt.Sprintf("target.%s = source.%s", q.QuoteIdentifier(c), q.QuoteIdentifier(c))
+		}
+		source := make([]string, len(quoted))
+		for i, c := range quoted {
+			source[i] = "source." + c
+		}
+		query = fmt.Sprintf("MERGE INTO %s AS target USING (VALUES (%s)) AS source (%s) ON %s",
+			q.QualifiedView(view), placeholders, strings.Join(quoted, ", "), strings.Join(on, " AND "))
+		if len(update) != 0 {
+			set := make([]string, len(update))
+			for i, c := range update {
+				set[i] = fmt.Sprintf("target.%s = source.%s", q.QuoteIdentifier(c), q.QuoteIdentifier(c))
+			}
+			query += " WHEN MATCHED THEN UPDATE SET " + strings.Join(set, ", ")
+		}
+		query += fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s);",
+			strings.Join(quoted, ", "), strings.Join(source, ", "))
+
+	default:
+		panic("reform: Unhandled UpsertMethod. Please report this bug.")
+	}
+
+	return query, values, nil
+}
+
+// UpsertAction is an action performed by UpsertResult.
+type UpsertAction int
+
+const (
+	// Inserted means that a new row was inserted.
+	Inserted UpsertAction = iota
+
+	// Updated means that existing row was updated.
+	Updated
+)
+
+// UpsertResult is like Upsert with default update columns and without predicate, but also reports whether
+// record was inserted or existing row was updated. Primary key field is filled on insert,
+// unless dialect's LastInsertIdMethod is NoLastInsertId.
+//
+// Detection depends on dialect:
+//   - OnConflict with Returning LastInsertIdMethod (PostgreSQL) uses "RETURNING (xmax = 0)",
+//     which relies on PostgreSQL's implementation details;
+//   - OnDuplicateKeyUpdate (MySQL) uses the number of affected rows: 1 for insert, 2 for update,
+//     0 for update which did not change the row (unless CLIENT_FOUND_ROWS flag is used, which makes it 1);
+//   - Merge (Microsoft SQL Server) uses "OUTPUT $action";
+//   - other dialects check existence of conflicting row and then run upsert in a single transaction:
+//     either a new one, or q itself if it is already a transaction. Concurrent inserts may make it inaccurate.
+//
+// If all inserted columns are conflict columns, there is nothing to update, and Updated is returned for existing row.
+func (q *Querier) UpsertResult(record Record, conflictColumns ...string) (action UpsertAction, err error) {
+	table := record.Table()
+	defer q.observe("upsert", table, time.Now(), &err)
+
+	query, values, err := q.upsertQuery(record, conflictColumns, "", nil)
+	if err != nil {
+		return
+	}
+	pk := q.QuoteIdentifier(table.Columns()[table.PKColumnIndex()])
+	fillPK := q.LastInsertIdMethod() != NoLastInsertId
+
+	switch q.UpsertMethod() {
+	case OnConflict:
+		if q.LastInsertIdMethod() != Returning {
+			break
+		}
+		var inserted bool
+		err = q.QueryRow(Expand(table, query+" RETURNING "+pk+", (xmax = 0)"), values...).Scan(record.PKPointer(), &inserted)
+		if err == ErrNoRows {
+			return Updated, nil
+		}
+		if err != nil || inserted {
+			return
+		}
+		return Updated, nil
+
+	case OnDuplicateKeyUpdate:
+		hasPK := record.HasPK()
+		var res sql.Result
+		if res, err = q.Exec(Expand(table, query), values...); err != nil {
+			return
+		}
+		var ra int64
+		if ra, err = res.RowsAffected(); err != nil || ra != 1 {
+			return Updated, err
+		}
+		if fillPK && !hasPK && isIntegerPK(record) {
+			var id int64
+			if id, err = res.LastInsertId(); err != nil {
+				return
+			}
+			record.SetPK(id)
+		}
+		return
+
+	case Merge:
+		// "$action" should not be expanded
+		query = strings.TrimSuffix(Expand(table, query), ";") + " OUTPUT $action, INSERTED." + pk + ";"
+		var a string
+		err = q.QueryRow(query, values...).Scan(&a, record.PKPointer())
+		if err == ErrNoRows {
+			return Updated, nil
+		}
+		if err != nil || a == "INSERT" {
+			return
+		}
+		return Updated, nil
+	}
+
+	// conflict columns are checked by upsertQuery, and values are set by hooks
+	columns, recordValues := table.Columns(), record.Values()
+	conds := make(map[string]interface{}, len(conflictColumns))
+	for _, c := range conflictColumns {
+		col, _ := table.HasCol(c)
+		for i, tc := range columns {
+			if tc == col {
+				conds[col] = recordValues[i]
+				break
+			}
+		}
+	}
+
+	err = q.inTransaction(func(q *Querier) error {
+		where, args, err := q.BuildWhere(table, 1, conds)
+		if err != nil {
+			return err
+		}
+		var one int
+		err = q.QueryRow(fmt.Sprintf("SELECT 1 FROM %s WHERE %s%s", q.QualifiedView(table), where, q.limit1Clause()), args...).Scan(&one)
+		switch err {
+		case nil:
+			action = Updated
+		case ErrNoRows:
+			action = Inserted
+		default:
+			return err
+		}
+
+		hasPK := record.HasPK()
+		res, err := q.Exec(Expand(table, query), values...)
+		if err != nil {
+			return err
+		}
+		if action == Inserted && fillPK && !hasPK && isIntegerPK(record) {
+			id, err := res.LastInsertId()
+			if err != nil {
+				return err
+			}
+			record.SetPK(id)
+		}
+		return nil
+	})
+	return
+}
+
+// InsertColumns inserts a struct into SQL database table with specified columns.
+// Other columns are omitted from generated INSERT statement.
+// If str implements BeforeInserterQ or BeforeInserter, it calls BeforeInsertQ(q) or BeforeInsert() before doing so.
+//
+// Like Insert, it omits primary key column if record's primary key is not set, even if it is specified,
+// so database generates it; set primary key field to insert it explicitly.
+// It fills record's primary key field, unless dialect's LastInsertIdMethod is NoLastInsertId.
+func (q *Querier) InsertColumns(str Struct, columns ...string) error {
+	err := q.beforeInsert(str)
+	if err != nil {
+		return err
+	}
+
+	columns, values, err := filteredColumnsAndValues(str, columns, false)
+	if err != nil {
+		return err
+	}
+
+	// cut primary key
+	if record, ok := AsRecord(str); ok && !record.HasPK() {
+		pk := str.View().Columns()[str.View().(Table).PKColumnIndex()]
+		for i, c := range columns {
+			if c == pk {
+				columns = append(columns[:i], columns[i+1:]...)
+				values = append(values[:i], values[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return q.insert(str, columns, values, true)
+}
+
+// insertMultiValues checks structs for InsertMulti and InsertMultiReturning, calls BeforeInsert(),
+// and returns columns and values to insert. All structs should belong to given view.
+func (q *Querier) insertMultiValues(view View, structs []Struct) (columns []string, values []interface{}, err error) {
+	// check that view is the same
+	for _, str := range structs {
+		if str.View() != view {
+			return nil, nil, fmt.Errorf("reform: different tables in InsertMulti: %s and %s", view.Name(), str.View().Name())
+		}
+	}
+
+	if !q.SkipBulkHooks {
+		for _, str := range structs {
+			if e := q.callBeforeInsert(str); e != nil && err == nil {
+				err = e
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, str := range structs {
+		if err = checkEnums(str); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// check if all PK are present or all are absent
+	record, _ := AsRecord(structs[0])
+	if record != nil {
+		for _, str := range structs {
+			rec := MustRecord(str)
+			if record.HasPK() != rec.HasPK() {
+				return nil, nil, fmt.Errorf("reform: PK in present in one struct and absent in other: first: %s, second: %s",
+					record, rec)
+			}
+		}
+	}
+
+	columns = view.Columns()
+	order, err := columnOrder(view)
+	if err != nil {
+		return nil, nil, err
+	}
+	cutPK := record != nil && !record.HasPK()
+	var pk uint
+	if cutPK {
+		pk = view.(Table).PKColumnIndex()
+		for i, index := range order {
+			if index == pk {
+				pk = uint(i)
+				break
+			}
+		}
+	}
+
+	values = make([]interface{}, 0, len(columns)*len(structs))
+	for _, str := range structs {
+		v := str.Values()
+		if err = q.toDB(view, columns, v); err != nil {
+			return nil, nil, err
+		}
+		if order != nil {
+			ordered := make([]interface{}, len(order))
+			for i, index := range order {
+				ordered[i] = v[index]
+			}
+			v = ordered
+		}
+		if cutPK {
+			v = append(v[:pk], v[pk+1:]...)
+		}
+		values = append(values, v...)
+	}
+
+	if order != nil {
+		ordered := make([]string, len(order))
+		for i, index := range order {
+			ordered[i] = columns[index]
+		}
+		columns = ordered
+	}
+	if cutPK {
+		columns = append(columns[:pk], columns[pk+1:]...)
+	}
+	return columns, values, nil
+}
+
+// columnOrder returns indexes of view's columns in the order of ColumnOrder if view implements ColumnOrderer,
+// or nil otherwise. It returns error if ColumnOrder is not a permutation of view's columns.
+func columnOrder(view View) ([]uint, error) {
+	o, ok := view.(ColumnOrderer)
+	if !ok {
+		return nil, nil
+	}
+
+	columns := view.Columns()
+	order := o.ColumnOrder()
+	if len(order) != len(columns) {
+		return nil, fmt.Errorf("reform: ColumnOrder of %s has %d columns, expected %d", view.Name(), len(order), len(columns))
+	}
+
+	indexes := make([]uint, len(order))
+	seen := make(map[string]bool, len(order))
+	for i, c := range order {
+		if seen[c] {
+			return nil, fmt.Errorf("reform: duplicate column %s in ColumnOrder of %s", c, view.Name())
+		}
+		seen[c] = true
+
+		index := -1
+		for j, col := range columns {
+			if col == c {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("reform: unexpected column %s in ColumnOrder of %s", c, view.Name())
+		}
+		indexes[i] = uint(index)
+	}
+	return indexes, nil
+}
+
+// insertMultiQuery returns INSERT query for n rows of given view and columns.
+// If returning is true, it also returns all view's columns with dialect's LastInsertIdMethod.
+func (q *Querier) insertMultiQuery(view View, columns []string, n int, returning bool) string {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = q.QuoteIdentifier(c)
+	}
+
+	var returned []string
+	if returning {
+		returned = view.Columns()
+		for i, c := range returned {
+			returned[i] = q.QuoteIdentifier(c)
+		}
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s)",
+		q.QualifiedView(view),
+		strings.Join(quoted, ", "),
+	)
+	if returning && q.LastInsertIdMethod() == OutputInserted {
+		query += " OUTPUT INSERTED." + strings.Join(returned, ", INSERTED.")
+	}
+
+	placeholders := q.Placeholders(1, len(columns)*n)
+	rows := make([]string, n)
+	for i := 0; i < n; i++ {
+		rows[i] = "(" + strings.Join(placeholders[len(columns)*i:len(columns)*(i+1)], ", ") + ")"
+	}
+	query += " VALUES " + strings.Join(rows, ", ")
+
+	if returning && q.LastInsertIdMethod() == Returning {
+		query += " RETURNING " + strings.Join(returned, ", ")
+	}
+	if returning && q.LastInsertIdMethod() == ThenReturn {
+		query += " THEN RETURN " + strings.Join(returned, ", ")
+	}
+	return query
+}
+
+// insertMultiChunk returns the maximum number of rows of n with given number of columns
+// which can be inserted with a single statement, as limited by MaxInsertMultiRows and dialect's MaxPlaceholders.
+func (q *Querier) insertMultiChunk(n, columns int) int {
+	chunk := n
+	if max := q.MaxInsertMultiRows; max > 0 && chunk > max {
+		chunk = max
+	}
+	return q.placeholdersChunk(chunk, columns)
+}
+
+// placeholdersChunk returns the maximum number of rows of n with given number of columns
+// which can be used in a single statement, as limited by dialect's MaxPlaceholders.
+// It is at least 1, even if a single row exceeds MaxPlaceholders; database then returns an error.
+func (q *Querier) placeholdersChunk(n, columns int) int {
+	chunk := n
+	if max := q.MaxPlaceholders(); max > 0 && columns > 0 && chunk*columns > max {
+		chunk = max / columns
+		if chunk < 1 {
+			chunk = 1
+		}
+	}
+	return chunk
+}
+
+// InsertMulti inserts several structs into SQL database table with single query.
+// If they implement BeforeInserterQ or BeforeInserter, it calls BeforeInsertQ(q) or BeforeInsert() before doing so,
+// and if they implement AfterInserter, it calls AfterInsert() after that. If view implements BulkHooker,
+// its hooks are called too. See SkipBulkHooks for opting out.
+// If the number of structs exceeds MaxInsertMultiRows or dialect's MaxPlaceholders limits,
+// they are inserted in chunks with several sequential queries inside a single transaction.
+//
+// All structs should belong to the same view/table.
+// All records should either have or not have primary key set.
+// It doesn't fill primary key fields.
+// Given all these limitations, most users should use Querier.Insert in a loop, not this method.
+func (q *Querier) InsertMulti(structs ...Struct) (err error) {
+	if len(structs) == 0 {
+		return nil
+	}
+
+	view := structs[0].View()
+	defer q.observe("insert", view, time.Now(), &err)
+
+	if err = q.beforeBulk(view, "insert"); err != nil {
+		return err
+	}
+	columns, values, err := q.insertMultiValues(view, structs)
+	if err != nil {
+		return err
+	}
+
+	chunk := q.insertMultiChunk(len(structs), len(columns))
+	if chunk == len(structs) {
+		query := q.insertMultiQuery(view, columns, len(structs), false)
+		_, err = q.Exec(Expand(view, query), values...)
+	} else {
+		err = q.inTransaction(func(q *Querier) error {
+			for start := 0; start < len(structs); start += chunk {
+				end := start + chunk
+				if end > len(structs) {
+					end = len(structs)
+				}
+
+				query := q.insertMultiQuery(view, columns, end-start, false)
+				args := values[start*len(columns) : end*len(columns)]
+				if _, err := q.Exec(Expand(view, query), args...); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = q.callAfterInsertMulti(structs); err != nil {
+		return err
+	}
+	return q.afterBulk(view, "insert", uint(len(structs)))
+}
+
+// insertMultiIgnoreQuery returns a query for InsertMultiIfNotExists for n rows with dialect's UpsertMethod.
+func (q *Querier) insertMultiIgnoreQuery(view View, columns []string, conflict []string, n int) string {
+	switch q.UpsertMethod() {
+	case OnConflict:
+		query := q.insertMultiQuery(view, columns, n, false) + " ON CONFLICT "
+		if len(conflict) != 0 {
+			quoted := make([]string, len(conflict))
+			for i, c := range conflict {
+				quoted[i] = q.QuoteIdentifier(c)
+			}
+			query += "(" + strings.Join(quoted, ", ") + ") "
+		}
+		return query + "DO NOTHING"
+
+	case OnDuplicateKeyUpdate:
+		return "INSERT IGNORE" + strings.TrimPrefix(q.insertMultiQuery(view, columns, n, false), "INSERT")
+
+	case Merge:
+		quoted := make([]string, len(columns))
+		source := make([]string, len(columns))
+		for i, c := range columns {
+			quoted[i] = q.QuoteIdentifier(c)
+			source[i] = "source." + quoted[i]
+		}
+		on := make([]string, len(conflict))
+		for i, c := range conflict {
+			on[i] = fmt.Sprintf("target.%s = source.%s", q.QuoteIdentifier(c), q.QuoteIdentifier(c))
+		}
+		placeholders := q.Placeholders(1, len(columns)*n)
+		rows := make([]string, n)
+		for i := 0; i < n; i++ {
+			rows[i] = "(" + strings.Join(placeholders[len(columns)*i:len(columns)*(i+1)], ", ") + ")"
+		}
+		return fmt.Sprintf("MERGE INTO %s AS target USING (VALUES %s) AS source (%s) ON %s "+
+			"WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s);",
+			q.QualifiedView(view), strings.Join(rows, ", "), strings.Join(quoted, ", "), strings.Join(on, " AND "),
+			strings.Join(quoted, ", "), strings.Join(source, ", "))
+
+	default:
+		panic("reform: Unhandled UpsertMethod. Please report this bug.")
+	}
+}
+
+// InsertMultiIfNotExists is like InsertMulti, but skips rows which conflict with existing ones
+// on conflictColumns (or fields) unique constraint, and returns a number of actually inserted rows.
+// It is useful for idempotent bulk loads.
+//
+// Dialects with OnConflict UpsertMethod use "ON CONFLICT (...) DO NOTHING" (conflictColumns may be empty
+// to skip rows conflicting with any constraint), dialects with OnDuplicateKeyUpdate use "INSERT IGNORE"
+// (conflictColumns are ignored, and note that other errors become warnings), dialects with Merge use
+// "MERGE ... WHEN NOT MATCHED" (conflictColumns are required and should be inserted).
+// It returns error for other dialects.
+func (q *Querier) InsertMultiIfNotExists(conflictColumns []string, structs ...Struct) (inserted uint, err error) {
+	if len(structs) == 0 {
+		return 0, nil
+	}
+
+	view := structs[0].View()
+	defer q.observe("insert", view, time.Now(), &err)
+
+	method := q.UpsertMethod()
+	if method == NoUpsert {
+		return 0, fmt.Errorf("reform: InsertMultiIfNotExists is not supported by this dialect")
+	}
+	if len(conflictColumns) == 0 && method == Merge {
+		return 0, fmt.Errorf("reform: InsertMultiIfNotExists requires conflict columns for this dialect")
+	}
+
+	columns, values, err := q.insertMultiValues(view, structs)
+	if err != nil {
+		return 0, err
+	}
+
+	isInserted := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		isInserted[c] = true
+	}
+	conflict := make([]string, len(conflictColumns))
+	for i, c := range conflictColumns {
+		col, ok := view.HasCol(c)
+		if !ok {
+			return 0, fmt.Errorf("reform: unexpected columns: [%s]", c)
+		}
+		if method == Merge && !isInserted[col] {
+			return 0, fmt.Errorf("reform: conflict column %s is not inserted", col)
+		}
+		conflict[i] = col
+	}
+
+	insertChunk := func(q *Querier, n int, args []interface{}) error {
+		query := q.insertMultiIgnoreQuery(view, columns, conflict, n)
+		res, err := q.Exec(Expand(view, query), args...)
+		if err != nil {
+			return err
+		}
+		ra, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		inserted += uint(ra)
+		return nil
+	}
+
+	chunk := q.insertMultiChunk(len(structs), len(columns))
+	if chunk == len(structs) {
+		err = insertChunk(q, len(structs), values)
+		return
+	}
+
+	err = q.inTransaction(func(q *Querier) error {
+		for start := 0; start < len(structs); start += chunk {
+			end := start + chunk
+			if end > len(structs) {
+				end = len(structs)
+			}
+			if err := insertChunk(q, end-start, values[start*len(columns):end*len(columns)]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		inserted = 0
+	}
+	return
+}
+
+// InsertMultiReturning is like InsertMulti, but also scans inserted rows (including generated primary keys)
+// back to given structs in the same order, and returns them.
+// If structs implement AfterFinder, it also calls AfterFind(), and then AfterInsert() for AfterInserter.
+// Structs are inserted in chunks limited by MaxInsertMultiRows and dialect's MaxPlaceholders inside a single transaction.
+//
+// It is supported only by dialects with Returning, OutputInserted or ThenReturn LastInsertIdMethod.
+func (q *Querier) InsertMultiReturning(structs ...Struct) (_ []Struct, err error) {
+	if m := q.LastInsertIdMethod(); m != Returning && m != OutputInserted && m != ThenReturn {
+		return nil, fmt.Errorf("reform: InsertMultiReturning is not supported by this dialect")
+	}
+	if len(structs) == 0 {
+		return structs, nil
+	}
+
+	view := structs[0].View()
+	defer q.observe("insert", view, time.Now(), &err)
+
+	columns, values, err := q.insertMultiValues(view, structs)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := q.insertMultiChunk(len(structs), len(columns))
+	err = q.inTransaction(func(q *Querier) error {
+		for start := 0; start < len(structs); start += chunk {
+			end := start + chunk
+			if end > len(structs) {
+				end = len(structs)
+			}
+
+			query := q.insertMultiQuery(view, columns, end-start, true)
+			args := values[start*len(columns) : end*len(columns)]
+			if err := q.scanReturning(structs[start:end], Expand(view, query), args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return structs, q.callAfterInsertMulti(structs)
+}
+
+// scanReturning runs query with args and scans result rows to given structs in order.
+func (q *Querier) scanReturning(structs []Struct, query string, args ...interface{}) (err error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		e := rows.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+
+	for i, str := range structs {
+		err = q.NextRow(str, rows)
+		if err == ErrNoRows {
+			return fmt.Errorf("reform: expected %d returned rows, got %d", len(structs), i)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InsertSelect inserts rows selected from srcView with tail and args into dst view or table
+// and returns a number of inserted rows. Given columns (or fields) should be present in both views.
+// Tail is expanded with srcView.
+func (q *Querier) InsertSelect(dst View, columns []string, srcView View, tail string, args ...interface{}) (_ uint, err error) {
+	defer q.observe("insert", dst, time.Now(), &err)
+
+	dstColumns := make([]string, len(columns))
+	srcColumns := make([]string, len(columns))
+	src := q.QualifiedView(srcView)
+	for i, c := range columns {
+		dc, ok := dst.HasCol(c)
+		if !ok {
+			return 0, fmt.Errorf("reform: unexpected column %s for %s", c, dst.Name())
+		}
+		sc, ok := srcView.HasCol(c)
+		if !ok {
+			return 0, fmt.Errorf("reform: unexpected column %s for %s", c, srcView.Name())
+		}
+		dstColumns[i] = q.QuoteIdentifier(dc)
+		srcColumns[i] = src + "." + q.QuoteIdentifier(sc)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s %s",
+		q.QualifiedView(dst),
+		strings.Join(dstColumns, ", "),
+		strings.Join(srcColumns, ", "),
+		src,
+		tail,
+	)
+
+	res, err := q.Exec(Expand(srcView, query), args...)
+	if err != nil {
+		return 0, err
+	}
+	ra, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return uint(ra), nil
+}
+
+// update updates record's row and returns a number of affected rows.
+// It panics if more than one row was affected, unless PanicOnMultiAffected is false.
+func (q *Querier) update(record Record, columns []string, values []interface{}) (int64, error) {
+	return q.updateWhere(record, columns, values, nil, nil)
+}
+
+// updateWhere is like update, but also adds conditions for condColumns and condValues to primary key one.
+// nil values produce "IS NULL" conditions.
+func (q *Querier) updateWhere(record Record, columns []string, values []interface{}, condColumns []string, condValues []interface{}) (_ int64, err error) {
+	defer q.observe("update", record.Table(), time.Now(), &err)
+
+	if err := q.toDB(record.Table(), columns, values); err != nil {
+		return 0, err
+	}
+	if err := q.toDB(record.Table(), condColumns, condValues); err != nil {
+		return 0, err
+	}
+
+	table := record.Table()
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString("UPDATE ")
+	q.writeQualifiedView(buf, table)
+	buf.WriteString(" SET ")
+	for i, c := range columns {
+		columns[i] = q.QuoteIdentifier(c)
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(columns[i])
+		buf.WriteString(" = ")
+		buf.WriteString(q.Placeholder(i + 1))
+	}
+	buf.WriteString(" WHERE ")
+	buf.WriteString(q.QuoteIdentifier(table.Columns()[table.PKColumnIndex()]))
+	buf.WriteString(" = ")
+	buf.WriteString(q.Placeholder(len(columns) + 1))
+	args := append(values, record.PKValue())
+	for i, c := range condColumns {
+		buf.WriteString(" AND ")
+		buf.WriteString(q.QuoteIdentifier(c))
+		if isNil(condValues[i]) {
+			buf.WriteString(" IS NULL")
+			continue
+		}
+		buf.WriteString(" = ")
+		args = append(args, condValues[i])
+		buf.WriteString(q.Placeholder(len(args)))
+	}
+	query := buf.String()
+
+	res, err := q.Exec(Expand(table, query), args...)
+	if err != nil {
+		return 0, err
+	}
+	ra, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if ra > 1 {
+		return ra, q.multiAffected("UPDATE", ra)
+	}
+	return ra, nil
+}
+
+func (q *Querier) beforeUpdate(record Record) error {
+	if !record.HasPK() {
+		return ErrNoPK
+	}
+
+	if err := q.callBeforeUpdate(record); err != nil {
+		return err
+	}
+
+	return checkEnums(record)
+}
+
+// Update updates all columns of row specified by primary key in SQL database table with given record.
+// If record implements BeforeUpdaterQ or BeforeUpdater, it calls BeforeUpdateQ(q) or BeforeUpdate() before doing so.
+// If record implements EnumValidator, it checks enum values before doing so.
 //
 // Method returns ErrNoRows if no rows were updated.
 // Method returns ErrNoPK if primary key is not set.
 func (q *Querier) Update(record Record) error {
+	ra, err := q.UpdateWithResult(record)
+	if err != nil {
+		return err
+	}
+	if ra == 0 {
+		return ErrNoRows
+	}
+	return nil
+}
+
+// UpdateWithResult updates all columns of row specified by primary key in SQL database table with given record
+// and returns a number of affected rows as reported by database.
+// If record implements BeforeUpdaterQ or BeforeUpdater, it calls BeforeUpdateQ(q) or BeforeUpdate() before doing so.
+//
+// Unlike Update, it does not convert zero affected rows to ErrNoRows.
+// Note that some databases (like MySQL) report zero affected rows when row was found,
+// but its values were not changed.
+// Method returns ErrNoPK if primary key is not set.
+func (q *Querier) UpdateWithResult(record Record) (int64, error) {
+	err := q.beforeUpdate(record)
+	if err != nil {
+		return 0, err
+	}
+
+	return q.updateRecord(record)
+}
+
+// UpdateRaw updates all columns of row specified by primary key in SQL database table with given record
+// like Update, but without calling BeforeUpdateQ or BeforeUpdate hooks. It can be used for data migrations
+// and imports which should preserve exact source values.
+// If record implements EnumValidator, it still checks enum values before doing so.
+//
+// Method returns ErrNoRows if no rows were updated.
+// Method returns ErrNoPK if primary key is not set.
+func (q *Querier) UpdateRaw(record Record) error {
+	if !record.HasPK() {
+		return ErrNoPK
+	}
+	if err := checkEnums(record); err != nil {
+		return err
+	}
+
+	ra, err := q.updateRecord(record)
+	if err != nil {
+		return err
+	}
+	if ra == 0 {
+		return ErrNoRows
+	}
+	return nil
+}
+
+// updateRecord updates all record's columns except primary key and returns a number of affected rows.
+func (q *Querier) updateRecord(record Record) (int64, error) {
+	table := record.Table()
+	values := record.Values()
+	columns := table.Columns()
+
+	// cut primary key
+	pk := table.PKColumnIndex()
+	values = append(values[:pk], values[pk+1:]...)
+	columns = append(columns[:pk], columns[pk+1:]...)
+
+	return q.update(record, columns, values)
+}
+
+func (q *Querier) DsUpdateStruct(str Struct, ds *goqu.Dataset) (uint, error) {
+	query, args, err := q.dsUpdateStructSQL(str, ds)
+	if err != nil {
+		return 0, err
+	}
+
+	return q.dsExec("update", str.View(), query, args...)
+}
+
+// dsUpdateStructSQL returns UPDATE query and args for DsUpdateStruct.
+func (q *Querier) dsUpdateStructSQL(str Struct, ds *goqu.Dataset) (string, []interface{}, error) {
+	if err := q.callBeforeUpdate(str); err != nil {
+		return "", nil, err
+	}
+
+	if err := checkEnums(str); err != nil {
+		return "", nil, err
+	}
+
+	values := str.Values()
+	columns := str.View().Columns()
+
+	var pk int
+	record, ok := str.(Table)
+	if ok {
+		pk = int(record.PKColumnIndex())
+	}
+
+	if ok {
+		values = append(values[:pk], values[pk+1:]...)
+		columns = append(columns[:pk], columns[pk+1:]...)
+	}
+
+	updates := make(map[string]interface{}, len(columns))
+	for i := 0; i < len(columns); i++ {
+		updates[columns[i]] = values[i]
+	}
+
+	return q.dsFrom(ds, str.View()).ToUpdateSql(updates)
+}
+
+// UpdateIf updates specified columns of row specified by primary key in SQL database table with given record,
+// but only if row's columns (or fields) also have given values (compare-and-set).
+// nil values (including nil pointers) match NULL. Conditions are added in table's columns order.
+// If record implements BeforeUpdaterQ or BeforeUpdater, it calls BeforeUpdateQ(q) or BeforeUpdate() before doing so.
+//
+// It returns false without error if no rows were updated, because conditions were not met
+// or because row does not exist.
+// Method returns ErrNoPK if primary key is not set.
+func (q *Querier) UpdateIf(record Record, conditions map[string]interface{}, columns ...string) (bool, error) {
+	table := record.Table()
+	condSet := make(map[string]interface{}, len(conditions))
+	for c, v := range conditions {
+		col, ok := table.HasCol(c)
+		if !ok {
+			return false, fmt.Errorf("reform: unexpected columns: [%s]", c)
+		}
+		condSet[col] = v
+	}
+
+	// keep columns order
+	condColumns := make([]string, 0, len(condSet))
+	condValues := make([]interface{}, 0, len(condSet))
+	for _, c := range table.Columns() {
+		if v, ok := condSet[c]; ok {
+			condColumns = append(condColumns, c)
+			condValues = append(condValues, v)
+		}
+	}
+
+	if err := q.beforeUpdate(record); err != nil {
+		return false, err
+	}
+
+	columns, values, err := filteredColumnsAndValues(record, columns, true)
+	if err != nil {
+		return false, err
+	}
+	if len(values) == 0 {
+		return false, ErrNothingToUpdate
+	}
+
+	ra, err := q.updateWhere(record, columns, values, condColumns, condValues)
+	if err != nil {
+		return false, err
+	}
+	return ra > 0, nil
+}
+
+// UpdateColumns updates specified columns of row specified by primary key in SQL database table with given record.
+// Other columns are omitted from generated UPDATE statement.
+// If record implements BeforeUpdaterQ or BeforeUpdater, it calls BeforeUpdateQ(q) or BeforeUpdate() before doing so.
+//
+// Method returns ErrNoRows if no rows were updated.
+// Method returns ErrNoPK if primary key is not set.
+func (q *Querier) UpdateColumns(record Record, columns ...string) error {
+	ra, err := q.UpdateColumnsWithResult(record, columns...)
+	if err != nil {
+		return err
+	}
+	if ra == 0 {
+		return ErrNoRows
+	}
+	return nil
+}
+
+// UpdateColumnsWithResult updates specified columns of row specified by primary key in SQL database table
+// with given record and returns a number of affected rows as reported by database.
+// See UpdateColumns and UpdateWithResult for details.
+func (q *Querier) UpdateColumnsWithResult(record Record, columns ...string) (int64, error) {
 	err := q.beforeUpdate(record)
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	columns, values, err := filteredColumnsAndValues(record, columns, true)
+	if err != nil {
+		return 0, err
 	}
 
-	table := record.Table()
-	values := record.Values()
-	columns := table.Columns()
-
-	// cut primary key
-	pk := table.PKColumnIndex()
-	values = append(values[:pk], values[pk+1:]...)
-	columns = append(columns[:pk], columns[pk+1:]...)
+	if len(values) == 0 {
+		return 0, ErrNothingToUpdate
+	}
 
 	return q.update(record, columns, values)
 }
 
-func (q *Querier) DsUpdateStruct(str Struct, ds *goqu.Dataset) (uint, error) {
-	if bu, ok := str.(BeforeUpdater); ok {
-		err := bu.BeforeUpdate()
-		if err != nil {
-			return 0, err
+// snapshotValues returns a copy of values with pointers replaced by pointers to copies of their targets,
+// so later modifications of record do not change it.
+func snapshotValues(values []interface{}) []interface{} {
+	res := make([]interface{}, len(values))
+	for i, v := range values {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			c := reflect.New(rv.Elem().Type())
+			c.Elem().Set(rv.Elem())
+			v = c.Interface()
 		}
+		res[i] = v
 	}
+	return res
+}
 
-	values := str.Values()
-	columns := str.View().Columns()
+// takeSnapshot stores a copy of record's values if it implements Snapshotter.
+func takeSnapshot(record Record) {
+	if s, ok := record.(Snapshotter); ok {
+		s.SetSnapshot(snapshotValues(record.Values()))
+	}
+}
 
-	var pk int
-	record, ok := str.(Table)
-	if ok {
-		pk = int(record.PKColumnIndex())
+// valuesEqual returns true if both values are equal. Pointers are compared by their targets,
+// typed nil pointers are equal to each other and to untyped nil, time.Time values are compared with Equal.
+func valuesEqual(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for va.Kind() == reflect.Ptr && !va.IsNil() {
+		va = va.Elem()
+	}
+	for vb.Kind() == reflect.Ptr && !vb.IsNil() {
+		vb = vb.Elem()
 	}
 
-	if ok {
-		values = append(values[:pk], values[pk+1:]...)
-		columns = append(columns[:pk], columns[pk+1:]...)
+	aNil := !va.IsValid() || (va.Kind() == reflect.Ptr && va.IsNil())
+	bNil := !vb.IsValid() || (vb.Kind() == reflect.Ptr && vb.IsNil())
+	if aNil || bNil {
+		return aNil == bNil
 	}
 
-	updates := make(map[string]interface{}, len(columns))
-	for i := 0; i < len(columns); i++ {
-		updates[columns[i]] = values[i]
+	if ta, ok := va.Interface().(time.Time); ok {
+		tb, ok := vb.Interface().(time.Time)
+		return ok && ta.Equal(tb)
+	}
+	return reflect.DeepEqual(va.Interface(), vb.Interface())
+}
+
+// Diff returns names of fields which values are different in loaded and current structs of the same view.
+// Pointer fields are compared by their targets, time.Time fields are compared with Equal,
+// other fields are compared with reflect.DeepEqual.
+// It panics if structs belong to different views.
+func Diff(loaded, current Struct) []string {
+	view := current.View()
+	if loaded.View() != view {
+		panic(fmt.Sprintf("reform: Diff of %s and %s structs", loaded.View().Name(), view.Name()))
 	}
 
-	query, args, err := ds.From(str.View().Name()).ToUpdateSql(updates)
-	if err != nil {
-		return 0, err
+	var res []string
+	fields := view.Fields()
+	for _, i := range diffIndexes(loaded.Values(), current.Values()) {
+		res = append(res, fields[i])
 	}
+	return res
+}
 
-	return q.DsExec(str.View(), query, args...)
+// diffIndexes returns indexes of different values.
+func diffIndexes(loaded, current []interface{}) []int {
+	var res []int
+	for i, v := range current {
+		if !valuesEqual(loaded[i], v) {
+			res = append(res, i)
+		}
+	}
+	return res
 }
 
-// UpdateColumns updates specified columns of row specified by primary key in SQL database table with given record.
-// Other columns are omitted from generated UPDATE statement.
-// If record implements BeforeUpdater, it calls BeforeUpdate() before doing so.
+// UpdateChanged updates columns of row specified by primary key in SQL database table
+// which were changed since record was loaded by FindByPrimaryKeyTo. Values are compared like Diff does.
+// If record does not implement Snapshotter or has no snapshot, it behaves like Update.
+// If record implements BeforeUpdaterQ or BeforeUpdater, it calls BeforeUpdateQ(q) or BeforeUpdate() before
+// comparing values, so changes made there are updated too.
+// On success, snapshot is updated.
 //
+// Method returns ErrNothingToUpdate if nothing was changed.
 // Method returns ErrNoRows if no rows were updated.
 // Method returns ErrNoPK if primary key is not set.
-func (q *Querier) UpdateColumns(record Record, columns ...string) error {
-	err := q.beforeUpdate(record)
-	if err != nil {
+func (q *Querier) UpdateChanged(record Record) error {
+	s, ok := record.(Snapshotter)
+	if !ok || s.Snapshot() == nil {
+		err := q.Update(record)
+		if err == nil {
+			takeSnapshot(record)
+		}
 		return err
 	}
 
-	columns, values, err := filteredColumnsAndValues(record, columns, true)
-	if err != nil {
+	if err := q.beforeUpdate(record); err != nil {
 		return err
 	}
 
-	if len(values) == 0 {
-		// TODO make exported type for that error
-		return fmt.Errorf("reform: nothing to update")
+	table := record.Table()
+	pk := int(table.PKColumnIndex())
+	current := record.Values()
+	var columns []string
+	var values []interface{}
+	for _, i := range diffIndexes(s.Snapshot(), current) {
+		if i != pk {
+			columns = append(columns, table.Columns()[i])
+			values = append(values, current[i])
+		}
+	}
+	if len(columns) == 0 {
+		return ErrNothingToUpdate
 	}
 
-	return q.update(record, columns, values)
+	ra, err := q.update(record, columns, values)
+	if err != nil {
+		return err
+	}
+	if ra == 0 {
+		return ErrNoRows
+	}
+	takeSnapshot(record)
+	return nil
 }
 
 func (q *Querier) DsUpdateColumns(str Struct, ds *goqu.Dataset, columns ...string) (uint, error) {
+	query, args, err := q.dsUpdateColumnsSQL(str, ds, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	return q.dsExec("update", str.View(), query, args...)
+}
+
+// dsUpdateColumnsSQL returns UPDATE query and args for DsUpdateColumns.
+func (q *Querier) dsUpdateColumnsSQL(str Struct, ds *goqu.Dataset, columns []string) (string, []interface{}, error) {
 	var err error
 
-	if bu, ok := str.(BeforeUpdater); ok {
-		err = bu.BeforeUpdate()
-		if err != nil {
-			return 0, err
-		}
+	if err = q.callBeforeUpdate(str); err != nil {
+		return "", nil, err
+	}
+
+	if err = checkEnums(str); err != nil {
+		return "", nil, err
 	}
 
 	var values []interface{}
@@ -402,12 +1822,11 @@ func (q *Querier) DsUpdateColumns(str Struct, ds *goqu.Dataset, columns ...strin
 
 	cols, values, err = filteredColumnsAndValues(str, columns, true)
 	if err != nil {
-		return 0, err
+		return "", nil, err
 	}
 
 	if len(values) == 0 {
-		// TODO make exported type for that error
-		return 0, fmt.Errorf("reform: nothing to update")
+		return "", nil, ErrNothingToUpdate
 	}
 
 	updates := make(map[string]interface{}, len(cols))
@@ -415,12 +1834,196 @@ func (q *Querier) DsUpdateColumns(str Struct, ds *goqu.Dataset, columns ...strin
 		updates[cols[i]] = values[i]
 	}
 
-	query, args, err := ds.From(str.View().Name()).ToUpdateSql(updates)
+	return q.dsFrom(ds, str.View()).ToUpdateSql(updates)
+}
+
+// UpdateAll updates rows in view with tail and args by setting given columns (or fields) to given values
+// and returns a number of updated rows. Placeholders in tail start with 1, as for DeleteFrom.
+//
+// Method never returns ErrNoRows.
+func (q *Querier) UpdateAll(view View, set map[string]interface{}, tail string, args ...interface{}) (_ uint, err error) {
+	defer q.observe("update", view, time.Now(), &err)
+
+	if len(set) == 0 {
+		return 0, ErrNothingToUpdate
+	}
+
+	setCols := make(map[string]interface{}, len(set))
+	for c, v := range set {
+		col, ok := view.HasCol(c)
+		if !ok {
+			return 0, fmt.Errorf("reform: unexpected columns: [%s]", c)
+		}
+		setCols[col] = v
+	}
+
+	// keep columns order
+	columns := make([]string, 0, len(setCols))
+	values := make([]interface{}, 0, len(setCols))
+	for _, c := range view.Columns() {
+		if v, ok := setCols[c]; ok {
+			columns = append(columns, c)
+			values = append(values, v)
+		}
+	}
+	if err := q.toDB(view, columns, values); err != nil {
+		return 0, err
+	}
+
+	// placeholders for values go after tail's ones if they are numbered, before otherwise
+	var placeholders []string
+	if q.numberedPlaceholders() {
+		placeholders = q.Placeholders(len(args)+1, len(columns))
+		args = append(append(make([]interface{}, 0, len(args)+len(values)), args...), values...)
+	} else {
+		placeholders = q.Placeholders(1, len(columns))
+		args = append(values, args...)
+	}
+
+	p := make([]string, len(columns))
+	for i, c := range columns {
+		p[i] = q.QuoteIdentifier(c) + " = " + placeholders[i]
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s %s",
+		q.QualifiedView(view),
+		strings.Join(p, ", "),
+		tail,
+	)
+
+	if err = q.beforeBulk(view, "update"); err != nil {
+		return 0, err
+	}
+	res, err := q.Exec(Expand(view, query), args...)
 	if err != nil {
 		return 0, err
 	}
+	ra, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return uint(ra), q.afterBulk(view, "update", uint(ra))
+}
+
+// UpdateMultiFromValues updates all columns of rows specified by primary keys with given records
+// using "UPDATE ... FROM (VALUES ...)" statement, which is much more efficient than separate updates
+// for thousands of rows with distinct values. It returns a total number of updated rows.
+// If records implement BeforeUpdaterQ or BeforeUpdater, it calls BeforeUpdateQ(q) or BeforeUpdate() before doing so.
+// If records implement EnumValidator, it checks enum values before doing so.
+// If the number of records exceeds dialect's MaxPlaceholders limit, they are updated in chunks
+// with several sequential statements inside a single transaction.
+//
+// It is supported only by dialects with SupportsUpdateFromValues (PostgreSQL).
+// All records should belong to the same table and have primary key set, otherwise ErrNoPK is returned.
+// Method never returns ErrNoRows.
+func (q *Querier) UpdateMultiFromValues(records ...Record) (_ uint, err error) {
+	if !q.SupportsUpdateFromValues() {
+		return 0, fmt.Errorf("reform: UpdateMultiFromValues is not supported by this dialect")
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	table := records[0].Table()
+	defer q.observe("update", table, time.Now(), &err)
+
+	columns := table.Columns()
+	values := make([]interface{}, 0, len(records)*len(columns))
+	for _, record := range records {
+		if record.Table() != table {
+			return 0, fmt.Errorf("reform: UpdateMultiFromValues requires records of the same table")
+		}
+		if err = q.beforeUpdate(record); err != nil {
+			return 0, err
+		}
+		v := record.Values()
+		if err = q.toDB(table, columns, v); err != nil {
+			return 0, err
+		}
+		values = append(values, v...)
+	}
+
+	chunk := q.placeholdersChunk(len(records), len(columns))
+	if chunk == len(records) {
+		return q.updateFromValues(table, columns, values)
+	}
+
+	var total uint
+	err = q.inTransaction(func(q *Querier) error {
+		for start := 0; start < len(records); start += chunk {
+			end := start + chunk
+			if end > len(records) {
+				end = len(records)
+			}
+
+			ra, err := q.updateFromValues(table, columns, values[start*len(columns):end*len(columns)])
+			if err != nil {
+				return err
+			}
+			total += ra
+		}
+		return nil
+	})
+	return total, err
+}
+
+// updateFromValues runs "UPDATE ... FROM (VALUES ...)" statement for values of all table's columns
+// and returns a number of updated rows.
+func (q *Querier) updateFromValues(table Table, columns []string, values []interface{}) (uint, error) {
+	pk := q.QuoteIdentifier(columns[table.PKColumnIndex()])
+	name := q.QualifiedView(table)
+	t := q.QuoteIdentifier("t")
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString("UPDATE ")
+	buf.WriteString(name)
+	buf.WriteString(" SET ")
+	var n int
+	for i, c := range columns {
+		if i == int(table.PKColumnIndex()) {
+			continue
+		}
+		if n != 0 {
+			buf.WriteString(", ")
+		}
+		n++
+		c = q.QuoteIdentifier(c)
+		buf.WriteString(c + " = " + t + "." + c)
+	}
+
+	// the first row contains typed NULLs, so placeholders get types of table's columns
+	// instead of text, and it matches no rows
+	buf.WriteString(" FROM (VALUES (")
+	for i, c := range columns {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(buf, "(SELECT %s FROM %s WHERE FALSE)", q.QuoteIdentifier(c), name)
+	}
+	buf.WriteString(")")
+	for start := 0; start < len(values); start += len(columns) {
+		buf.WriteString(", (")
+		buf.WriteString(strings.Join(q.Placeholders(start+1, len(columns)), ", "))
+		buf.WriteString(")")
+	}
+	buf.WriteString(") AS " + t + " (")
+	for i, c := range columns {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(q.QuoteIdentifier(c))
+	}
+	fmt.Fprintf(buf, ") WHERE %s.%s = %s.%s", name, pk, t, pk)
 
-	return q.DsExec(str.View(), query, args...)
+	res, err := q.Exec(buf.String(), values...)
+	if err != nil {
+		return 0, err
+	}
+	ra, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return uint(ra), nil
 }
 
 func (q *Querier) DsUpdate(str Struct, ds *goqu.Dataset, columns ...string) (uint, error) {
@@ -430,6 +2033,29 @@ func (q *Querier) DsUpdate(str Struct, ds *goqu.Dataset, columns ...string) (uin
 	return q.DsUpdateStruct(str, ds)
 }
 
+// DsUpdateReturning is like DsUpdate, but adds Returning clause with all view's columns to ds
+// and returns updated rows as a slice of new Structs, so computed columns can be read atomically.
+// It is supported only by dialects with RETURNING syntax.
+//
+// Method never returns ErrNoRows.
+func (q *Querier) DsUpdateReturning(str Struct, ds *goqu.Dataset, columns ...string) ([]Struct, error) {
+	ds = ds.Returning(getDsView(str.View()).columns...)
+
+	var query string
+	var args []interface{}
+	var err error
+	if len(columns) > 0 {
+		query, args, err = q.dsUpdateColumnsSQL(str, ds, columns)
+	} else {
+		query, args, err = q.dsUpdateStructSQL(str, ds)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return q.DsExecReturning(str.View(), query, args...)
+}
+
 // Save saves record in SQL database table.
 // If primary key is set, it first calls Update and checks if row was updated.
 // If primary key is absent or no row was updated, it calls Insert.
@@ -444,24 +2070,83 @@ func (q *Querier) Save(record Record) error {
 	return q.Insert(record)
 }
 
+// SaveMulti saves several records in SQL database tables inside a single transaction:
+// either a new one, or q itself if it is already a transaction. On error, the whole transaction is rolled back
+// (if it was started by SaveMulti), and the first error is returned.
+//
+// Records with primary key set are saved with Save. Other records are inserted with InsertMultiReturning
+// for dialects with Returning, OutputInserted or ThenReturn LastInsertIdMethod (all of them should belong
+// to the same table in that case), or with Insert otherwise. In both cases primary key fields are filled,
+// unless dialect's LastInsertIdMethod is NoLastInsertId.
+func (q *Querier) SaveMulti(records ...Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	return q.inTransaction(func(q *Querier) error {
+		var inserts []Struct
+		for _, record := range records {
+			if !record.HasPK() {
+				inserts = append(inserts, record)
+				continue
+			}
+			if err := q.Save(record); err != nil {
+				return err
+			}
+		}
+		if len(inserts) == 0 {
+			return nil
+		}
+
+		switch q.LastInsertIdMethod() {
+		case Returning, OutputInserted, ThenReturn:
+			_, err := q.InsertMultiReturning(inserts...)
+			return err
+		default:
+			for _, str := range inserts {
+				if err := q.Insert(str); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	})
+}
+
+// FindOrCreate queries record's table with column and arg and scans first result to record.
+// If there are no rows in result, it inserts record with Insert.
+// It makes sense to call it inside a transaction to avoid races with concurrent inserts.
+func (q *Querier) FindOrCreate(record Record, column string, arg interface{}) error {
+	err := q.FindOneTo(record, column, arg)
+	if err == ErrNoRows {
+		return q.Insert(record)
+	}
+	return err
+}
+
 // Delete deletes record from SQL database table by primary key.
 //
 // Method returns ErrNoRows if no rows were deleted.
 // Method returns ErrNoPK if primary key is not set.
-func (q *Querier) Delete(record Record) error {
+func (q *Querier) Delete(record Record) (err error) {
+	table := record.Table()
+	defer q.observe("delete", table, time.Now(), &err)
+
 	if !record.HasPK() {
 		return ErrNoPK
 	}
 
-	table := record.Table()
-	pk := table.PKColumnIndex()
-	query := fmt.Sprintf("DELETE FROM %s WHERE %s = %s",
-		q.QualifiedView(table),
-		q.QuoteIdentifier(table.Columns()[pk]),
-		q.Placeholder(1),
-	)
-
-	res, err := q.Exec(os.Expand(query, table.ToCol), record.PKValue())
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString("DELETE FROM ")
+	q.writeQualifiedView(buf, table)
+	buf.WriteString(" WHERE ")
+	buf.WriteString(q.QuoteIdentifier(table.Columns()[table.PKColumnIndex()]))
+	buf.WriteString(" = ")
+	buf.WriteString(q.Placeholder(1))
+	query := buf.String()
+
+	res, err := q.Exec(Expand(table, query), record.PKValue())
 	if err != nil {
 		return err
 	}
@@ -473,21 +2158,86 @@ func (q *Querier) Delete(record Record) error {
 		return ErrNoRows
 	}
 	if ra > 1 {
-		panic(fmt.Sprintf("reform: %d rows by DELETE by primary key. Please report this bug.", ra))
+		return q.multiAffected("DELETE", ra)
 	}
 	return nil
 }
 
+// DeleteReturningRecord deletes record from SQL database table by primary key
+// and scans deleted row back to record, so its fields reflect the last persisted state.
+// If record implements AfterFinder, it also calls AfterFind().
+//
+// For dialects with Returning, OutputInserted or ThenReturn LastInsertIdMethod it uses a single query.
+// For other dialects it reloads record and then deletes it in a single transaction:
+// either a new one, or q itself if it is already a transaction.
+//
+// Method returns ErrNoRows if no rows were deleted.
+// Method returns ErrNoPK if primary key is not set.
+func (q *Querier) DeleteReturningRecord(record Record) (err error) {
+	if !record.HasPK() {
+		return ErrNoPK
+	}
+
+	method := q.LastInsertIdMethod()
+	switch method {
+	case Returning, OutputInserted, ThenReturn:
+		// handled below
+	default:
+		return q.inTransaction(func(q *Querier) error {
+			if err := q.Reload(record); err != nil {
+				return err
+			}
+			return q.Delete(record)
+		})
+	}
+
+	table := record.Table()
+	defer q.observe("delete", table, time.Now(), &err)
+
+	returned := table.Columns()
+	for i, c := range returned {
+		returned[i] = q.QuoteIdentifier(c)
+	}
+
+	query := "DELETE FROM " + q.QualifiedView(table)
+	if method == OutputInserted {
+		query += " OUTPUT DELETED." + strings.Join(returned, ", DELETED.")
+	}
+	query += fmt.Sprintf(" WHERE %s = %s",
+		q.QuoteIdentifier(table.Columns()[table.PKColumnIndex()]),
+		q.Placeholder(1),
+	)
+	switch method {
+	case Returning:
+		query += " RETURNING " + strings.Join(returned, ", ")
+	case ThenReturn:
+		query += " THEN RETURN " + strings.Join(returned, ", ")
+	}
+
+	if err = q.QueryRow(Expand(table, query), record.PKValue()).Scan(record.Pointers()...); err != nil {
+		return err
+	}
+	if err = q.fromDB(record); err != nil {
+		return err
+	}
+	return q.callAfterFind(record)
+}
+
 // DeleteFrom deletes rows from view with tail and args and returns a number of deleted rows.
 //
 // Method never returns ErrNoRows.
-func (q *Querier) DeleteFrom(view View, tail string, args ...interface{}) (uint, error) {
+func (q *Querier) DeleteFrom(view View, tail string, args ...interface{}) (_ uint, err error) {
+	defer q.observe("delete", view, time.Now(), &err)
+
 	query := fmt.Sprintf("DELETE FROM %s %s",
 		q.QualifiedView(view),
 		tail,
 	)
 
-	res, err := q.Exec(os.Expand(query, view.ToCol), args...)
+	if err = q.beforeBulk(view, "delete"); err != nil {
+		return 0, err
+	}
+	res, err := q.Exec(Expand(view, query), args...)
 	if err != nil {
 		return 0, err
 	}
@@ -495,19 +2245,76 @@ func (q *Querier) DeleteFrom(view View, tail string, args ...interface{}) (uint,
 	if err != nil {
 		return 0, err
 	}
-	return uint(ra), nil
+	return uint(ra), q.afterBulk(view, "delete", uint(ra))
+}
+
+// DeleteByPKs deletes rows from table by primary keys and returns a number of deleted rows.
+// Primary keys are split into chunks by dialect's MaxPlaceholders, all chunks are deleted in a single transaction.
+//
+// Method never returns ErrNoRows. It returns 0, nil if no primary keys are given.
+func (q *Querier) DeleteByPKs(table Table, pks ...interface{}) (uint, error) {
+	if len(pks) == 0 {
+		return 0, nil
+	}
+
+	var res uint
+	err := q.inTransaction(func(q *Querier) error {
+		res = 0
+		max := q.MaxPlaceholders()
+		for rest := pks; len(rest) > 0; {
+			n := len(rest)
+			if max > 0 && n > max {
+				n = max
+			}
+
+			tail := fmt.Sprintf("WHERE %s IN (%s)",
+				q.QuoteIdentifier(table.PK()),
+				strings.Join(q.Placeholders(1, n), ", "),
+			)
+			ra, err := q.DeleteFrom(table, tail, rest[:n]...)
+			if err != nil {
+				return err
+			}
+			res += ra
+			rest = rest[n:]
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res, nil
+}
+
+// DeleteFromNamed deletes rows from view with tail with ":name" markers and args
+// and returns a number of deleted rows. See NamedTail for details about markers.
+//
+// Method never returns ErrNoRows.
+func (q *Querier) DeleteFromNamed(view View, tail string, args map[string]interface{}) (uint, error) {
+	tail, a, err := q.NamedTail(tail, args)
+	if err != nil {
+		return 0, err
+	}
+	return q.DeleteFrom(view, tail, a...)
 }
 
 func (q *Querier) DsDelete(view View, ds *goqu.Dataset) (uint, error) {
-	query, args, err := ds.From(view.Name()).ToDeleteSql()
+	query, args, err := q.dsFrom(ds, view).ToDeleteSql()
 	if err != nil {
 		return 0, err
 	}
-	return q.DsExec(view, query, args...)
+	return q.dsExec("delete", view, query, args...)
 }
 
 func (q *Querier) DsExec(view View, query string, args ...interface{}) (uint, error) {
-	res, err := q.Exec(os.Expand(query, view.ToCol), args...)
+	return q.dsExec("exec", view, query, args...)
+}
+
+// dsExec implements DsExec and Ds* commands, observing them as given operation.
+func (q *Querier) dsExec(op string, view View, query string, args ...interface{}) (_ uint, err error) {
+	defer q.observe(op, view, time.Now(), &err)
+
+	res, err := q.Exec(Expand(view, query), args...)
 	if err != nil {
 		return 0, err
 	}
@@ -517,3 +2324,12 @@ func (q *Querier) DsExec(view View, query string, args ...interface{}) (uint, er
 	}
 	return uint(ra), nil
 }
+
+// DsExecReturning executes query built by goqu with Returning clause (for example, from
+// ds.Returning(view.IColumns()...).ToInsertSql(...)) and returns returned rows as a slice of new Structs.
+// If view's Struct implements AfterFinder, it also calls AfterFind().
+//
+// Method never returns ErrNoRows.
+func (q *Querier) DsExecReturning(view View, query string, args ...interface{}) ([]Struct, error) {
+	return q.queryAllFrom(view, query, args...)
+}
@@ -1,14 +1,28 @@
 package reform_test
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
 	"time"
 
 	"github.com/AlekSi/pointer"
 	"github.com/enodata/faker"
+	"gopkg.in/doug-martin/goqu.v3"
+	_ "gopkg.in/doug-martin/goqu.v3/adapters/postgres"
 
 	"github.com/empirefox/reform"
+	"github.com/empirefox/reform/dialects/mssql"
+	"github.com/empirefox/reform/dialects/mysql"
 	"github.com/empirefox/reform/dialects/postgresql"
+	"github.com/empirefox/reform/dialects/redshift"
+	"github.com/empirefox/reform/dialects/spanner"
 	. "github.com/empirefox/reform/internal/test/models"
 )
 
@@ -127,6 +141,29 @@ func (s *ReformSuite) TestInsertColumns() {
 	s.Error(err)
 }
 
+func (s *ReformSuite) TestInsertColumnsPK() {
+	// primary key is not set, so it is omitted
+	person := &Person{Name: "Without PK"}
+	err := s.q.InsertColumns(person, "id", "name", "created_at")
+	s.NoError(err)
+	s.NotEqual(int32(0), person.ID)
+
+	person2, err := s.q.FindByPrimaryKeyFrom(PersonTable, person.ID)
+	s.NoError(err)
+	s.Equal("Without PK", person2.(*Person).Name)
+
+	// primary key is set, so it is kept
+	setIdentityInsert(s.T(), s.q, "people", true)
+	person = &Person{ID: 51, Name: "With PK"}
+	err = s.q.InsertColumns(person, "id", "name", "created_at")
+	s.NoError(err)
+	s.Equal(int32(51), person.ID)
+
+	person2, err = s.q.FindByPrimaryKeyFrom(PersonTable, 51)
+	s.NoError(err)
+	s.Equal("With PK", person2.(*Person).Name)
+}
+
 func (s *ReformSuite) TestInsertColumnsIntoView() {
 	pp := &PersonProject{PersonID: 1, ProjectID: "baron"}
 	err := s.q.InsertColumns(pp, "person_id", "project_id")
@@ -142,6 +179,449 @@ func (s *ReformSuite) TestInsertColumnsIntoView() {
 	s.Error(err)
 }
 
+func (s *ReformSuite) TestInsertStringPK() {
+	fake := new(fakeDB)
+	err := reform.NewDBFromInterface(fake, mysql.Dialect, nil).Insert(&Project{Name: "No ID"})
+	s.EqualError(err, "reform: projects has string primary key which can't be received with LastInsertId, set it explicitly")
+	s.Empty(fake.queries)
+
+	project := &Project{ID: "string_pk", Name: "String PK", Start: time.Now()}
+	err = s.q.Insert(project)
+	s.NoError(err)
+	s.Equal("string_pk", project.ID)
+
+	project2, err := s.q.FindByPrimaryKeyFrom(ProjectTable, "string_pk")
+	s.NoError(err)
+	s.Equal("String PK", project2.(*Project).Name)
+}
+
+// setTX is a fakeTX which executes "SET" statements successfully.
+type setTX struct {
+	fakeTX
+}
+
+func (t setTX) Exec(query string, args ...interface{}) (sql.Result, error) {
+	res, err := t.fakeTX.Exec(query, args...)
+	if strings.HasPrefix(query, "SET ") {
+		return driver.RowsAffected(0), nil
+	}
+	return res, err
+}
+
+func (s *ReformSuite) TestInsertWithPK() {
+	fake := new(fakeDB)
+	tx := reform.NewTXFromInterface(fakeTX{fake}, mssql.Dialect, nil)
+	err := tx.InsertWithPK(&Person{ID: 1, Name: "Alice"})
+	s.Equal(errFake, err)
+	s.Equal([]string{"SET IDENTITY_INSERT [people] ON"}, fake.queries)
+
+	// IDENTITY_INSERT is turned off after failed insert
+	fake = new(fakeDB)
+	tx = reform.NewTXFromInterface(setTX{fakeTX{fake}}, mssql.Dialect, nil)
+	err = tx.InsertWithPK(&Person{ID: 1, Name: "Alice"})
+	s.Equal(errFake, err)
+	s.Require().Len(fake.queries, 3)
+	s.Equal("SET IDENTITY_INSERT [people] ON", fake.queries[0])
+	s.True(strings.HasPrefix(fake.queries[1], "INSERT INTO [people] "), "%s", fake.queries[1])
+	s.Equal("SET IDENTITY_INSERT [people] OFF", fake.queries[2])
+
+	// seed people with explicit primary keys
+	seed := []*Person{
+		{ID: 1001, Name: faker.Name().Name()},
+		{ID: 1002, Name: faker.Name().Name(), Email: pointer.ToString(faker.Internet().Email())},
+	}
+	for _, person := range seed {
+		err = s.q.InsertWithPK(person)
+		s.Require().NoError(err)
+	}
+
+	for _, person := range seed {
+		person2, err := s.q.FindByPrimaryKeyFrom(PersonTable, person.ID)
+		s.NoError(err)
+		s.Equal(person.Name, person2.(*Person).Name)
+		s.Equal(person.Email, person2.(*Person).Email)
+	}
+
+	err = s.q.InsertWithPK(seed[0])
+	s.Error(err)
+}
+
+func (s *ReformSuite) TestInsertReturningColumns() {
+	for dialect, expected := range map[reform.Dialect]string{
+		postgresql.Dialect: `INSERT INTO "people" ("name", "created_at") VALUES ($1, $2) RETURNING "id", "group_id"`,
+		mssql.Dialect:      `INSERT INTO [people] ([name], [created_at]) OUTPUT INSERTED.[id], INSERTED.[group_id] VALUES (?, ?)`,
+	} {
+		fake := new(fakeDB)
+		db := reform.NewDBFromInterface(fake, dialect, nil)
+		s.Panics(func() { _ = db.InsertReturningColumns(&DefaultedPerson{Name: "Alice"}, "GroupID") })
+		s.Equal([]string{expected}, fake.queries)
+	}
+
+	person := &DefaultedPerson{Name: faker.Name().Name(), CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	s.EqualError(s.q.InsertReturningColumns(person, "foo"), "reform: unexpected column foo for people")
+
+	err := s.q.InsertReturningColumns(person, "GroupID")
+	s.Require().NoError(err)
+	s.NotEqual(int32(0), person.ID)
+	s.Equal(int32(65534), person.GroupID)
+
+	person2, err := s.q.FindByPrimaryKeyFrom(DefaultedPersonTable, person.ID)
+	s.NoError(err)
+	s.Equal(person, person2)
+}
+
+func (s *ReformSuite) TestInsertOmitEmpty() {
+	person := &DefaultedPerson{Name: faker.Name().Name(), CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	fake := new(fakeDB)
+	err := reform.NewDBFromInterface(fake, mysql.Dialect, nil).Insert(person)
+	s.Equal(errFake, err)
+	s.Equal([]string{"INSERT INTO `people` (`name`, `created_at`) VALUES (?, ?)"}, fake.queries)
+
+	err = s.q.Insert(person)
+	s.Require().NoError(err)
+	s.Equal(int32(0), person.GroupID) // not filled
+	s.NoError(s.q.Reload(person))
+	s.Equal(int32(65534), person.GroupID)
+
+	person = &DefaultedPerson{GroupID: 42, Name: faker.Name().Name(), CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	err = s.q.Insert(person)
+	s.Require().NoError(err)
+	s.NoError(s.q.Reload(person))
+	s.Equal(int32(42), person.GroupID)
+
+	// explicit zero value
+	person = &DefaultedPerson{Name: faker.Name().Name(), CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	err = s.q.InsertColumns(person, "GroupID", "Name", "CreatedAt")
+	s.Require().NoError(err)
+	s.NoError(s.q.Reload(person))
+	s.Equal(int32(0), person.GroupID)
+}
+
+func (s *ReformSuite) TestInsertDefault() {
+	// NULL group_id means database default
+	transformers := map[reform.View]map[string]reform.ColumnTransformer{
+		PersonTable: {
+			"group_id": {
+				ToDB: func(value interface{}) (interface{}, error) {
+					if value.(*int32) == nil {
+						return reform.Default, nil
+					}
+					return value, nil
+				},
+			},
+		},
+	}
+
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, mysql.Dialect, nil)
+	db.Transformers = transformers
+	err := db.Insert(&Person{Name: "Default", CreatedAt: time.Now()})
+	s.Equal(errFake, err)
+	s.Equal([]string{
+		"INSERT INTO `people` (`group_id`, `name`, `email`, `created_at`, `updated_at`) VALUES (DEFAULT, ?, ?, ?, ?)",
+	}, fake.queries)
+
+	// placeholders are renumbered
+	fake = new(fakeDB)
+	db = reform.NewDBFromInterface(fake, redshift.Dialect, nil)
+	db.Transformers = transformers
+	err = db.Insert(&Person{Name: "Default", CreatedAt: time.Now()})
+	s.Equal(errFake, err)
+	s.Equal([]string{
+		`INSERT INTO "people" ("group_id", "name", "email", "created_at", "updated_at") VALUES (DEFAULT, $1, $2, $3, $4)`,
+	}, fake.queries)
+
+	s.q.Transformers = transformers
+	person := &Person{Name: faker.Name().Name(), CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	err = s.q.Insert(person)
+	s.Require().NoError(err)
+	s.NoError(s.q.Reload(person))
+	s.Equal(pointer.ToInt32(65534), person.GroupID)
+
+	person = &Person{GroupID: pointer.ToInt32(42), Name: faker.Name().Name(), CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	err = s.q.Insert(person)
+	s.Require().NoError(err)
+	s.NoError(s.q.Reload(person))
+	s.Equal(pointer.ToInt32(42), person.GroupID)
+}
+
+func (s *ReformSuite) TestNullRoundTrip() {
+	person := &NullPerson{Name: faker.Name().Name(), CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	for i, v := range person.Values() {
+		s.True(v != nil, "%d: untyped nil", i)
+	}
+	err := s.q.Insert(person)
+	s.Require().NoError(err)
+	s.NoError(s.q.Reload(person))
+	s.Equal(sql.NullInt64{}, person.GroupID)
+	s.Equal(sql.NullString{}, person.Email)
+	s.Nil(person.UpdatedAt)
+
+	updatedAt := person.CreatedAt.Add(time.Hour)
+	person.GroupID = sql.NullInt64{Int64: 42, Valid: true}
+	person.Email = sql.NullString{String: faker.Internet().Email(), Valid: true}
+	person.UpdatedAt = &updatedAt
+	err = s.q.Update(person)
+	s.Require().NoError(err)
+	person2 := &NullPerson{ID: person.ID}
+	s.NoError(s.q.Reload(person2))
+	s.Equal(person, person2)
+
+	person.GroupID = sql.NullInt64{}
+	person.Email = sql.NullString{}
+	person.UpdatedAt = nil
+	err = s.q.Update(person)
+	s.Require().NoError(err)
+	s.NoError(s.q.Reload(person2))
+	s.Equal(person, person2)
+}
+
+func (s *ReformSuite) TestInsertRawUpdateRaw() {
+	createdAt := time.Date(2014, 12, 31, 23, 59, 59, 123456789, time.UTC)
+	updatedAt := createdAt.Add(time.Hour)
+	person := &Person{Name: faker.Name().Name(), CreatedAt: createdAt, UpdatedAt: &updatedAt}
+	err := s.q.InsertRaw(person)
+	s.Require().NoError(err)
+	s.NotEqual(int32(0), person.ID)
+	s.Equal(createdAt, person.CreatedAt)
+	s.Equal(updatedAt, *person.UpdatedAt)
+
+	updatedAt = updatedAt.Truncate(time.Second)
+	person.UpdatedAt = &updatedAt
+	err = s.q.UpdateRaw(person)
+	s.NoError(err)
+	s.Equal(updatedAt, *person.UpdatedAt)
+
+	person2, err := s.q.FindByPrimaryKeyFrom(PersonTable, person.ID)
+	s.NoError(err)
+	s.Equal(updatedAt, *person2.(*Person).UpdatedAt)
+
+	s.Equal(reform.ErrNoPK, s.q.UpdateRaw(&Person{}))
+	s.Equal(reform.ErrNoRows, s.q.UpdateRaw(&Person{ID: -1}))
+}
+
+func (s *ReformSuite) TestUpsert() {
+	for dialect, expected := range map[reform.Dialect]string{
+		postgresql.Dialect: `INSERT INTO "person_project" ("person_id", "project_id") VALUES ($1, $2) ` +
+			`ON CONFLICT ("person_id", "project_id") DO NOTHING`,
+		mysql.Dialect: "INSERT INTO `person_project` (`person_id`, `project_id`) VALUES (?, ?) " +
+			"ON DUPLICATE KEY UPDATE `person_id` = VALUES(`person_id`)",
+		mssql.Dialect: `MERGE INTO [person_project] AS target USING (VALUES (?, ?)) AS source ([person_id], [project_id]) ` +
+			`ON target.[person_id] = source.[person_id] AND target.[project_id] = source.[project_id] ` +
+			`WHEN NOT MATCHED THEN INSERT ([person_id], [project_id]) VALUES (source.[person_id], source.[project_id]);`,
+	} {
+		fake := new(fakeDB)
+		err := reform.NewDBFromInterface(fake, dialect, nil).Upsert(&PersonProject{PersonID: 1, ProjectID: "baron"},
+			[]string{"PersonID", "project_id"})
+		s.Equal(errFake, err)
+		s.Equal([]string{expected}, fake.queries)
+	}
+
+	fake := new(fakeDB)
+	err := reform.NewDBFromInterface(fake, redshift.Dialect, nil).Upsert(&PersonProject{}, []string{"person_id"})
+	s.EqualError(err, "reform: Upsert is not supported by this dialect")
+	err = s.q.Upsert(&PersonProject{}, []string{"no_such_column"})
+	s.EqualError(err, "reform: unexpected columns: [no_such_column]")
+
+	// two-column conflict target
+	pp := &PersonProject{PersonID: 1, ProjectID: "baron"}
+	for i := 0; i < 2; i++ {
+		err = s.q.Upsert(pp, []string{"person_id", "project_id"})
+		s.NoError(err)
+	}
+	structs, err := s.q.SelectAllFrom(PersonProjectView, "WHERE person_id = 1 AND project_id = 'baron'")
+	s.NoError(err)
+	s.Len(structs, 1)
+
+	if s.q.UpsertMethod() == reform.Merge {
+		s.T().Skip("inserting explicit identity values requires SET IDENTITY_INSERT")
+	}
+
+	person, err := s.q.FindByPrimaryKeyFrom(PersonTable, 102)
+	s.Require().NoError(err)
+	person.(*Person).Name = "Jane"
+	person.(*Person).Email = nil
+	err = s.q.Upsert(person, []string{"id"}, "Name")
+	s.NoError(err)
+
+	person2, err := s.q.FindByPrimaryKeyFrom(PersonTable, 102)
+	s.NoError(err)
+	s.Equal("Jane", person2.(*Person).Name)
+	s.Equal(pointer.ToString("elfrieda_abbott@example.org"), person2.(*Person).Email)
+}
+
+func (s *ReformSuite) TestUpsertResult() {
+	for dialect, expected := range map[reform.Dialect]string{
+		postgresql.Dialect: `INSERT INTO "projects" ("name", "id", "start", "end") VALUES ($1, $2, $3, $4) ` +
+			`ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "start" = EXCLUDED."start", "end" = EXCLUDED."end" ` +
+			`RETURNING "id", (xmax = 0)`,
+		mssql.Dialect: `MERGE INTO [projects] AS target USING (VALUES (?, ?, ?, ?)) AS source ([name], [id], [start], [end]) ` +
+			`ON target.[id] = source.[id] ` +
+			`WHEN MATCHED THEN UPDATE SET target.[name] = source.[name], target.[start] = source.[start], target.[end] = source.[end] ` +
+			`WHEN NOT MATCHED THEN INSERT ([name], [id], [start], [end]) VALUES (source.[name], source.[id], source.[start], source.[end]) ` +
+			`OUTPUT $action, INSERTED.[id];`,
+	} {
+		fake := new(fakeDB)
+		s.Panics(func() {
+			reform.NewDBFromInterface(fake, dialect, nil).UpsertResult(&Project{ID: "baron", Name: "Baron"}, "id")
+		})
+		s.Equal([]string{expected}, fake.queries)
+	}
+
+	project := &Project{ID: "baron", Name: "Brave Baron", Start: baronStart}
+	action, err := s.q.UpsertResult(project, "id")
+	s.NoError(err)
+	s.Equal(reform.Updated, action)
+
+	project = &Project{ID: "upserted", Name: "Upserted", Start: baronStart}
+	action, err = s.q.UpsertResult(project, "id")
+	s.NoError(err)
+	s.Equal(reform.Inserted, action)
+	action, err = s.q.UpsertResult(project, "id")
+	s.NoError(err)
+	s.Equal(reform.Updated, action)
+
+	projects, err := s.q.SelectAllFrom(ProjectTable, "WHERE id IN ('baron', 'upserted') ORDER BY id")
+	s.NoError(err)
+	s.Require().Len(projects, 2)
+	s.Equal("Brave Baron", projects[0].(*Project).Name)
+	s.Equal("Upserted", projects[1].(*Project).Name)
+
+	if s.q.UpsertMethod() == reform.Merge {
+		s.T().Skip("conflict column should be inserted")
+	}
+
+	person := &Person{Name: "Upserted"}
+	action, err = s.q.UpsertResult(person, "id")
+	s.NoError(err)
+	s.Equal(reform.Inserted, action)
+	s.NotEqual(int32(0), person.ID)
+}
+
+func (s *ReformSuite) TestUpsertWhere() {
+	fake := new(fakeDB)
+	err := reform.NewDBFromInterface(fake, postgresql.Dialect, nil).UpsertWhere(&PersonProject{PersonID: 1, ProjectID: "baron"},
+		[]string{"person_id", "project_id"}, "$ProjectID <> 'deleted'")
+	s.Equal(errFake, err)
+	expected := `INSERT INTO "person_project" ("person_id", "project_id") VALUES ($1, $2) ` +
+		`ON CONFLICT ("person_id", "project_id") WHERE project_id <> 'deleted' DO NOTHING`
+	s.Equal([]string{expected}, fake.queries)
+
+	for _, dialect := range []reform.Dialect{mysql.Dialect, mssql.Dialect} {
+		err = reform.NewDBFromInterface(new(fakeDB), dialect, nil).UpsertWhere(&PersonProject{},
+			[]string{"person_id", "project_id"}, "project_id <> 'deleted'")
+		s.EqualError(err, "reform: conflict predicate is not supported by this dialect")
+	}
+
+	if s.q.UpsertMethod() != reform.OnConflict {
+		s.T().Skip("conflict predicate is supported only with ON CONFLICT")
+	}
+
+	// unique index which is not partial satisfies any predicate
+	pp := &PersonProject{PersonID: 1, ProjectID: "baron"}
+	for i := 0; i < 2; i++ {
+		err = s.q.UpsertWhere(pp, []string{"person_id", "project_id"}, "project_id IS NOT NULL")
+		s.NoError(err)
+	}
+	structs, err := s.q.SelectAllFrom(PersonProjectView, "WHERE person_id = 1 AND project_id = 'baron'")
+	s.NoError(err)
+	s.Len(structs, 1)
+}
+func (s *ReformSuite) TestInsertMultiReturning() {
+	for dialect, expected := range map[reform.Dialect]string{
+		postgresql.Dialect: `INSERT INTO "people" ("group_id", "name", "email", "created_at", "updated_at") ` +
+			`VALUES ($1, $2, $3, $4, $5), ($6, $7, $8, $9, $10) ` +
+			`RETURNING "id", "group_id", "name", "email", "created_at", "updated_at"`,
+		mssql.Dialect: `INSERT INTO [people] ([group_id], [name], [email], [created_at], [updated_at]) ` +
+			`OUTPUT INSERTED.[id], INSERTED.[group_id], INSERTED.[name], INSERTED.[email], INSERTED.[created_at], INSERTED.[updated_at] ` +
+			`VALUES (?, ?, ?, ?, ?), (?, ?, ?, ?, ?)`,
+		spanner.Dialect: "INSERT INTO `people` (`group_id`, `name`, `email`, `created_at`, `updated_at`) " +
+			"VALUES (@p1, @p2, @p3, @p4, @p5), (@p6, @p7, @p8, @p9, @p10) " +
+			"THEN RETURN `id`, `group_id`, `name`, `email`, `created_at`, `updated_at`",
+	} {
+		fake := new(fakeDB)
+		tx := reform.NewTXFromInterface(fakeTX{fake}, dialect, nil)
+		_, err := tx.InsertMultiReturning(&Person{Name: "Alice"}, &Person{Name: "Bob"})
+		s.Equal(errFake, err)
+		s.Equal([]string{expected}, fake.queries)
+	}
+
+	alice := &Person{Name: "Alice", Email: pointer.ToString(faker.Internet().Email())}
+	bob := &Person{Name: "Bob"}
+	structs, err := s.q.InsertMultiReturning(alice, bob)
+	if m := s.q.LastInsertIdMethod(); m != reform.Returning && m != reform.OutputInserted {
+		s.EqualError(err, "reform: InsertMultiReturning is not supported by this dialect")
+		return
+	}
+	s.NoError(err)
+	s.Equal([]reform.Struct{alice, bob}, structs)
+	s.NotEqual(int32(0), alice.ID)
+	s.NotEqual(int32(0), bob.ID)
+	s.NotEqual(alice.ID, bob.ID)
+
+	person, err := s.q.FindByPrimaryKeyFrom(PersonTable, bob.ID)
+	s.NoError(err)
+	s.Equal(bob, person)
+}
+
+func (s *ReformSuite) TestInsertNoLastInsertId() {
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, redshift.Dialect, nil)
+
+	person := &Person{Name: "Alice"}
+	err := db.Insert(person)
+	s.Equal(errFake, err)
+	s.Equal(int32(0), person.ID)
+
+	err = db.InsertMulti(&Person{Name: "Alice"}, &Person{Name: "Bob"})
+	s.Equal(errFake, err)
+
+	s.Equal([]string{
+		`INSERT INTO "people" ("group_id", "name", "email", "created_at", "updated_at") ` +
+			`VALUES ($1, $2, $3, $4, $5)`,
+		`INSERT INTO "people" ("group_id", "name", "email", "created_at", "updated_at") ` +
+			`VALUES ($1, $2, $3, $4, $5), ($6, $7, $8, $9, $10)`,
+	}, fake.queries)
+}
+
+func (s *ReformSuite) TestTransformers() {
+	s.q.Transformers = map[reform.View]map[string]reform.ColumnTransformer{
+		PersonTable: {
+			"name": {
+				ToDB: func(value interface{}) (interface{}, error) {
+					return base64.StdEncoding.EncodeToString([]byte(value.(string))), nil
+				},
+				FromDB: func(value interface{}) (interface{}, error) {
+					b, err := base64.StdEncoding.DecodeString(value.(string))
+					return string(b), err
+				},
+			},
+		},
+	}
+
+	person := &Person{Name: "Alice"}
+	err := s.q.Insert(person)
+	s.NoError(err)
+	s.Equal("Alice", person.Name)
+
+	var name string
+	err = s.q.QueryRow("SELECT name FROM people WHERE id = "+s.q.Placeholder(1), person.ID).Scan(&name)
+	s.NoError(err)
+	s.Equal("QWxpY2U=", name)
+
+	person2, err := s.q.FindByPrimaryKeyFrom(PersonTable, person.ID)
+	s.NoError(err)
+	s.Equal("Alice", person2.(*Person).Name)
+
+	person.Name = "Bob"
+	err = s.q.Update(person)
+	s.NoError(err)
+	structs, err := s.q.FindAllFrom(PersonTable, "name", "Qm9i")
+	s.NoError(err)
+	s.Require().Len(structs, 1)
+	s.Equal("Bob", structs[0].(*Person).Name)
+}
+
 func (s *ReformSuite) TestInsertMulti() {
 	newEmail := faker.Internet().Email()
 	newName := faker.Name().Name()
@@ -162,6 +642,121 @@ func (s *ReformSuite) TestInsertMulti() {
 	s.Nil(person2.UpdatedAt)
 }
 
+// orderedPersonTable is PersonTable with pinned order of columns in multi-row inserts.
+type orderedPersonTable struct {
+	reform.Table
+	order []string
+}
+
+func (t orderedPersonTable) ColumnOrder() []string { return t.order }
+
+// orderedPerson is a Person with orderedPersonTable view.
+type orderedPerson struct {
+	*Person
+	table *orderedPersonTable
+}
+
+func (p orderedPerson) View() reform.View { return p.table }
+
+func (s *ReformSuite) TestColumnOrder() {
+	// generated order is a part of the contract
+	s.Equal([]string{"id", "group_id", "name", "email", "created_at", "updated_at"}, PersonTable.Columns())
+	person := &Person{ID: 42, GroupID: pointer.ToInt32(65534), Name: "Alice", Email: pointer.ToString("alice@example.com")}
+	values, pointers := person.Values(), person.Pointers()
+	s.Require().Len(values, len(PersonTable.Columns()))
+	s.Require().Len(pointers, len(values))
+	for i, p := range pointers {
+		s.Equal(values[i], reflect.ValueOf(p).Elem().Interface(), "%s", PersonTable.Columns()[i])
+	}
+
+	table := &orderedPersonTable{PersonTable, []string{"name", "updated_at", "id", "email", "group_id", "created_at"}}
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, postgresql.Dialect, nil)
+	err := db.InsertMulti(orderedPerson{&Person{Name: "Alice"}, table}, orderedPerson{&Person{Name: "Bob"}, table})
+	s.Equal(errFake, err)
+	s.Equal([]string{`INSERT INTO "people" ("name", "updated_at", "email", "group_id", "created_at") ` +
+		`VALUES ($1, $2, $3, $4, $5), ($6, $7, $8, $9, $10)`}, fake.queries)
+
+	email := faker.Internet().Email()
+	err = s.q.InsertMulti(
+		orderedPerson{&Person{Name: "ordered 1", Email: &email}, table},
+		orderedPerson{&Person{Name: "ordered 2", GroupID: pointer.ToInt32(65534)}, table},
+	)
+	s.NoError(err)
+	structs, err := s.q.SelectAllFrom(PersonTable, "WHERE name LIKE 'ordered %' ORDER BY name")
+	s.NoError(err)
+	s.Require().Len(structs, 2)
+	s.Equal(&email, structs[0].(*Person).Email)
+	s.Nil(structs[0].(*Person).GroupID)
+	s.Nil(structs[1].(*Person).Email)
+	s.Equal(pointer.ToInt32(65534), structs[1].(*Person).GroupID)
+
+	for order, expected := range map[string]string{
+		"name": "reform: ColumnOrder of people has 1 columns, expected 6",
+		"name updated_at id email name created_at":  "reform: duplicate column name in ColumnOrder of people",
+		"name updated_at id email group created_at": "reform: unexpected column group in ColumnOrder of people",
+	} {
+		table.order = strings.Fields(order)
+		err = db.InsertMulti(orderedPerson{&Person{Name: "Alice"}, table})
+		s.EqualError(err, expected)
+	}
+}
+
+func (s *ReformSuite) TestInsertMultiMaxRows() {
+	var inserts int
+	s.q.QueryRewriter = func(op string, query string) string {
+		if op == "insert" {
+			inserts++
+		}
+		return query
+	}
+	s.q.MaxInsertMultiRows = 2
+
+	people := make([]reform.Struct, 5)
+	for i := range people {
+		people[i] = &Person{Name: fmt.Sprintf("max rows %d", i)}
+	}
+	err := s.q.InsertMulti(people...)
+	s.NoError(err)
+	s.Equal(3, inserts)
+
+	structs, err := s.q.SelectAllFrom(PersonTable, "WHERE name LIKE 'max rows %'")
+	s.NoError(err)
+	s.Len(structs, 5)
+}
+
+// maxPlaceholdersDialect is a Dialect with MaxPlaceholders less than the number of people columns.
+type maxPlaceholdersDialect struct {
+	reform.Dialect
+}
+
+func (maxPlaceholdersDialect) MaxPlaceholders() int {
+	return 3
+}
+
+func (s *ReformSuite) TestInsertMultiMaxPlaceholders() {
+	q := reform.NewTXFromInterface(s.q, maxPlaceholdersDialect{s.q.Dialect}, nil)
+	var inserts int
+	q.QueryRewriter = func(op string, query string) string {
+		if op == "insert" {
+			inserts++
+		}
+		return query
+	}
+
+	people := make([]reform.Struct, 3)
+	for i := range people {
+		people[i] = &Person{Name: fmt.Sprintf("max placeholders %d", i)}
+	}
+	err := q.InsertMulti(people...)
+	s.NoError(err)
+	s.Equal(3, inserts)
+
+	structs, err := s.q.SelectAllFrom(PersonTable, "WHERE name LIKE 'max placeholders %'")
+	s.NoError(err)
+	s.Len(structs, 3)
+}
+
 func (s *ReformSuite) TestInsertMultiWithPrimaryKeys() {
 	setIdentityInsert(s.T(), s.q, "people", true)
 
@@ -203,6 +798,52 @@ func (s *ReformSuite) TestInsertMultiMixes() {
 	s.Error(err)
 }
 
+func (s *ReformSuite) TestInsertMultiIfNotExists() {
+	batch := []reform.Struct{
+		&PersonProject{PersonID: 1, ProjectID: "baron"},
+		&PersonProject{PersonID: 102, ProjectID: "baron"}, // already exists
+		&PersonProject{PersonID: 1, ProjectID: "queen"},
+	}
+	if s.q.UpsertMethod() == reform.NoUpsert {
+		_, err := s.q.InsertMultiIfNotExists([]string{"person_id", "project_id"}, batch...)
+		s.EqualError(err, "reform: InsertMultiIfNotExists is not supported by this dialect")
+		return
+	}
+
+	inserted, err := s.q.InsertMultiIfNotExists([]string{"PersonID", "ProjectID"}, batch...)
+	s.NoError(err)
+	s.Equal(uint(2), inserted)
+
+	// batch is re-sent
+	inserted, err = s.q.InsertMultiIfNotExists([]string{"person_id", "project_id"}, batch...)
+	s.NoError(err)
+	s.Equal(uint(0), inserted)
+
+	structs, err := s.q.SelectAllFrom(PersonProjectView, "WHERE person_id = "+s.q.Placeholder(1)+" ORDER BY project_id", 1)
+	s.NoError(err)
+	s.Equal([]reform.Struct{
+		&PersonProject{PersonID: 1, ProjectID: "baron"},
+		&PersonProject{PersonID: 1, ProjectID: "queen"},
+	}, structs)
+
+	_, err = s.q.InsertMultiIfNotExists([]string{"no_such_column"}, batch...)
+	s.EqualError(err, "reform: unexpected columns: [no_such_column]")
+
+	for dialect, expected := range map[reform.Dialect]string{
+		postgresql.Dialect: `INSERT INTO "person_project" ("person_id", "project_id") VALUES ($1, $2), ($3, $4), ($5, $6) ` +
+			`ON CONFLICT ("person_id", "project_id") DO NOTHING`,
+		mysql.Dialect: "INSERT IGNORE INTO `person_project` (`person_id`, `project_id`) VALUES (?, ?), (?, ?), (?, ?)",
+		mssql.Dialect: "MERGE INTO [person_project] AS target USING (VALUES (?, ?), (?, ?), (?, ?)) AS source ([person_id], [project_id]) " +
+			"ON target.[person_id] = source.[person_id] AND target.[project_id] = source.[project_id] " +
+			"WHEN NOT MATCHED THEN INSERT ([person_id], [project_id]) VALUES (source.[person_id], source.[project_id]);",
+	} {
+		fake := new(fakeDB)
+		_, err = reform.NewDBFromInterface(fake, dialect, nil).InsertMultiIfNotExists([]string{"person_id", "project_id"}, batch...)
+		s.Equal(errFake, err)
+		s.Equal([]string{expected}, fake.queries)
+	}
+}
+
 func (s *ReformSuite) TestInsertIDOnly() {
 	id := &IDOnly{}
 	err := s.q.Insert(id)
@@ -210,6 +851,21 @@ func (s *ReformSuite) TestInsertIDOnly() {
 	s.Equal(int32(1), id.ID)
 }
 
+func (s *ReformSuite) TestInsertSelect() {
+	columns := []string{"Name", "email", "created_at"}
+	ra, err := s.q.InsertSelect(PersonTable, columns, PersonTable, "WHERE name = "+s.q.Placeholder(1), "Elfrieda Abbott")
+	s.NoError(err)
+	s.Equal(uint(2), ra)
+
+	structs, err := s.q.FindAllFrom(PersonTable, "name", "Elfrieda Abbott")
+	s.NoError(err)
+	s.Len(structs, 4)
+
+	ra, err = s.q.InsertSelect(PersonTable, []string{"name", "start"}, ProjectTable, "")
+	s.EqualError(err, "reform: unexpected column start for people")
+	s.Equal(uint(0), ra)
+}
+
 func (s *ReformSuite) TestUpdate() {
 	var person Person
 	err := s.q.Update(&person)
@@ -234,6 +890,31 @@ func (s *ReformSuite) TestUpdate() {
 	s.Equal(&person, person2)
 }
 
+func (s *ReformSuite) TestUpdateWithResult() {
+	var person Person
+	ra, err := s.q.UpdateWithResult(&person)
+	s.Equal(reform.ErrNoPK, err)
+	s.Equal(int64(0), ra)
+
+	person.ID = 99
+	ra, err = s.q.UpdateWithResult(&person)
+	s.NoError(err)
+	s.Equal(int64(0), ra)
+
+	err = s.q.FindByPrimaryKeyTo(&person, 102)
+	s.NoError(err)
+
+	person.Email = pointer.ToString(faker.Internet().Email())
+	ra, err = s.q.UpdateWithResult(&person)
+	s.NoError(err)
+	s.Equal(int64(1), ra)
+
+	person.Name = faker.Name().Name()
+	ra, err = s.q.UpdateColumnsWithResult(&person, "name")
+	s.NoError(err)
+	s.Equal(int64(1), ra)
+}
+
 func (s *ReformSuite) TestUpdateOverwrite() {
 	newEmail := faker.Internet().Email()
 	person := Person{ID: 102, Email: pointer.ToString(newEmail)}
@@ -250,6 +931,46 @@ func (s *ReformSuite) TestUpdateOverwrite() {
 	s.WithinDuration(time.Now(), *person2.UpdatedAt, 2*time.Second)
 }
 
+func (s *ReformSuite) TestUpdateIf() {
+	str, err := s.q.FindByPrimaryKeyFrom(PersonTable, 102)
+	s.Require().NoError(err)
+	person := str.(*Person)
+
+	person.Name = "State 1"
+	updated, err := s.q.UpdateIf(person, map[string]interface{}{"name": "Elfrieda Abbott"}, "name")
+	s.NoError(err)
+	s.True(updated)
+
+	// stale read
+	person.Name = "State 2"
+	updated, err = s.q.UpdateIf(person, map[string]interface{}{"Name": "Elfrieda Abbott"}, "name")
+	s.NoError(err)
+	s.False(updated)
+
+	person2, err := s.q.FindByPrimaryKeyFrom(PersonTable, 102)
+	s.NoError(err)
+	s.Equal("State 1", person2.(*Person).Name)
+
+	// NULL condition
+	str, err = s.q.FindByPrimaryKeyFrom(PersonTable, 103)
+	s.Require().NoError(err)
+	person = str.(*Person)
+	person.Email = pointer.ToString("cas@example.org")
+	updated, err = s.q.UpdateIf(person, map[string]interface{}{"email": nil, "name": "Elfrieda Abbott"}, "email")
+	s.NoError(err)
+	s.True(updated)
+	updated, err = s.q.UpdateIf(person, map[string]interface{}{"email": (*string)(nil)}, "email")
+	s.NoError(err)
+	s.False(updated)
+
+	_, err = s.q.UpdateIf(person, map[string]interface{}{"no_such_column": 1}, "email")
+	s.EqualError(err, "reform: unexpected columns: [no_such_column]")
+	_, err = s.q.UpdateIf(&Person{}, nil, "email")
+	s.Equal(reform.ErrNoPK, err)
+	_, err = s.q.UpdateIf(person, nil)
+	s.Equal(reform.ErrNothingToUpdate, err)
+}
+
 func (s *ReformSuite) TestUpdateColumns() {
 	newName := faker.Name().Name()
 	newEmail := faker.Internet().Email()
@@ -290,6 +1011,311 @@ func (s *ReformSuite) TestUpdateColumns() {
 	}
 }
 
+// enumPerson is a Person with enum constraint on name.
+type enumPerson struct {
+	*Person
+}
+
+func (enumPerson) Enums() map[string][]string {
+	return map[string][]string{"Name": {"Alice", "Bob"}}
+}
+
+func (s *ReformSuite) TestEnums() {
+	person := enumPerson{&Person{Name: "Eve"}}
+	err := s.q.Insert(person)
+	s.Equal(&reform.InvalidEnumError{Column: "name", Value: "Eve"}, err)
+	s.EqualError(err, `reform: invalid value "Eve" for enum column name`)
+
+	person.Name = "Alice"
+	err = s.q.Insert(person)
+	s.NoError(err)
+
+	person.Name = "Mallory"
+	err = s.q.Update(person)
+	s.Equal(&reform.InvalidEnumError{Column: "name", Value: "Mallory"}, err)
+
+	person.Name = "Bob"
+	err = s.q.UpdateColumns(person, "name")
+	s.NoError(err)
+}
+
+// snapshotPerson is a Person which remembers values loaded by FindByPrimaryKeyTo.
+type snapshotPerson struct {
+	*Person
+	snapshot []interface{}
+}
+
+func (p *snapshotPerson) Snapshot() []interface{} {
+	return p.snapshot
+}
+
+func (p *snapshotPerson) SetSnapshot(values []interface{}) {
+	p.snapshot = values
+}
+
+func (s *ReformSuite) TestUpdateChanged() {
+	person := &snapshotPerson{Person: new(Person)}
+	err := s.q.FindByPrimaryKeyTo(person, 102)
+	s.Require().NoError(err)
+	s.Require().NotNil(person.snapshot)
+
+	// BeforeUpdate sets updated_at
+	fake := new(fakeDB)
+	err = reform.NewDBFromInterface(fake, s.q.Dialect, nil).UpdateChanged(person)
+	s.Equal(errFake, err)
+	expected := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s",
+		s.q.QuoteIdentifier("people"), s.q.QuoteIdentifier("updated_at"), s.q.Placeholder(1),
+		s.q.QuoteIdentifier("id"), s.q.Placeholder(2))
+	s.Equal([]string{expected}, fake.queries)
+
+	// change pointer's target in place
+	newEmail := faker.Internet().Email()
+	*person.Email = newEmail
+
+	fake = new(fakeDB)
+	err = reform.NewDBFromInterface(fake, s.q.Dialect, nil).UpdateChanged(person)
+	s.Equal(errFake, err)
+	expected = fmt.Sprintf("UPDATE %s SET %s = %s, %s = %s WHERE %s = %s",
+		s.q.QuoteIdentifier("people"), s.q.QuoteIdentifier("email"), s.q.Placeholder(1),
+		s.q.QuoteIdentifier("updated_at"), s.q.Placeholder(2),
+		s.q.QuoteIdentifier("id"), s.q.Placeholder(3))
+	s.Equal([]string{expected}, fake.queries)
+
+	err = s.q.UpdateChanged(person)
+	s.NoError(err)
+	s.Equal(person.Values(), person.snapshot)
+
+	person2, err := s.q.FindByPrimaryKeyFrom(PersonTable, 102)
+	s.NoError(err)
+	s.Equal(newEmail, *person2.(*Person).Email)
+
+	// without snapshot
+	person2.(*Person).Name = faker.Name().Name()
+	err = s.q.UpdateChanged(person2)
+	s.NoError(err)
+}
+
+func (s *ReformSuite) TestDiff() {
+	created := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	loaded := &Person{ID: 1, Name: "Alice", Email: pointer.ToString("alice@example.com"), CreatedAt: created}
+	current := *loaded
+	s.Empty(reform.Diff(loaded, &current))
+
+	// same instant in other location, equal targets of different pointers, typed nil
+	current.CreatedAt = created.In(time.FixedZone("UTC+3", 3*60*60))
+	current.Email = pointer.ToString("alice@example.com")
+	current.UpdatedAt = (*time.Time)(nil)
+	s.Empty(reform.Diff(loaded, &current))
+
+	current.Email = nil
+	current.UpdatedAt = pointer.ToTime(created)
+	current.CreatedAt = created.Add(time.Second)
+	s.Equal([]string{"Email", "CreatedAt", "UpdatedAt"}, reform.Diff(loaded, &current))
+
+	s.Panics(func() { reform.Diff(loaded, new(Project)) })
+}
+
+// outboxPerson is a Person which inserts a Project in the same transaction in BeforeInsertQ.
+type outboxPerson struct {
+	*Person
+	q *reform.Querier
+}
+
+func (p *outboxPerson) BeforeInsertQ(q *reform.Querier) error {
+	p.q = q
+	if err := p.Person.BeforeInsert(); err != nil {
+		return err
+	}
+	return q.Insert(&Project{ID: "outbox", Name: "Outbox", Start: time.Now()})
+}
+
+func (s *ReformSuite) TestBeforeInsertQ() {
+	newEmail := faker.Internet().Email()
+	person := &outboxPerson{Person: &Person{Email: &newEmail}}
+	err := s.q.Insert(person)
+	s.Require().NoError(err)
+	s.True(person.q == s.q.Querier)
+	s.NotEqual(int32(0), person.ID)
+	s.False(person.CreatedAt.IsZero(), "BeforeInsert of embedded Person should be called by hook")
+
+	_, err = s.q.FindByPrimaryKeyFrom(ProjectTable, "outbox")
+	s.NoError(err)
+}
+
+// hookedPersonTable is PersonTable which counts bulk hooks calls.
+type hookedPersonTable struct {
+	reform.Table
+	before, after []string
+	rows          []uint
+}
+
+func (t *hookedPersonTable) BeforeBulk(q *reform.Querier, op string) error {
+	t.before = append(t.before, op)
+	return nil
+}
+
+func (t *hookedPersonTable) AfterBulk(q *reform.Querier, op string, rows uint) error {
+	t.after = append(t.after, op)
+	t.rows = append(t.rows, rows)
+	return nil
+}
+
+// hookedPerson is a Person with hookedPersonTable view which counts insert hooks calls.
+type hookedPerson struct {
+	*Person
+	table                     *hookedPersonTable
+	beforeInsert, afterInsert int
+}
+
+func (p *hookedPerson) View() reform.View { return p.table }
+
+func (p *hookedPerson) BeforeInsert() error {
+	p.beforeInsert++
+	return p.Person.BeforeInsert()
+}
+
+func (p *hookedPerson) AfterInsert() error {
+	p.afterInsert++
+	return nil
+}
+
+func (s *ReformSuite) TestBulkHooks() {
+	table := &hookedPersonTable{Table: PersonTable}
+	name := faker.Name().Name()
+	people := []*hookedPerson{
+		{Person: &Person{Name: name}, table: table},
+		{Person: &Person{Name: name}, table: table},
+		{Person: &Person{Name: name}, table: table},
+	}
+	s.Require().NoError(s.q.InsertMulti(people[0], people[1], people[2]))
+	for _, p := range people {
+		s.Equal(1, p.beforeInsert)
+		s.Equal(1, p.afterInsert)
+		s.False(p.CreatedAt.IsZero())
+	}
+	s.Equal([]string{"insert"}, table.before)
+	s.Equal([]string{"insert"}, table.after)
+	s.Equal([]uint{3}, table.rows)
+
+	person := &hookedPerson{Person: &Person{Name: name}, table: table}
+	s.Require().NoError(s.q.Insert(person))
+	s.Equal(1, person.beforeInsert)
+	s.Equal(1, person.afterInsert)
+	s.Len(table.before, 1, "single insert is not a bulk operation")
+
+	ra, err := s.q.UpdateAll(table, map[string]interface{}{"group_id": 42}, "WHERE name = "+s.q.Placeholder(1), name)
+	s.NoError(err)
+	s.Equal(uint(4), ra)
+	ra, err = s.q.DeleteFrom(table, "WHERE name = "+s.q.Placeholder(1), name)
+	s.NoError(err)
+	s.Equal(uint(4), ra)
+	s.Equal([]string{"insert", "update", "delete"}, table.before)
+	s.Equal([]string{"insert", "update", "delete"}, table.after)
+	s.Equal([]uint{3, 4, 4}, table.rows)
+
+	q := *s.q.Querier
+	q.SkipBulkHooks = true
+	people = []*hookedPerson{
+		{Person: &Person{Name: name, CreatedAt: time.Now()}, table: table},
+		{Person: &Person{Name: name, CreatedAt: time.Now()}, table: table},
+		{Person: &Person{Name: name, CreatedAt: time.Now()}, table: table},
+	}
+	s.Require().NoError(q.InsertMulti(people[0], people[1], people[2]))
+	for _, p := range people {
+		s.Equal(0, p.beforeInsert)
+		s.Equal(0, p.afterInsert)
+	}
+	s.Len(table.before, 3)
+	s.Len(table.after, 3)
+}
+
+func (s *ReformSuite) TestUpdateMultiFromValues() {
+	records := make([]reform.Record, 1000)
+	for i := range records {
+		records[i] = &Person{ID: int32(i + 1), Name: fmt.Sprintf("Person %d", i+1), CreatedAt: personCreated}
+	}
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, postgresql.Dialect, nil)
+	_, err := db.UpdateMultiFromValues(records...)
+	s.Equal(errFake, err)
+	s.Require().Len(fake.queries, 1)
+	query := fake.queries[0]
+	s.True(strings.HasPrefix(query, `UPDATE "people" SET "group_id" = "t"."group_id", "name" = "t"."name", `+
+		`"email" = "t"."email", "created_at" = "t"."created_at", "updated_at" = "t"."updated_at" `+
+		`FROM (VALUES ((SELECT "id" FROM "people" WHERE FALSE), (SELECT "group_id" FROM "people" WHERE FALSE), `), query[:300])
+	s.Contains(query, `), ($1, $2, $3, $4, $5, $6), ($7, $8, $9, $10, $11, $12), `)
+	s.True(strings.HasSuffix(query, `, ($5995, $5996, $5997, $5998, $5999, $6000)) `+
+		`AS "t" ("id", "group_id", "name", "email", "created_at", "updated_at") WHERE "people"."id" = "t"."id"`))
+	for _, r := range records {
+		s.NotNil(r.(*Person).UpdatedAt, "BeforeUpdate should be called")
+	}
+
+	// a single row exceeds MaxPlaceholders
+	fake = new(fakeDB)
+	tx := reform.NewTXFromInterface(fakeTX{fake}, maxPlaceholdersDialect{postgresql.Dialect}, nil)
+	_, err = tx.UpdateMultiFromValues(records[:2]...)
+	s.Equal(errFake, err)
+	s.Require().Len(fake.queries, 1)
+	s.Contains(fake.queries[0], `, ($1, $2, $3, $4, $5, $6)) AS "t"`)
+
+	_, err = db.UpdateMultiFromValues(&Person{Name: "No PK"})
+	s.Equal(reform.ErrNoPK, err)
+	_, err = db.UpdateMultiFromValues(&Person{ID: 1}, &Project{ID: "baron"})
+	s.EqualError(err, "reform: UpdateMultiFromValues requires records of the same table")
+
+	if !s.q.SupportsUpdateFromValues() {
+		_, err = s.q.UpdateMultiFromValues(records...)
+		s.EqualError(err, "reform: UpdateMultiFromValues is not supported by this dialect")
+		return
+	}
+
+	person102, err := s.q.FindByPrimaryKeyFrom(PersonTable, 102)
+	s.Require().NoError(err)
+	person103, err := s.q.FindByPrimaryKeyFrom(PersonTable, 103)
+	s.Require().NoError(err)
+	person102.(*Person).Name = "Updated 102"
+	person103.(*Person).GroupID = pointer.ToInt32(42)
+	ra, err := s.q.UpdateMultiFromValues(person102.(*Person), person103.(*Person), &Person{ID: 424242, Name: "Missing"})
+	s.NoError(err)
+	s.Equal(uint(2), ra)
+
+	s.NoError(s.q.Reload(person102.(*Person)))
+	s.Equal("Updated 102", person102.(*Person).Name)
+	s.NoError(s.q.Reload(person103.(*Person)))
+	s.Equal(pointer.ToInt32(42), person103.(*Person).GroupID)
+}
+
+func (s *ReformSuite) TestUpdateAll() {
+	newEmail := faker.Internet().Email()
+	set := map[string]interface{}{"Email": newEmail, "group_id": 42}
+	ra, err := s.q.UpdateAll(PersonTable, set, "WHERE name = "+s.q.Placeholder(1), "Elfrieda Abbott")
+	s.NoError(err)
+	s.Equal(uint(2), ra)
+
+	structs, err := s.q.FindAllFrom(PersonTable, "email", newEmail)
+	s.NoError(err)
+	s.Require().Len(structs, 2)
+	for _, str := range structs {
+		s.Equal("Elfrieda Abbott", str.(*Person).Name)
+		s.Equal(pointer.ToInt32(42), str.(*Person).GroupID)
+	}
+
+	// caller's slice with spare capacity is not modified
+	args := make([]interface{}, 1, 3)
+	args[0] = "Elfrieda Abbott"
+	_, err = s.q.UpdateAll(PersonTable, set, "WHERE name = "+s.q.Placeholder(1), args...)
+	s.NoError(err)
+	s.Equal([]interface{}{"Elfrieda Abbott", nil, nil}, args[:3])
+
+	ra, err = s.q.UpdateAll(PersonTable, map[string]interface{}{"foo": 1}, "")
+	s.EqualError(err, "reform: unexpected columns: [foo]")
+	s.Equal(uint(0), ra)
+
+	ra, err = s.q.UpdateAll(PersonTable, nil, "")
+	s.EqualError(err, "reform: nothing to update")
+	s.Equal(uint(0), ra)
+}
+
 func (s *ReformSuite) TestSave() {
 	newName := faker.Name().Name()
 	person := &Person{Name: newName}
@@ -314,6 +1340,65 @@ func (s *ReformSuite) TestSave() {
 	s.Equal(person, person2)
 }
 
+func (s *ReformSuite) TestExpandNumberedPlaceholders() {
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, postgresql.Dialect, nil)
+	_, err := db.DeleteFrom(PersonTable, "WHERE $Name = $1 AND $ID > $12", "Alice", 42)
+	s.Equal(errFake, err)
+	s.Equal([]string{`DELETE FROM "people" WHERE name = $1 AND id > $12`}, fake.queries)
+}
+
+func (s *ReformSuite) TestSaveMulti() {
+	s.NoError(s.q.SaveMulti())
+
+	existing, err := s.q.FindByPrimaryKeyFrom(PersonTable, 102)
+	s.Require().NoError(err)
+	newName := faker.Name().Name()
+	existing.(*Person).Name = newName
+	alice := &Person{Name: "Alice"}
+	bob := &Person{Name: "Bob", Email: pointer.ToString(faker.Internet().Email())}
+
+	err = s.q.SaveMulti(alice, existing, bob)
+	s.Require().NoError(err)
+	if s.q.LastInsertIdMethod() == reform.NoLastInsertId {
+		return
+	}
+
+	s.NotEqual(int32(0), alice.ID)
+	s.NotEqual(int32(0), bob.ID)
+	s.NotEqual(alice.ID, bob.ID)
+	for _, person := range []*Person{alice, existing.(*Person), bob} {
+		person2, err := s.q.FindByPrimaryKeyFrom(PersonTable, person.ID)
+		s.Require().NoError(err)
+		s.Equal(person.Name, person2.(*Person).Name)
+		s.Equal(person.Email, person2.(*Person).Email)
+	}
+
+	// inside a transaction, the first error is returned as is
+	fake := new(fakeDB)
+	err = reform.NewTXFromInterface(fakeTX{fake}, s.q.Dialect, nil).SaveMulti(&Person{Name: "Carol"})
+	s.Equal(errFake, err)
+	s.Len(fake.queries, 1)
+}
+
+func (s *ReformSuite) TestFindOrCreate() {
+	newEmail := faker.Internet().Email()
+	person := &Person{Name: faker.Name().Name(), Email: &newEmail}
+	err := s.q.FindOrCreate(person, "email", newEmail)
+	s.NoError(err)
+	s.NotEqual(int32(0), person.ID)
+
+	person2 := &Person{Name: faker.Name().Name()}
+	err = s.q.FindOrCreate(person2, "email", newEmail)
+	s.NoError(err)
+	s.Equal(person.ID, person2.ID)
+	s.Equal(person.Name, person2.Name)
+
+	err = s.q.FindOrCreate(person2, "invalid_column", nil)
+	s.Error(err)
+	s.NotEqual(reform.ErrNoRows, err)
+}
+
 func (s *ReformSuite) TestDelete() {
 	person := &Person{ID: 1}
 	err := s.q.Delete(person)
@@ -336,6 +1421,21 @@ func (s *ReformSuite) TestDelete() {
 	s.Equal(reform.ErrNoRows, err)
 }
 
+func (s *ReformSuite) TestDeleteReturningRecord() {
+	person := &Person{ID: 102, Name: "Jane"}
+	err := s.q.DeleteReturningRecord(person)
+	s.NoError(err)
+	s.Equal(&Person{ID: 102, GroupID: pointer.ToInt32(65534), Name: "Elfrieda Abbott",
+		Email: pointer.ToString("elfrieda_abbott@example.org"), CreatedAt: personCreated}, person)
+	err = s.q.Reload(person)
+	s.Equal(reform.ErrNoRows, err)
+
+	err = s.q.DeleteReturningRecord(person)
+	s.Equal(reform.ErrNoRows, err)
+	err = s.q.DeleteReturningRecord(&Project{})
+	s.Equal(reform.ErrNoPK, err)
+}
+
 func (s *ReformSuite) TestDeleteFrom() {
 	ra, err := s.q.DeleteFrom(PersonTable, "WHERE email IS NULL")
 	s.NoError(err)
@@ -359,6 +1459,28 @@ func (s *ReformSuite) TestDeleteFrom() {
 	s.Equal(uint(0), ra)
 }
 
+func (s *ReformSuite) TestDeleteByPKs() {
+	ra, err := s.q.DeleteByPKs(PersonTable)
+	s.NoError(err)
+	s.Equal(uint(0), ra)
+
+	ra, err = s.q.DeleteByPKs(PersonTable, 1, 2, 99)
+	s.NoError(err)
+	s.Equal(uint(2), ra)
+
+	pks := make([]interface{}, s.q.MaxPlaceholders()+1)
+	for i := range pks {
+		pks[i] = 100 + i
+	}
+	ra, err = s.q.DeleteByPKs(PersonTable, pks...)
+	s.NoError(err)
+	s.Equal(uint(3), ra)
+
+	structs, err := s.q.SelectAllFrom(PersonTable, "")
+	s.NoError(err)
+	s.Nil(structs)
+}
+
 func (s *ReformSuite) TestCommandsSchema() {
 	if s.q.Dialect != postgresql.Dialect {
 		s.T().Skip("only PostgreSQL supports schemas")
@@ -372,3 +1494,158 @@ func (s *ReformSuite) TestCommandsSchema() {
 	err = s.q.Delete(legacyPerson)
 	s.NoError(err)
 }
+
+func (s *ReformSuite) TestDsExecReturning() {
+	fake := new(fakeDB)
+	_, err := reform.NewDBFromInterface(fake, s.q.Dialect, nil).DsExecReturning(PersonTable,
+		"UPDATE people SET $Name = 'Jane' RETURNING $ID, $Name")
+	s.Equal(errFake, err)
+	s.Equal([]string{"UPDATE people SET name = 'Jane' RETURNING id, name"}, fake.queries)
+
+	if s.q.Dialect != postgresql.Dialect {
+		s.T().Skip("only PostgreSQL supports RETURNING syntax")
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s RETURNING %s",
+		s.q.QuoteIdentifier("people"), s.q.QuoteIdentifier("name"), s.q.Placeholder(1),
+		s.q.QuoteIdentifier("id"), s.q.Placeholder(2), strings.Join(s.q.QualifiedColumns(PersonTable), ", "))
+	structs, err := s.q.DsExecReturning(PersonTable, query, "Jane", 102)
+	s.Require().NoError(err)
+	s.Require().Len(structs, 1)
+	s.Equal("Jane", structs[0].(*Person).Name)
+	s.Equal(int32(102), structs[0].(*Person).ID)
+}
+
+func (s *ReformSuite) TestDsUpdateReturning() {
+	if s.q.Dialect != postgresql.Dialect {
+		s.T().Skip("only PostgreSQL supports RETURNING syntax")
+	}
+
+	person, err := s.q.FindByPrimaryKeyFrom(PersonTable, 102)
+	s.Require().NoError(err)
+	person.(*Person).Name = "Jane"
+
+	ds := goqu.New("postgres", nil).From().Where(goqu.I("id").Eq(102))
+	structs, err := s.q.DsUpdateReturning(person, ds, "name")
+	s.Require().NoError(err)
+	s.Require().Len(structs, 1)
+	s.Equal("Jane", structs[0].(*Person).Name)
+	s.Equal(int32(102), structs[0].(*Person).ID)
+}
+
+func (s *ReformSuite) TestCopyFrom() {
+	_, err := reform.NewDBFromInterface(new(fakeDB), s.q.Dialect, nil).Prepare("SELECT 1")
+	s.EqualError(err, "reform: DBTX does not support Prepare")
+
+	if s.q.Dialect != postgresql.Dialect {
+		_, err = postgresql.CopyFrom(s.q, PersonTable, &Person{})
+		s.Error(err)
+		s.T().Skip("PostgreSQL-specific test")
+	}
+	if os.Getenv("REFORM_TEST_DRIVER") != "postgres" {
+		s.T().Skip("only github.com/lib/pq supports COPY")
+	}
+
+	structs := make([]reform.Struct, 3)
+	for i := range structs {
+		structs[i] = &Person{Name: faker.Name().Name(), CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	}
+	n, err := postgresql.CopyFrom(s.q, PersonTable, structs...)
+	s.Require().NoError(err)
+	s.Equal(int64(3), n)
+
+	for _, str := range structs {
+		_, err = s.q.SelectOneFrom(PersonTable, "WHERE name = "+s.q.Placeholder(1), str.(*Person).Name)
+		s.NoError(err)
+	}
+
+	_, err = postgresql.CopyFrom(s.q, PersonTable, &Person{}, &Person{ID: 1})
+	s.Error(err)
+}
+
+// copyFromPeople returns n new people for bulk insert benchmarks.
+func copyFromPeople(n int) []reform.Struct {
+	structs := make([]reform.Struct, n)
+	now := time.Now().UTC().Truncate(time.Second)
+	for i := range structs {
+		structs[i] = &Person{Name: fmt.Sprintf("Person %d", i), CreatedAt: now}
+	}
+	return structs
+}
+
+// valuesPerson is a Person which does not implement reform.ValuesFiller (and hooks),
+// so Values is used by insert methods.
+type valuesPerson struct {
+	reform.Record
+}
+
+func BenchmarkInsert(b *testing.B) {
+	tx, err := DB.Begin()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	b.Run("ValuesInto", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err = tx.Insert(&Person{Name: "Benchmark", CreatedAt: createdAt}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Values", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err = tx.Insert(valuesPerson{&Person{Name: "Benchmark", CreatedAt: createdAt}}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkCopyFrom(b *testing.B) {
+	if DB.Dialect != postgresql.Dialect || os.Getenv("REFORM_TEST_DRIVER") != "postgres" {
+		b.Skip("only PostgreSQL with github.com/lib/pq supports COPY")
+	}
+
+	structs := copyFromPeople(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx, err := DB.Begin()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err = postgresql.CopyFrom(tx, PersonTable, structs...); err != nil {
+			b.Fatal(err)
+		}
+		if err = tx.Rollback(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInsertMulti(b *testing.B) {
+	if DB.Dialect != postgresql.Dialect || os.Getenv("REFORM_TEST_DRIVER") != "postgres" {
+		b.Skip("compared with BenchmarkCopyFrom")
+	}
+
+	structs := copyFromPeople(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tx, err := DB.Begin()
+		if err != nil {
+			b.Fatal(err)
+		}
+		for rest := structs; len(rest) > 0; rest = rest[1000:] {
+			if err = tx.InsertMulti(rest[:1000]...); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err = tx.Rollback(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
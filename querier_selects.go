@@ -2,14 +2,19 @@ package reform
 
 import (
 	"database/sql"
+	"encoding/csv"
 	"fmt"
-	"os"
+	"io"
+	"reflect"
 	"strings"
+	"time"
 
 	"gopkg.in/doug-martin/goqu.v3"
 )
 
 // NextRow scans next result row from rows to str. If str implements AfterFinder, it also calls AfterFind().
+// If str implements AfterFinderRows, it scans result columns after str's columns (for example, from JOIN)
+// and calls AfterFindRows() with them instead.
 // It is caller's responsibility to call rows.Close().
 //
 // If there is no next result row, it returns ErrNoRows. It also may return rows.Next(), rows.Scan()
@@ -17,37 +22,166 @@ import (
 //
 // See SelectRows example for idiomatic usage.
 func (q *Querier) NextRow(str Struct, rows *sql.Rows) error {
-	var err error
 	next := rows.Next()
 	if !next {
-		err = rows.Err()
+		err := rows.Err()
 		if err == nil {
 			err = ErrNoRows
 		}
 		return err
 	}
 
-	err = rows.Scan(str.Pointers()...)
+	return q.scanRow(str, rows)
+}
+
+// scanRow scans current row into str, converts it from database representation and calls AfterFind hooks.
+func (q *Querier) scanRow(str Struct, rows *sql.Rows) error {
+	var err error
+	afr, ok := str.(AfterFinderRows)
+	if !ok {
+		err = rows.Scan(str.Pointers()...)
+		if err != nil {
+			return err
+		}
+
+		err = q.fromDB(str)
+		if err != nil {
+			return err
+		}
+
+		return q.callAfterFind(str)
+	}
+
+	// scan extra columns after struct's columns
+	dest := str.Pointers()
+	n := len(dest)
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	extraValues := make([]interface{}, 0, len(columns))
+	for i := n; i < len(columns); i++ {
+		v := new(interface{})
+		extraValues = append(extraValues, v)
+		dest = append(dest, v)
+	}
+
+	err = rows.Scan(dest...)
 	if err != nil {
 		return err
 	}
 
-	if af, ok := str.(AfterFinder); ok {
-		err = af.AfterFind()
+	err = q.fromDB(str)
+	if err != nil {
+		return err
 	}
-	return err
+
+	extra := make(map[string]interface{}, len(extraValues))
+	for i, v := range extraValues {
+		extra[columns[n+i]] = *(v.(*interface{}))
+	}
+	return afr.AfterFindRows(extra)
+}
+
+// callAfterFind calls AfterFindRows (without extra columns), AfterFindQ or AfterFind hook if str implements it.
+func (q *Querier) callAfterFind(str Struct) error {
+	switch af := str.(type) {
+	case AfterFinderRows:
+		return af.AfterFindRows(nil)
+	case AfterFinderQ:
+		return af.AfterFindQ(q)
+	case AfterFinder:
+		return af.AfterFind()
+	}
+	return nil
 }
 
 // selectQuery returns full SELECT query for given view and tail.
-func (q *Querier) selectQuery(view View, tail string, limit1 bool) string {
-	command := "SELECT"
+// If forUpdate is true, selected rows are locked with dialect's LockForUpdateMethod.
+func (q *Querier) selectQuery(view View, tail string, limit1, forUpdate bool) string {
+	return q.selectQueryAs(view, "", tail, limit1, forUpdate)
+}
 
+// selectQueryAs is like selectQuery, but also sets view alias if it is not empty,
+// and qualifies columns with it.
+func (q *Querier) selectQueryAs(view View, alias string, tail string, limit1, forUpdate bool) string {
+	lockForUpdateMethod := NoLockForUpdate
+	if forUpdate {
+		lockForUpdateMethod = q.LockForUpdateMethod()
+		switch lockForUpdateMethod {
+		case ForUpdate, UpdLock, NoLockForUpdate:
+			// nothing
+		default:
+			panic("reform: Unhandled LockForUpdateMethod. Please report this bug.")
+		}
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	buf.WriteString("SELECT ")
 	if limit1 && q.SelectLimitMethod() == SelectTop {
-		command += " TOP 1"
+		buf.WriteString("TOP 1 ")
 	}
 
-	return fmt.Sprintf("%s %s FROM %s %s",
-		command, strings.Join(q.QualifiedColumns(view), ", "), q.QualifiedView(view), tail)
+	if alias == "" {
+		buf.WriteString(q.qualifiedColumnsList(view))
+	} else {
+		writeJoined(buf, q.QualifiedColumnsAs(view, alias), ", ")
+	}
+
+	buf.WriteString(" FROM ")
+	q.writeQualifiedView(buf, view)
+	if alias != "" {
+		buf.WriteString(" AS ")
+		buf.WriteString(q.QuoteIdentifier(alias))
+	}
+	if lockForUpdateMethod == UpdLock {
+		buf.WriteString(" WITH (UPDLOCK)")
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(tail)
+	if lockForUpdateMethod == ForUpdate {
+		buf.WriteString(" FOR UPDATE")
+	}
+
+	return buf.String()
+}
+
+// queryOneTo expands and runs query with args and scans first result to str.
+// If str implements AfterFinder, it also calls AfterFind().
+func (q *Querier) queryOneTo(str Struct, query string, args ...interface{}) error {
+	found, err := q.queryOneToOK(str, query, args...)
+	if err == nil && !found {
+		err = ErrNoRows
+	}
+	return err
+}
+
+// queryOneToOK is like queryOneTo, but returns false instead of ErrNoRows if there are no rows in result.
+// Errors returned by hooks are returned as is, even if they are ErrNoRows.
+func (q *Querier) queryOneToOK(str Struct, query string, args ...interface{}) (_ bool, err error) {
+	defer q.observe("select", str.View(), time.Now(), &err)
+
+	err = q.QueryRow(Expand(str.View(), query), args...).Scan(str.Pointers()...)
+	if err == ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = q.fromDB(str)
+	if err != nil {
+		return false, err
+	}
+
+	err = q.callAfterFind(str)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // SelectOneTo queries str's View with tail and args and scans first result to str.
@@ -56,102 +190,661 @@ func (q *Querier) selectQuery(view View, tail string, limit1 bool) string {
 // If there are no rows in result, it returns ErrNoRows. It also may return QueryRow(), Scan()
 // and AfterFinder errors.
 func (q *Querier) SelectOneTo(str Struct, tail string, args ...interface{}) error {
-	query := q.selectQuery(str.View(), tail, true)
-	err := q.QueryRow(os.Expand(query, str.View().ToCol), args...).Scan(str.Pointers()...)
+	return q.queryOneTo(str, q.selectQuery(str.View(), tail, true, false), args...)
+}
+
+// SelectOneToOK is like SelectOneTo, but returns false and nil error instead of ErrNoRows
+// if there are no rows in result. Other errors, including ones returned by AfterFinder, are returned as is.
+func (q *Querier) SelectOneToOK(str Struct, tail string, args ...interface{}) (found bool, err error) {
+	return q.queryOneToOK(str, q.selectQuery(str.View(), tail, true, false), args...)
+}
+
+// SelectOneToForUpdate is like SelectOneTo, but also locks selected row until the end of transaction.
+// It makes sense only inside a transaction.
+func (q *Querier) SelectOneToForUpdate(str Struct, tail string, args ...interface{}) error {
+	return q.queryOneTo(str, q.selectQuery(str.View(), tail, true, true), args...)
+}
+
+func (q *Querier) DsSelectOneTo(str Struct, ds *goqu.Dataset) error {
+	query, args, err := q.dsSelectFrom(ds, str.View()).Limit(1).ToSql()
 	if err != nil {
 		return err
 	}
 
-	if af, ok := str.(AfterFinder); ok {
-		err = af.AfterFind()
+	return q.queryOneTo(str, query, args...)
+}
+
+// SelectOneFrom queries view with tail and args and scans first result to new Struct str.
+// If str implements AfterFinder, it also calls AfterFind().
+//
+// If there are no rows in result, it returns nil, ErrNoRows. It also may return QueryRow(), Scan()
+// and AfterFinder errors.
+func (q *Querier) SelectOneFrom(view View, tail string, args ...interface{}) (Struct, error) {
+	str := view.NewStruct()
+	err := q.SelectOneTo(str, tail, args...)
+	if err != nil {
+		return nil, err
+	}
+	return str, nil
+}
+
+func (q *Querier) DsSelectOneFrom(view View, ds *goqu.Dataset) (Struct, error) {
+	str := view.NewStruct()
+	err := q.DsSelectOneTo(str, ds)
+	if err != nil {
+		return nil, err
+	}
+	return str, nil
+}
+
+// SelectRows queries view with tail and args and returns rows. They can then be iterated with NextRow().
+// It is caller's responsibility to call rows.Close().
+//
+// In case of error rows will be nil. Error is never ErrNoRows.
+//
+// See example for idiomatic usage.
+func (q *Querier) SelectRows(view View, tail string, args ...interface{}) (_ *sql.Rows, err error) {
+	defer q.observe("select", view, time.Now(), &err)
+
+	query := q.selectQuery(view, tail, false, false)
+	return q.Query(Expand(view, query), args...)
+}
+
+func (q *Querier) DsSelectRows(view View, ds *goqu.Dataset) (_ *sql.Rows, err error) {
+	defer q.observe("select", view, time.Now(), &err)
+
+	query, args, err := q.DsToSQL(view, ds, SelectDsMode, nil)
+	if err != nil {
+		return nil, err
+	}
+	return q.Query(query, args...)
+}
+
+func (q *Querier) DsCount(view View, ds *goqu.Dataset) (uint64, error) {
+	query, args, err := q.DsToSQL(view, ds, CountDsMode, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := q.queryCount(view, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	if count < 0 {
+		count = 0
+	}
+	return uint64(count), nil
+}
+
+// queryCount runs COUNT query with args and returns its result.
+func (q *Querier) queryCount(view View, query string, args ...interface{}) (count int64, err error) {
+	defer q.observe("select", view, time.Now(), &err)
+
+	err = q.QueryRow(query, args...).Scan(&count)
+	return
+}
+
+// queryAllFrom expands and runs query with args and returns a slice of new Structs.
+// If view's Struct implements AfterFinder, it also calls AfterFind().
+func (q *Querier) queryAllFrom(view View, query string, args ...interface{}) (structs []Struct, err error) {
+	defer q.observe("select", view, time.Now(), &err)
+
+	var rows *sql.Rows
+	rows, err = q.Query(Expand(view, query), args...)
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := rows.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+
+	for {
+		str := view.NewStruct()
+		err = q.NextRow(str, rows)
+		if err != nil {
+			if err == ErrNoRows {
+				err = nil
+			}
+			return
+		}
+
+		structs = append(structs, str)
+	}
+}
+
+// SelectAllFrom queries view with tail and args and returns a slice of new Structs.
+// If view's Struct implements AfterFinder, it also calls AfterFind().
+//
+// In case of query error slice will be nil. If error is encountered during iteration,
+// partial result and error will be returned. Error is never ErrNoRows.
+func (q *Querier) SelectAllFrom(view View, tail string, args ...interface{}) ([]Struct, error) {
+	return q.queryAllFrom(view, q.selectQuery(view, tail, false, false), args...)
+}
+
+// SelectAllFromWithMaps is like SelectAllFrom, but also returns each Struct as a map of view's column names
+// to values returned by Struct's Values, for example, for rendering rows in generic UIs without reflection.
+// Maps are index-aligned with Structs; use view's Columns for ordered iteration over them.
+// Map values are not copied, so pointer values (like *string for nullable columns) share memory with Struct fields.
+// Note that all rows and maps are kept in memory at once, which may be too much for large result sets;
+// use SelectRows and NextRow for them instead.
+//
+// In case of query error slices will be nil. If error is encountered during iteration,
+// partial results and error will be returned. Error is never ErrNoRows.
+func (q *Querier) SelectAllFromWithMaps(view View, tail string, args ...interface{}) ([]Struct, []map[string]interface{}, error) {
+	structs, err := q.SelectAllFrom(view, tail, args...)
+	if structs == nil {
+		return nil, nil, err
+	}
+
+	columns := view.Columns()
+	maps := make([]map[string]interface{}, len(structs))
+	for i, str := range structs {
+		m := make(map[string]interface{}, len(columns))
+		for j, v := range str.Values() {
+			m[columns[j]] = v
+		}
+		maps[i] = m
+	}
+	return structs, maps, err
+}
+
+// SelectAllFromNamed is like SelectAllFrom, but queries table (or view) with given name instead of view's Name(),
+// like partition "orders_2025" for "orders" view. See WithTableName for details.
+func (q *Querier) SelectAllFromNamed(view View, tableName string, tail string, args ...interface{}) ([]Struct, error) {
+	nq, err := q.WithTableName(view, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return nq.SelectAllFrom(view, tail, args...)
+}
+
+// SelectDistinctAllFrom is like SelectAllFrom, but uses "SELECT DISTINCT", so duplicate rows are returned once.
+func (q *Querier) SelectDistinctAllFrom(view View, tail string, args ...interface{}) ([]Struct, error) {
+	query := "SELECT DISTINCT " + strings.TrimPrefix(q.selectQuery(view, tail, false, false), "SELECT ")
+	return q.queryAllFrom(view, query, args...)
+}
+
+// SelectDistinctColumn queries distinct values of view's column (or field) with tail and args
+// and returns them as a slice. Values have the same types as view's Struct fields (for example, *string
+// for nullable column). It can be used for populating filter options.
+//
+// In case of query error slice will be nil. If error is encountered during iteration,
+// partial result and error will be returned. Error is never ErrNoRows.
+func (q *Querier) SelectDistinctColumn(view View, column string, tail string, args ...interface{}) (values []interface{}, err error) {
+	col, ok := view.HasCol(column)
+	if !ok {
+		return nil, fmt.Errorf("reform: unexpected column %s for %s", column, view.Name())
+	}
+	var typ reflect.Type
+	for i, c := range view.Columns() {
+		if c == col {
+			typ = reflect.TypeOf(view.NewStruct().Pointers()[i]).Elem()
+			break
+		}
+	}
+
+	defer q.observe("select", view, time.Now(), &err)
+
+	qv := q.QualifiedView(view)
+	query := fmt.Sprintf("SELECT DISTINCT %s.%s FROM %s %s", qv, q.QuoteIdentifier(col), qv, tail)
+	rows, err := q.Query(Expand(view, query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		e := rows.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+
+	for rows.Next() {
+		v := reflect.New(typ)
+		if err = rows.Scan(v.Interface()); err != nil {
+			return
+		}
+		values = append(values, v.Elem().Interface())
+	}
+	err = rows.Err()
+	return
+}
+
+// SelectAllTolerant queries view with tail and args and returns a slice of new Structs.
+// If view's Struct implements AfterFinder, it also calls AfterFind().
+//
+// Unlike SelectAllFrom, it does not stop on the first row which can't be scanned or for which
+// AfterFind hook returns error: such rows are skipped, and their errors are returned in scanErrors
+// in order of appearance. err is returned only for query-level failures (query execution,
+// iteration or rows closing errors); in that case structs and scanErrors contain rows processed so far.
+// This trades strictness for completeness: use it only when partial results are better than none,
+// and always check scanErrors. err is never ErrNoRows.
+func (q *Querier) SelectAllTolerant(view View, tail string, args ...interface{}) (structs []Struct, scanErrors []error, err error) {
+	defer q.observe("select", view, time.Now(), &err)
+
+	var rows *sql.Rows
+	rows, err = q.Query(Expand(view, q.selectQuery(view, tail, false, false)), args...)
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := rows.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+
+	for rows.Next() {
+		str := view.NewStruct()
+		if e := q.scanRow(str, rows); e != nil {
+			scanErrors = append(scanErrors, e)
+			continue
+		}
+		structs = append(structs, str)
+	}
+	err = rows.Err()
+	return
+}
+
+// SelectJoined runs full SELECT query with args, typically with JOINs, and scans each result row
+// into new Structs of targets' views. It returns a slice of rows, each containing one Struct per target,
+// in the same order. If Struct implements AfterFinder, it also calls AfterFind().
+//
+// Query should select columns of all targets' views in the same order as targets are given,
+// and columns of each view in the order of its Columns(), for example, with QualifiedColumns
+// of each view joined with ", ". It returns error if the number of selected columns doesn't match.
+// Unlike other methods, query is used as is, without "$Field" references expansion.
+//
+// In case of query error slice will be nil. If error is encountered during iteration,
+// partial result and error will be returned. Error is never ErrNoRows.
+func (q *Querier) SelectJoined(query string, args []interface{}, targets ...Struct) (res [][]Struct, err error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("reform: SelectJoined requires at least one target")
+	}
+	defer q.observe("select", targets[0].View(), time.Now(), &err)
+
+	var n int
+	for _, t := range targets {
+		n += len(t.View().Columns())
+	}
+
+	var rows *sql.Rows
+	rows, err = q.Query(query, args...)
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := rows.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+
+	var columns []string
+	if columns, err = rows.Columns(); err != nil {
+		return
+	}
+	if len(columns) != n {
+		err = fmt.Errorf("reform: SelectJoined query returns %d columns, targets have %d", len(columns), n)
+		return
+	}
+
+	for rows.Next() {
+		row := make([]Struct, len(targets))
+		dest := make([]interface{}, 0, n)
+		for i, t := range targets {
+			row[i] = t.View().NewStruct()
+			dest = append(dest, row[i].Pointers()...)
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return
+		}
+
+		for _, str := range row {
+			if err = q.fromDB(str); err != nil {
+				return
+			}
+			if err = q.callAfterFind(str); err != nil {
+				return
+			}
+		}
+		res = append(res, row)
+	}
+	err = rows.Err()
+	return
+}
+
+// SelectByColumnNames runs full SELECT query with args and returns a slice of new Structs of view.
+// Unlike other methods, result columns are matched to view's columns by name (with view's HasCol,
+// then with FoldedCol for names folded by database, like "NAME" for "name" column), so they can be selected in any order, like with name-based mappers (sqlx, etc.).
+// Result columns without matching view's column are ignored; fields without matching result column
+// are left with zero values. If view's Struct implements AfterFinder, it also calls AfterFind().
+// "$Field" references in query are expanded.
+//
+// In case of query error slice will be nil. If error is encountered during iteration,
+// partial result and error will be returned. Error is never ErrNoRows.
+func (q *Querier) SelectByColumnNames(view View, query string, args ...interface{}) (structs []Struct, err error) {
+	defer q.observe("select", view, time.Now(), &err)
+
+	var rows *sql.Rows
+	rows, err = q.Query(Expand(view, query), args...)
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := rows.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+
+	var columns []string
+	if columns, err = rows.Columns(); err != nil {
+		return
+	}
+	index := make(map[string]int, len(view.Columns()))
+	for i, c := range view.Columns() {
+		index[c] = i
+	}
+
+	// positions[i] is an index of view's column for result column i, or -1
+	positions := make([]int, len(columns))
+	for i, c := range columns {
+		positions[i] = -1
+		col, ok := view.HasCol(c)
+		if !ok {
+			col, ok = view.FoldedCol(q.Dialect, c)
+		}
+		if ok {
+			positions[i] = index[col]
+		}
+	}
+
+	for rows.Next() {
+		str := view.NewStruct()
+		pointers := str.Pointers()
+		dest := make([]interface{}, len(columns))
+		for i, p := range positions {
+			if p < 0 {
+				dest[i] = new(interface{})
+			} else {
+				dest[i] = pointers[p]
+			}
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return
+		}
+
+		if err = q.fromDB(str); err != nil {
+			return
+		}
+		if err = q.callAfterFind(str); err != nil {
+			return
+		}
+		structs = append(structs, str)
+	}
+	err = rows.Err()
+	return
+}
+
+// SelectAllFromAs is like SelectAllFrom, but uses alias for view in "FROM" clause
+// and qualifies selected columns with it. Alias is quoted, tail should reference it the same way.
+// It allows to use the same view again in tail, for example, for self-joins.
+func (q *Querier) SelectAllFromAs(view View, alias string, tail string, args ...interface{}) ([]Struct, error) {
+	return q.queryAllFrom(view, q.selectQueryAs(view, alias, tail, false, false), args...)
+}
+
+// SelectAllFromCTE is like SelectAllFrom, but prefixes query with "WITH " and with,
+// so common table expressions defined there can be referenced in tail (for example, in JOINs).
+// with should include "RECURSIVE" keyword if it is required by dialect. "$Field" references
+// are expanded in the whole query with view's columns; placeholders in with go before tail's ones.
+func (q *Querier) SelectAllFromCTE(view View, with string, tail string, args ...interface{}) ([]Struct, error) {
+	return q.queryAllFrom(view, "WITH "+with+" "+q.selectQuery(view, tail, false, false), args...)
+}
+
+// cutOrderBy returns tail without trailing top-level "ORDER BY" clause, if any.
+func cutOrderBy(tail string) string {
+	upper := strings.ToUpper(tail)
+	var depth int
+	cut := -1
+	for i := 0; i < len(upper); i++ {
+		switch upper[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case 'O':
+			if depth == 0 && strings.HasPrefix(upper[i:], "ORDER") && (i == 0 || !isNameChar(upper[i-1], false)) {
+				rest := strings.TrimLeft(upper[i+len("ORDER"):], " \t\r\n")
+				if len(rest) < len(upper)-i-len("ORDER") && strings.HasPrefix(rest, "BY") {
+					cut = i
+				}
+			}
+		}
+	}
+	if cut < 0 {
+		return tail
+	}
+	return strings.TrimRight(tail[:cut], " \t\r\n")
+}
+
+// pageTail returns tail with limit and offset clauses for dialect's SelectLimitMethod.
+func (q *Querier) pageTail(tail string, limit, offset uint) string {
+	switch q.SelectLimitMethod() {
+	case Limit:
+		return fmt.Sprintf("%s LIMIT %d OFFSET %d", tail, limit, offset)
+	case SelectTop:
+		// OFFSET ... FETCH requires ORDER BY
+		if cutOrderBy(tail) == tail {
+			tail += " ORDER BY (SELECT NULL)"
+		}
+		return fmt.Sprintf("%s OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", tail, offset, limit)
+	case FetchFirst:
+		return fmt.Sprintf("%s OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", tail, offset, limit)
+	default:
+		panic("reform: Unhandled SelectLimitMethod. Please report this bug.")
+	}
+}
+
+// SelectAllAndCount queries view with tail and args and returns a page of new Structs
+// (at most limit rows after skipping offset ones) and a total number of rows matching tail.
+// If view's Struct implements AfterFinder, it also calls AfterFind().
+//
+// tail should not contain limit and offset clauses; trailing "ORDER BY" clause is used only for the page.
+// If limit is 0, only total is counted. Queries are not run in a transaction,
+// so use one if total should be consistent with the page. Error is never ErrNoRows.
+func (q *Querier) SelectAllAndCount(view View, tail string, limit, offset uint, args ...interface{}) (structs []Struct, total uint64, err error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", q.QualifiedView(view), cutOrderBy(tail))
+	var count int64
+	if count, err = q.queryCount(view, Expand(view, query), args...); err != nil {
+		return
+	}
+	if count > 0 {
+		total = uint64(count)
+	}
+
+	if limit == 0 || uint64(offset) >= total {
+		return
+	}
+	structs, err = q.queryAllFrom(view, q.selectQuery(view, q.pageTail(tail, limit, offset), false, false), args...)
+	return
+}
+
+// SelectForUpdate is like SelectAllFrom, but also locks selected rows until the end of transaction.
+// It makes sense only inside a transaction.
+func (q *Querier) SelectForUpdate(view View, tail string, args ...interface{}) ([]Struct, error) {
+	return q.queryAllFrom(view, q.selectQuery(view, tail, false, true), args...)
+}
+
+// SelectAllReuse queries view with tail and args and calls fn for each result row scanned to the same new Struct.
+// If Struct implements AfterFinder, it also calls AfterFind().
+// Struct is reused for all rows, so fn should copy it if it retains it after return.
+//
+// Iteration is stopped on first fn error, which is returned. Error is never ErrNoRows.
+func (q *Querier) SelectAllReuse(view View, fn func(Struct) error, tail string, args ...interface{}) (err error) {
+	var rows *sql.Rows
+	rows, err = q.SelectRows(view, tail, args...)
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := rows.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+
+	str := view.NewStruct()
+	for {
+		err = q.NextRow(str, rows)
+		if err != nil {
+			if err == ErrNoRows {
+				err = nil
+			}
+			return
+		}
+
+		err = fn(str)
+		if err != nil {
+			return
+		}
 	}
-	return err
 }
 
-func (q *Querier) DsSelectOneTo(str Struct, ds *goqu.Dataset) error {
-	query, args, err := ds.From(str.View().Name()).Select(str.View().IColumns()...).Limit(1).ToSql()
-	if err != nil {
+// ExportCSV queries view with tail and args and writes results to w as CSV: header row with view's columns,
+// then a row with struct's Values for each result row. Rows are streamed with SelectAllReuse,
+// so memory usage does not depend on their number.
+// Nil values (including nil pointers) are written as empty fields, time.Time values are formatted with RFC 3339,
+// []byte values are written as is, and other values are formatted with fmt.Sprint.
+func (q *Querier) ExportCSV(w io.Writer, view View, tail string, args ...interface{}) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(view.Columns()); err != nil {
 		return err
 	}
 
-	err = q.QueryRow(os.Expand(query, str.View().ToCol), args...).Scan(str.Pointers()...)
+	var record []string
+	err := q.SelectAllReuse(view, func(str Struct) error {
+		record = record[:0]
+		for _, v := range str.Values() {
+			record = append(record, csvField(v))
+		}
+		return cw.Write(record)
+	}, tail, args...)
 	if err != nil {
 		return err
 	}
 
-	if af, ok := str.(AfterFinder); ok {
-		err = af.AfterFind()
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvField returns CSV field for value v. See ExportCSV.
+func csvField(v interface{}) string {
+	if isNil(v) {
+		return ""
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr {
+		v = rv.Elem().Interface()
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
 	}
-	return err
 }
 
-// SelectOneFrom queries view with tail and args and scans first result to new Struct str.
-// If str implements AfterFinder, it also calls AfterFind().
+// SelectAllFromIn is like SelectAllFrom, but also allows slice arguments for "IN (placeholder)" conditions.
+// See SliceArgs for details.
+func (q *Querier) SelectAllFromIn(view View, tail string, args ...interface{}) ([]Struct, error) {
+	tail, args = q.SliceArgs(tail, args)
+	return q.SelectAllFrom(view, tail, args...)
+}
+
+// SelectMap queries view with tail and args and returns a map of new Structs keyed by keyColumn values.
+// keyColumn can be a column or a field name. Pointer values are dereferenced, NULL values are keyed by nil.
+// If several rows have the same key, the last one wins.
+// If view's Struct implements AfterFinder, it also calls AfterFind().
 //
-// If there are no rows in result, it returns nil, ErrNoRows. It also may return QueryRow(), Scan()
-// and AfterFinder errors.
-func (q *Querier) SelectOneFrom(view View, tail string, args ...interface{}) (Struct, error) {
-	str := view.NewStruct()
-	err := q.SelectOneTo(str, tail, args...)
-	if err != nil {
-		return nil, err
+// In case of query error map will be nil. If error is encountered during iteration,
+// partial result and error will be returned. Error is never ErrNoRows.
+func (q *Querier) SelectMap(view View, keyColumn string, tail string, args ...interface{}) (map[interface{}]Struct, error) {
+	column, ok := view.HasCol(keyColumn)
+	if !ok {
+		return nil, fmt.Errorf("reform: unexpected columns: [%s]", keyColumn)
+	}
+	var index int
+	for i, c := range view.Columns() {
+		if c == column {
+			index = i
+			break
+		}
 	}
-	return str, nil
-}
 
-func (q *Querier) DsSelectOneFrom(view View, ds *goqu.Dataset) (Struct, error) {
-	str := view.NewStruct()
-	err := q.DsSelectOneTo(str, ds)
-	if err != nil {
+	structs, err := q.SelectAllFrom(view, tail, args...)
+	if structs == nil {
 		return nil, err
 	}
-	return str, nil
-}
 
-// SelectRows queries view with tail and args and returns rows. They can then be iterated with NextRow().
-// It is caller's responsibility to call rows.Close().
-//
-// In case of error rows will be nil. Error is never ErrNoRows.
-//
-// See example for idiomatic usage.
-func (q *Querier) SelectRows(view View, tail string, args ...interface{}) (*sql.Rows, error) {
-	query := q.selectQuery(view, tail, false)
-	return q.Query(os.Expand(query, view.ToCol), args...)
+	res := make(map[interface{}]Struct, len(structs))
+	for _, str := range structs {
+		key := str.Values()[index]
+		if v := reflect.ValueOf(key); v.Kind() == reflect.Ptr {
+			key = nil
+			if !v.IsNil() {
+				key = v.Elem().Interface()
+			}
+		}
+		res[key] = str
+	}
+	return res, err
 }
 
-func (q *Querier) DsSelectRows(view View, ds *goqu.Dataset) (*sql.Rows, error) {
-	query, args, err := ds.From(view.Name()).Select(view.IColumns()...).ToSql()
+// SelectAllNamed queries view with tail with ":name" markers and args and returns a slice of new Structs.
+// See NamedTail for details about markers and SelectAllFrom for details about results.
+func (q *Querier) SelectAllNamed(view View, tail string, args map[string]interface{}) ([]Struct, error) {
+	tail, a, err := q.NamedTail(tail, args)
 	if err != nil {
 		return nil, err
 	}
-	return q.Query(os.Expand(query, view.ToCol), args...)
+	return q.SelectAllFrom(view, tail, a...)
 }
 
-func (q *Querier) DsCount(view View, ds *goqu.Dataset) (uint64, error) {
-	query, args, err := ds.From(view.Name()).Select(goqu.COUNT(goqu.Star()).As("count")).ToSql()
+func (q *Querier) DsSelectAllFrom(view View, ds *goqu.Dataset) ([]Struct, error) {
+	query, args, err := q.dsSelectFrom(ds, view).ToSql()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	var count int64
-	err = q.QueryRow(os.Expand(query, view.ToCol), args...).Scan(&count)
-	if err != nil {
-		return 0, err
-	}
-	if count < 0 {
-		count = 0
-	}
-	return uint64(count), nil
+	return q.queryAllFrom(view, query, args...)
 }
 
-// SelectAllFrom queries view with tail and args and returns a slice of new Structs.
-// If view's Struct implements AfterFinder, it also calls AfterFind().
+// DsSelectAllWithTotal is like DsSelectAllFrom, but also returns a total number of rows matching ds
+// without its limit and offset, like SelectAllAndCount, but with a single query: "COUNT(*) OVER () AS __total"
+// window function is added to selected columns. It requires window functions support by database
+// (PostgreSQL, MySQL 8.0+, SQLite 3.25+, MS SQL Server).
+// Note that total is 0 if ds selects no rows, for example, because offset is too large.
 //
 // In case of query error slice will be nil. If error is encountered during iteration,
 // partial result and error will be returned. Error is never ErrNoRows.
-func (q *Querier) SelectAllFrom(view View, tail string, args ...interface{}) (structs []Struct, err error) {
+func (q *Querier) DsSelectAllWithTotal(view View, ds *goqu.Dataset) (structs []Struct, total uint64, err error) {
+	defer q.observe("select", view, time.Now(), &err)
+
+	ds = q.dsSelectFrom(ds, view).SelectAppend(goqu.L("COUNT(*) OVER ()").As("__total"))
+	query, args, err := ds.ToSql()
+	if err != nil {
+		return
+	}
+
 	var rows *sql.Rows
-	rows, err = q.SelectRows(view, tail, args...)
+	rows, err = q.Query(query, args...)
 	if err != nil {
 		return
 	}
@@ -162,28 +855,44 @@ func (q *Querier) SelectAllFrom(view View, tail string, args ...interface{}) (st
 		}
 	}()
 
-	for {
+	for rows.Next() {
 		str := view.NewStruct()
-		err = q.NextRow(str, rows)
-		if err != nil {
-			if err == ErrNoRows {
-				err = nil
-			}
+		if err = rows.Scan(append(str.Pointers(), &total)...); err != nil {
+			return
+		}
+		if err = q.fromDB(str); err != nil {
+			return
+		}
+		if err = q.callAfterFind(str); err != nil {
 			return
 		}
-
 		structs = append(structs, str)
 	}
+	err = rows.Err()
+	return
 }
 
-func (q *Querier) DsSelectAllFrom(view View, ds *goqu.Dataset) (structs []Struct, err error) {
-	query, args, err := ds.From(view.Name()).Select(view.IColumns()...).ToSql()
-	if err != nil {
-		return
-	}
+// Call executes a query (typically a stored procedure call) and returns rows.
+// Unlike SelectRows, query is used as is, without "$Field" expansion.
+// Rows can contain several result sets; use rows.NextResultSet() to advance to the next one.
+// It is caller's responsibility to call rows.Close().
+//
+// In case of error rows will be nil. Error is never ErrNoRows.
+func (q *Querier) Call(query string, args ...interface{}) (*sql.Rows, error) {
+	return q.Query(query, args...)
+}
 
+// CallMulti executes a query (typically a stored procedure call) with several result sets
+// and returns a slice of new Structs for each of them: first result set is scanned to views[0] Structs,
+// second to views[1] Structs, and so on. Extra result sets are ignored.
+// If Structs implement AfterFinder, it also calls AfterFind().
+// Query is used as is, without "$Field" expansion. Rows are closed before return.
+//
+// It returns error if there are less result sets than views. If error is encountered during iteration,
+// partial result and error will be returned. Error is never ErrNoRows.
+func (q *Querier) CallMulti(views []View, query string, args ...interface{}) (res [][]Struct, err error) {
 	var rows *sql.Rows
-	rows, err = q.Query(os.Expand(query, view.ToCol), args...)
+	rows, err = q.Call(query, args...)
 	if err != nil {
 		return
 	}
@@ -194,17 +903,46 @@ func (q *Querier) DsSelectAllFrom(view View, ds *goqu.Dataset) (structs []Struct
 		}
 	}()
 
-	for {
-		str := view.NewStruct()
-		err = q.NextRow(str, rows)
-		if err != nil {
-			if err == ErrNoRows {
-				err = nil
+	res = make([][]Struct, 0, len(views))
+	for i, view := range views {
+		if i > 0 && !rows.NextResultSet() {
+			err = rows.Err()
+			if err == nil {
+				err = fmt.Errorf("reform: expected %d result sets, got %d", len(views), i)
 			}
 			return
 		}
 
-		structs = append(structs, str)
+		var structs []Struct
+		for {
+			str := view.NewStruct()
+			err = q.NextRow(str, rows)
+			if err != nil {
+				break
+			}
+			structs = append(structs, str)
+		}
+		res = append(res, structs)
+		if err != ErrNoRows {
+			return
+		}
+		err = nil
+	}
+	return
+}
+
+// limit1Clause returns a clause limiting query result to one row which should be appended to the tail,
+// or empty string for dialects which limit it in "SELECT" (see selectQueryAs).
+func (q *Querier) limit1Clause() string {
+	switch q.SelectLimitMethod() {
+	case Limit:
+		return " LIMIT 1"
+	case SelectTop:
+		return ""
+	case FetchFirst:
+		return " FETCH FIRST 1 ROW ONLY"
+	default:
+		panic("reform: Unhandled SelectLimitMethod. Please report this bug.")
 	}
 }
 
@@ -218,8 +956,8 @@ func (q *Querier) findTail(view string, column string, arg interface{}, limit1 b
 		needArg = true
 	}
 
-	if limit1 && q.SelectLimitMethod() == Limit {
-		tail += " LIMIT 1"
+	if limit1 {
+		tail += q.limit1Clause()
 	}
 
 	return
@@ -231,13 +969,73 @@ func (q *Querier) findTail(view string, column string, arg interface{}, limit1 b
 // If there are no rows in result, it returns ErrNoRows. It also may return QueryRow(), Scan()
 // and AfterFinder errors.
 func (q *Querier) FindOneTo(str Struct, column string, arg interface{}) error {
-	tail, needArg := q.findTail(str.View().Name(), column, arg, true)
+	tail, needArg := q.findTail(q.viewName(str.View()), column, arg, true)
 	if needArg {
 		return q.SelectOneTo(str, tail, arg)
 	}
 	return q.SelectOneTo(str, tail)
 }
 
+// FindOneToOK is like FindOneTo, but returns false and nil error instead of ErrNoRows
+// if there are no rows in result. Other errors, including ones returned by AfterFinder, are returned as is.
+func (q *Querier) FindOneToOK(str Struct, column string, arg interface{}) (found bool, err error) {
+	tail, needArg := q.findTail(q.viewName(str.View()), column, arg, true)
+	if needArg {
+		return q.SelectOneToOK(str, tail, arg)
+	}
+	return q.SelectOneToOK(str, tail)
+}
+
+// FindOneToForUpdate is like FindOneTo, but also locks selected row until the end of transaction.
+// It makes sense only inside a transaction.
+func (q *Querier) FindOneToForUpdate(str Struct, column string, arg interface{}) error {
+	tail, needArg := q.findTail(q.viewName(str.View()), column, arg, true)
+	if needArg {
+		return q.SelectOneToForUpdate(str, tail, arg)
+	}
+	return q.SelectOneToForUpdate(str, tail)
+}
+
+// cmpOperators contains comparison operators allowed by FindOneToCmp.
+var cmpOperators = map[string]bool{
+	"=": true, "<>": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "LIKE": true,
+}
+
+// FindOneToCmp queries str's View with "WHERE column op arg ORDER BY orderBy [DESC]" and scans first result to str.
+// Both column and orderBy may be column or field names. Op is one of "=", "<>", "!=", "<", "<=", ">", ">=", "LIKE".
+// It is useful for lookups like "the latest row before given time".
+// If str implements AfterFinder, it also calls AfterFind().
+//
+// If there are no rows in result, it returns ErrNoRows. It also may return QueryRow(), Scan()
+// and AfterFinder errors.
+func (q *Querier) FindOneToCmp(str Struct, column, op string, arg interface{}, orderBy string, desc bool) error {
+	view := str.View()
+	col, ok := view.HasCol(column)
+	if !ok {
+		return fmt.Errorf("reform: unexpected column %s for %s", column, view.Name())
+	}
+	orderCol, ok := view.HasCol(orderBy)
+	if !ok {
+		return fmt.Errorf("reform: unexpected column %s for %s", orderBy, view.Name())
+	}
+	op = strings.ToUpper(op)
+	if !cmpOperators[op] {
+		return fmt.Errorf("reform: unexpected operator %q", op)
+	}
+	if arg == nil {
+		return fmt.Errorf("reform: FindOneToCmp requires non-nil arg")
+	}
+
+	name := q.QuoteIdentifier(q.viewName(view))
+	tail := fmt.Sprintf("WHERE %s.%s %s %s ORDER BY %s.%s",
+		name, q.QuoteIdentifier(col), op, q.Placeholder(1), name, q.QuoteIdentifier(orderCol))
+	if desc {
+		tail += " DESC"
+	}
+	tail += q.limit1Clause()
+	return q.SelectOneTo(str, tail, arg)
+}
+
 func (q *Querier) DsFindOneTo(str Struct, ds *goqu.Dataset) error {
 	return q.DsSelectOneTo(str, ds)
 }
@@ -248,13 +1046,31 @@ func (q *Querier) DsFindOneTo(str Struct, ds *goqu.Dataset) error {
 // If there are no rows in result, it returns nil, ErrNoRows. It also may return QueryRow(), Scan()
 // and AfterFinder errors.
 func (q *Querier) FindOneFrom(view View, column string, arg interface{}) (Struct, error) {
-	tail, needArg := q.findTail(view.Name(), column, arg, true)
+	tail, needArg := q.findTail(q.viewName(view), column, arg, true)
 	if needArg {
 		return q.SelectOneFrom(view, tail, arg)
 	}
 	return q.SelectOneFrom(view, tail)
 }
 
+// FindOrNew queries table with column and arg and returns first result as a new Record and true.
+// If there are no rows in result, it returns a new empty Record from table.NewRecord() and false.
+// If record implements AfterFinder, it also calls AfterFind() for found record.
+//
+// It may return QueryRow(), Scan() and AfterFinder errors. Error is never ErrNoRows.
+func (q *Querier) FindOrNew(table Table, column string, arg interface{}) (Record, bool, error) {
+	record := table.NewRecord()
+	err := q.FindOneTo(record, column, arg)
+	switch err {
+	case nil:
+		return record, true, nil
+	case ErrNoRows:
+		return table.NewRecord(), false, nil
+	default:
+		return nil, false, err
+	}
+}
+
 func (q *Querier) DsFindOneFrom(view View, ds *goqu.Dataset) (Struct, error) {
 	return q.DsSelectOneFrom(view, ds)
 }
@@ -266,7 +1082,7 @@ func (q *Querier) DsFindOneFrom(view View, ds *goqu.Dataset) (Struct, error) {
 //
 // See SelectRows example for idiomatic usage.
 func (q *Querier) FindRows(view View, column string, arg interface{}) (*sql.Rows, error) {
-	tail, needArg := q.findTail(view.Name(), column, arg, false)
+	tail, needArg := q.findTail(q.viewName(view), column, arg, false)
 	if needArg {
 		return q.SelectRows(view, tail, arg)
 	}
@@ -289,6 +1105,24 @@ func (q *Querier) FindAllFrom(view View, column string, args ...interface{}) ([]
 	return q.SelectAllFrom(view, tail, args...)
 }
 
+// FindAllFromOrdered is like FindAllFrom, but also orders results with explicit position of NULL values.
+// See OrderBy for details.
+func (q *Querier) FindAllFromOrdered(view View, column string, orders []Order, args ...interface{}) ([]Struct, error) {
+	p := strings.Join(q.Placeholders(1, len(args)), ", ")
+	qi := q.QualifiedView(view) + "." + q.QuoteIdentifier(column)
+	tail := fmt.Sprintf("WHERE %s IN (%s) %s", qi, p, q.OrderBy(view, orders...))
+	return q.SelectAllFrom(view, tail, args...)
+}
+
+// FindForUpdate is like FindAllFrom, but also locks selected rows until the end of transaction.
+// It makes sense only inside a transaction.
+func (q *Querier) FindForUpdate(view View, column string, args ...interface{}) ([]Struct, error) {
+	p := strings.Join(q.Placeholders(1, len(args)), ", ")
+	qi := q.QualifiedView(view) + "." + q.QuoteIdentifier(column)
+	tail := fmt.Sprintf("WHERE %s IN (%s)", qi, p)
+	return q.SelectForUpdate(view, tail, args...)
+}
+
 func (q *Querier) FindAllFromPK(table Table, args ...interface{}) ([]Struct, error) {
 	if len(args) == 0 {
 		return nil, ErrNoPK
@@ -299,35 +1133,330 @@ func (q *Querier) FindAllFromPK(table Table, args ...interface{}) ([]Struct, err
 	return q.SelectAllFrom(table, tail, args...)
 }
 
+// FindAllFromPKInOrder is like FindAllFromPK, but orders results to match the order of given primary keys
+// with dialect's InputOrderMethod. Rows for missing primary keys are skipped.
+func (q *Querier) FindAllFromPKInOrder(table Table, pks ...interface{}) ([]Struct, error) {
+	if len(pks) == 0 {
+		return nil, ErrNoPK
+	}
+	p := strings.Join(q.Placeholders(1, len(pks)), ", ")
+	qi := q.QualifiedView(table) + "." + q.QuoteIdentifier(table.PK())
+
+	var order string
+	args := make([]interface{}, 0, 2*len(pks))
+	args = append(args, pks...)
+	switch q.InputOrderMethod() {
+	case ArrayPosition:
+		// reuse placeholders typed by IN clause, otherwise array elements are resolved as text
+		order = fmt.Sprintf("array_position(ARRAY[%s], %s)", p, qi)
+	case FieldFunc:
+		order = fmt.Sprintf("FIELD(%s, %s)", qi, strings.Join(q.Placeholders(len(pks)+1, len(pks)), ", "))
+		args = append(args, pks...)
+	case OrderCase:
+		whens := make([]string, len(pks))
+		for i, ph := range q.Placeholders(len(pks)+1, len(pks)) {
+			whens[i] = fmt.Sprintf("WHEN %s THEN %d", ph, i)
+		}
+		order = fmt.Sprintf("CASE %s %s END", qi, strings.Join(whens, " "))
+		args = append(args, pks...)
+	default:
+		panic("reform: Unhandled InputOrderMethod. Please report this bug.")
+	}
+
+	tail := fmt.Sprintf("WHERE %s IN (%s) ORDER BY %s", qi, p, order)
+	return q.SelectAllFrom(table, tail, args...)
+}
+
+// FindMapByPK queries table with primary keys and returns a map of new Records keyed by primary key values.
+// See SelectMap for details.
+func (q *Querier) FindMapByPK(table Table, pks ...interface{}) (map[interface{}]Struct, error) {
+	if len(pks) == 0 {
+		return nil, ErrNoPK
+	}
+	p := strings.Join(q.Placeholders(1, len(pks)), ", ")
+	qi := q.QualifiedView(table) + "." + q.QuoteIdentifier(table.PK())
+	tail := fmt.Sprintf("WHERE %s IN (%s)", qi, p)
+	return q.SelectMap(table, table.PK(), tail, pks...)
+}
+
+// FindAllByPKMap queries table with primary keys and returns a map of new Records keyed by their PKValue(),
+// so keys have primary key field's type. Missing primary keys are not present in the map.
+// Unlike FindMapByPK, it returns an empty map for no primary keys.
+//
+// In case of query error map will be nil. If error is encountered during iteration,
+// partial result and error will be returned. Error is never ErrNoRows.
+func (q *Querier) FindAllByPKMap(table Table, pks ...interface{}) (map[interface{}]Record, error) {
+	if len(pks) == 0 {
+		return make(map[interface{}]Record), nil
+	}
+
+	structs, err := q.FindAllFromPK(table, pks...)
+	if structs == nil && err != nil {
+		return nil, err
+	}
+
+	res := make(map[interface{}]Record, len(structs))
+	for _, str := range structs {
+		record := MustRecord(str)
+		res[record.PKValue()] = record
+	}
+	return res, err
+}
+
 func (q *Querier) DsFindAllFrom(view View, ds *goqu.Dataset) ([]Struct, error) {
 	return q.DsSelectAllFrom(view, ds)
 }
 
+// DsFindAllIn queries view with ds filtered by column (or field) value in values and returns a slice of new Structs.
+// Dataset ds should be created by goqu database for the same SQL dialect, so it handles placeholders and quoting.
+// See SelectAllFrom for details about results.
+func (q *Querier) DsFindAllIn(view View, ds *goqu.Dataset, column string, values []interface{}) ([]Struct, error) {
+	col, ok := view.HasCol(column)
+	if !ok {
+		return nil, fmt.Errorf("reform: unexpected column %s for %s", column, view.Name())
+	}
+
+	return q.DsSelectAllFrom(view, ds.Where(goqu.I(col).In(values...)))
+}
+
+// DsFindByJSON queries view with column (or field) of PostgreSQL's jsonb type filtered by path and value,
+// and returns a slice of new Structs. It is supported only by dialects with "postgres" goqu adapter.
+// Elements of path are object keys; they are joined with "->" operators,
+// and the last one is extracted as text with "->>" operator, like "column -> 'a' -> 'b' ->> 'c'".
+// Extracted text is compared with value formatted with fmt.Sprint; nil value matches missing elements and JSON nulls.
+// See SelectAllFrom for details about results.
+func (q *Querier) DsFindByJSON(view View, column string, path []string, value interface{}) ([]Struct, error) {
+	if q.GoquAdapter() != "postgres" {
+		return nil, fmt.Errorf("reform: DsFindByJSON is not supported by this dialect")
+	}
+	col, ok := view.HasCol(column)
+	if !ok {
+		return nil, fmt.Errorf("reform: unexpected column %s for %s", column, view.Name())
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("reform: DsFindByJSON requires non-empty path")
+	}
+
+	args := make([]interface{}, 0, len(path)+1)
+	args = append(args, goqu.I(col))
+	for _, p := range path {
+		args = append(args, p)
+	}
+	expr := goqu.L("?"+strings.Repeat(" -> ?", len(path)-1)+" ->> ?", args...)
+
+	var cond goqu.Expression
+	if value == nil {
+		cond = expr.IsNull()
+	} else {
+		cond = expr.Eq(fmt.Sprint(value))
+	}
+	return q.DsSelectAllFrom(view, q.Dataset(view).Where(cond))
+}
+
+// pkTail returns a tail of SELECT query for given table and primary key values, and args for it.
+func (q *Querier) pkTail(table Table, pks []interface{}) (tail string, args []interface{}, err error) {
+	indexes := pkColumnIndexes(table)
+	if len(pks) != len(indexes) {
+		err = fmt.Errorf("reform: %s has %d primary key columns, got %d values", table.Name(), len(indexes), len(pks))
+		return
+	}
+
+	columns := table.Columns()
+	view := q.QuoteIdentifier(q.viewName(table))
+	conds := make([]string, len(indexes))
+	for i, index := range indexes {
+		qi := view + "." + q.QuoteIdentifier(columns[index])
+		if pks[i] == nil {
+			conds[i] = qi + " IS NULL"
+			continue
+		}
+		args = append(args, pks[i])
+		conds[i] = qi + " = " + q.Placeholder(len(args))
+	}
+
+	tail = "WHERE " + strings.Join(conds, " AND ") + q.limit1Clause()
+	return
+}
+
 // FindByPrimaryKeyTo queries record's Table with primary key and scans first result to record.
 // If record implements AfterFinder, it also calls AfterFind().
+// If record implements Snapshotter, it also stores a snapshot of loaded values for UpdateChanged.
 //
 // If there are no rows in result, it returns ErrNoRows. It also may return QueryRow(), Scan()
 // and AfterFinder errors.
 func (q *Querier) FindByPrimaryKeyTo(record Record, pk interface{}) error {
+	return q.FindByPrimaryKeysTo(record, pk)
+}
+
+// FindByPrimaryKeysTo is like FindByPrimaryKeyTo, but accepts values of all primary key columns
+// in the order of table's PKColumnIndexes (see CompositeTable).
+// It returns error if the number of values doesn't match the number of primary key columns.
+func (q *Querier) FindByPrimaryKeysTo(record Record, pks ...interface{}) error {
+	tail, args, err := q.pkTail(record.Table(), pks)
+	if err != nil {
+		return err
+	}
+	if err = q.SelectOneTo(record, tail, args...); err != nil {
+		return err
+	}
+	takeSnapshot(record)
+	return nil
+}
+
+// FindByPrimaryKeyToOK is like FindByPrimaryKeyTo, but returns false and nil error instead of ErrNoRows
+// if there are no rows in result. Other errors, including ones returned by AfterFinder, are returned as is.
+func (q *Querier) FindByPrimaryKeyToOK(record Record, pk interface{}) (found bool, err error) {
 	table := record.Table()
-	return q.FindOneTo(record, table.Columns()[table.PKColumnIndex()], pk)
+	found, err = q.FindOneToOK(record, table.Columns()[table.PKColumnIndex()], pk)
+	if found {
+		takeSnapshot(record)
+	}
+	return
 }
 
 // FindByPrimaryKeyFrom queries table with primary key and scans first result to new Record.
+// For tables with composite primary key (see CompositeTable) values of all key columns should be passed.
 // If record implements AfterFinder, it also calls AfterFind().
 //
 // If there are no rows in result, it returns nil, ErrNoRows. It also may return QueryRow(), Scan()
 // and AfterFinder errors.
-func (q *Querier) FindByPrimaryKeyFrom(table Table, pk interface{}) (Record, error) {
-	record := table.NewRecord()
-	err := q.FindOneTo(record, table.Columns()[table.PKColumnIndex()], pk)
+func (q *Querier) FindByPrimaryKeyFrom(table Table, pks ...interface{}) (Record, error) {
+	tail, args, err := q.pkTail(table, pks)
 	if err != nil {
 		return nil, err
 	}
+	record := table.NewRecord()
+	if err = q.SelectOneTo(record, tail, args...); err != nil {
+		return nil, err
+	}
 	return record, nil
 }
 
+// ExistsByPK returns true if table has a row with given primary key.
+// It is cheaper than FindByPrimaryKeyFrom or Reload as no columns are selected and scanned.
+func (q *Querier) ExistsByPK(table Table, pk interface{}) (_ bool, err error) {
+	defer q.observe("select", table, time.Now(), &err)
+
+	command := "SELECT"
+	if q.SelectLimitMethod() == SelectTop {
+		command += " TOP 1"
+	}
+	query := fmt.Sprintf("%s 1 FROM %s WHERE %s = %s",
+		command,
+		q.QualifiedView(table),
+		q.QuoteIdentifier(table.Columns()[table.PKColumnIndex()]),
+		q.Placeholder(1),
+	)
+	query += q.limit1Clause()
+
+	var one int
+	err = q.QueryRow(query, pk).Scan(&one)
+	switch err {
+	case nil:
+		return true, nil
+	case ErrNoRows:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
 // Reload is a shortcut for FindByPrimaryKeyTo for given record.
+// If record implements CompositeRecord, values of all primary key columns are used.
 func (q *Querier) Reload(record Record) error {
+	if cr, ok := record.(CompositeRecord); ok {
+		return q.FindByPrimaryKeysTo(record, cr.PKValues()...)
+	}
 	return q.FindByPrimaryKeyTo(record, record.PKValue())
 }
+
+// Explain returns an execution plan of the same SELECT query SelectAllFrom would run for view with tail and args.
+// Plan rows are joined with newlines, columns of each row with tabs.
+// It returns error if execution plan can't be obtained for this dialect.
+func (q *Querier) Explain(view View, tail string, args ...interface{}) (string, error) {
+	return q.explain(false, view, tail, args...)
+}
+
+// ExplainAnalyze is like Explain, but also executes query to return an actual execution plan,
+// if it is supported by the dialect (like "EXPLAIN ANALYZE" in PostgreSQL). Otherwise, it is the same as Explain.
+func (q *Querier) ExplainAnalyze(view View, tail string, args ...interface{}) (string, error) {
+	return q.explain(true, view, tail, args...)
+}
+
+// explain implements Explain and ExplainAnalyze.
+func (q *Querier) explain(analyze bool, view View, tail string, args ...interface{}) (string, error) {
+	prefix := q.ExplainPrefix(analyze)
+	if prefix == "" {
+		return "", fmt.Errorf("reform: Explain is not supported by this dialect")
+	}
+
+	query := q.selectQuery(view, tail, false, false)
+	if !strings.HasPrefix(prefix, "SET ") {
+		return q.queryPlan(prefix+query, args...)
+	}
+
+	// session option should be set on the same connection and reset after query
+	var plan string
+	err := q.inTransaction(func(q *Querier) error {
+		if _, err := q.Exec(prefix); err != nil {
+			return err
+		}
+
+		var err error
+		plan, err = q.queryPlan(query, args...)
+		if _, e := q.Exec(strings.TrimSuffix(prefix, " ON") + " OFF"); err == nil {
+			err = e
+		}
+		return err
+	})
+	return plan, err
+}
+
+// queryPlan runs query with args and returns all result sets joined together.
+func (q *Querier) queryPlan(query string, args ...interface{}) (plan string, err error) {
+	var rows *sql.Rows
+	rows, err = q.Query(query, args...)
+	if err != nil {
+		return
+	}
+	defer func() {
+		e := rows.Close()
+		if err == nil {
+			err = e
+		}
+	}()
+
+	var columns []string
+	var lines []string
+	for {
+		if columns, err = rows.Columns(); err != nil {
+			return
+		}
+		for rows.Next() {
+			values := make([]sql.NullString, len(columns))
+			dest := make([]interface{}, len(columns))
+			for i := range values {
+				dest[i] = &values[i]
+			}
+			if err = rows.Scan(dest...); err != nil {
+				return
+			}
+
+			line := make([]string, len(values))
+			for i, v := range values {
+				line[i] = v.String
+			}
+			lines = append(lines, strings.Join(line, "\t"))
+		}
+		if err = rows.Err(); err != nil {
+			return
+		}
+
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+
+	plan = strings.Join(lines, "\n")
+	return
+}
@@ -1,13 +1,28 @@
 package reform_test
 
 import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
 	"time"
 
 	"github.com/AlekSi/pointer"
+	"gopkg.in/doug-martin/goqu.v3"
+	_ "gopkg.in/doug-martin/goqu.v3/adapters/mysql"
+	_ "gopkg.in/doug-martin/goqu.v3/adapters/postgres"
 
 	"github.com/empirefox/reform"
+	"github.com/empirefox/reform/dialects/mssql"
+	"github.com/empirefox/reform/dialects/mysql"
 	"github.com/empirefox/reform/dialects/postgresql"
+	"github.com/empirefox/reform/dialects/sqlite3"
 	. "github.com/empirefox/reform/internal/test/models"
+	"github.com/empirefox/reform/parse"
 )
 
 var (
@@ -114,6 +129,394 @@ func (s *ReformSuite) TestSelectAllFrom() {
 	s.NotEqual(reform.ErrNoRows, err)
 }
 
+// emailPerson is a Person which AfterFind fails for people without email.
+type emailPerson struct {
+	*Person
+}
+
+func (p *emailPerson) AfterFind() error {
+	if p.Email == nil {
+		return fmt.Errorf("person %d has no email", p.ID)
+	}
+	return nil
+}
+
+// emailPersonView is a PersonTable which creates emailPersons.
+type emailPersonView struct {
+	reform.View
+}
+
+func (emailPersonView) NewStruct() reform.Struct {
+	return &emailPerson{Person: new(Person)}
+}
+
+func (s *ReformSuite) TestSelectDistinctAllFrom() {
+	fake := new(fakeDB)
+	_, err := reform.NewDBFromInterface(fake, postgresql.Dialect, nil).SelectDistinctAllFrom(PersonProjectView, "WHERE $PersonID = $1", 103)
+	s.Equal(errFake, err)
+	s.Equal([]string{`SELECT DISTINCT "person_project"."person_id", "person_project"."project_id" ` +
+		`FROM "person_project" WHERE person_id = $1`}, fake.queries)
+
+	structs, err := s.q.SelectDistinctAllFrom(PersonProjectView, "WHERE $PersonID = "+s.q.Placeholder(1)+" ORDER BY $ProjectID", 103)
+	s.NoError(err)
+	s.Equal([]reform.Struct{
+		&PersonProject{PersonID: 103, ProjectID: "baron"},
+		&PersonProject{PersonID: 103, ProjectID: "queen"},
+		&PersonProject{PersonID: 103, ProjectID: "traveler"},
+	}, structs)
+}
+
+func (s *ReformSuite) TestSelectDistinctColumn() {
+	values, err := s.q.SelectDistinctColumn(PersonTable, "Name", "WHERE id IN (102, 103)")
+	s.NoError(err)
+	s.Equal([]interface{}{"Elfrieda Abbott"}, values)
+
+	values, err = s.q.SelectDistinctColumn(PersonTable, "group_id", "WHERE id IN (102, 103)")
+	s.NoError(err)
+	s.Equal([]interface{}{pointer.ToInt32(65534)}, values)
+
+	values, err = s.q.SelectDistinctColumn(PersonProjectView, "ProjectID", "WHERE $PersonID > "+s.q.Placeholder(1)+" ORDER BY $ProjectID", 101)
+	s.NoError(err)
+	s.Equal([]interface{}{"baron", "queen", "traveler"}, values)
+
+	values, err = s.q.SelectDistinctColumn(PersonTable, "Name", "WHERE id = -1")
+	s.NoError(err)
+	s.Nil(values)
+
+	_, err = s.q.SelectDistinctColumn(PersonTable, "Nickname", "")
+	s.EqualError(err, "reform: unexpected column Nickname for people")
+}
+
+func (s *ReformSuite) TestSelectAllTolerant() {
+	view := emailPersonView{PersonTable}
+	structs, scanErrors, err := s.q.SelectAllTolerant(view, "WHERE name = "+s.q.Placeholder(1)+" ORDER BY id", "Elfrieda Abbott")
+	s.NoError(err)
+	s.Equal([]reform.Struct{
+		&emailPerson{&Person{ID: 102, GroupID: pointer.ToInt32(65534), Name: "Elfrieda Abbott", Email: pointer.ToString("elfrieda_abbott@example.org"), CreatedAt: personCreated}},
+	}, structs)
+	s.Equal([]error{errors.New("person 103 has no email")}, scanErrors)
+
+	structs, scanErrors, err = s.q.SelectAllTolerant(view, "WHERE id IS NULL")
+	s.Nil(structs)
+	s.Nil(scanErrors)
+	s.NoError(err)
+
+	structs, scanErrors, err = s.q.SelectAllTolerant(view, "WHERE invalid_tail")
+	s.Nil(structs)
+	s.Nil(scanErrors)
+	s.Error(err)
+	s.NotEqual(reform.ErrNoRows, err)
+}
+
+func (s *ReformSuite) TestSelectByColumnNames() {
+	query := "SELECT email, 42 AS extra, name, id FROM people WHERE id IN (" +
+		s.q.Placeholder(1) + ", " + s.q.Placeholder(2) + ") ORDER BY id"
+	structs, err := s.q.SelectByColumnNames(PersonTable, query, 102, 103)
+	s.NoError(err)
+	s.Equal([]reform.Struct{
+		&Person{ID: 102, Name: "Elfrieda Abbott", Email: pointer.ToString("elfrieda_abbott@example.org")},
+		&Person{ID: 103, Name: "Elfrieda Abbott"},
+	}, structs)
+
+	structs, err = s.q.SelectByColumnNames(PersonTable, "SELECT foo FROM bar")
+	s.Error(err)
+	s.Nil(structs)
+
+	// result column names folded by database
+	query = fmt.Sprintf("SELECT name AS %s, id AS %s FROM people WHERE id = %s",
+		s.q.QuoteIdentifier("NAME"), s.q.QuoteIdentifier("ID"), s.q.Placeholder(1))
+	q := reform.NewTXFromInterface(s.q, upperDialect{s.q.Dialect}, nil)
+	structs, err = q.SelectByColumnNames(PersonTable, query, 103)
+	s.NoError(err)
+	s.Equal([]reform.Struct{&Person{ID: 103, Name: "Elfrieda Abbott"}}, structs)
+}
+
+// uncomparableDialect is a Dialect which can't be used as a map key.
+type uncomparableDialect struct {
+	reform.Dialect
+	_ []int
+}
+
+func (s *ReformSuite) TestSelectUncomparableDialect() {
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, uncomparableDialect{Dialect: s.q.Dialect}, nil)
+	for i := 0; i < 2; i++ {
+		_, err := db.SelectAllFrom(PersonTable, "")
+		s.Equal(errFake, err)
+	}
+	s.Require().Len(fake.queries, 2)
+	s.Equal(fake.queries[0], fake.queries[1])
+
+	// renamed view is not cached
+	q, err := db.WithTableName(PersonTable, "people_2025")
+	s.Require().NoError(err)
+	_, err = q.SelectAllFrom(PersonTable, "")
+	s.Equal(errFake, err)
+	s.Contains(fake.queries[2], s.q.QuoteIdentifier("people_2025")+"."+s.q.QuoteIdentifier("id"))
+	_, err = db.SelectAllFrom(PersonTable, "")
+	s.Equal(errFake, err)
+	s.Equal(fake.queries[0], fake.queries[3])
+}
+
+// upperDialect is a Dialect which folds identifiers to upper case, like DB2 does.
+type upperDialect struct {
+	reform.Dialect
+}
+
+func (upperDialect) FoldIdentifier(identifier string) string {
+	return strings.ToUpper(identifier)
+}
+
+func (s *ReformSuite) TestSelectJoined() {
+	columns := append(s.q.QualifiedColumns(PersonTable), s.q.QualifiedColumns(PersonProjectView)...)
+	query := fmt.Sprintf("SELECT %s FROM %s JOIN %s ON %s.%s = %s.%s WHERE %s.%s = %s ORDER BY %s.%s",
+		strings.Join(columns, ", "), s.q.QualifiedView(PersonTable), s.q.QualifiedView(PersonProjectView),
+		s.q.QualifiedView(PersonTable), s.q.QuoteIdentifier("id"),
+		s.q.QualifiedView(PersonProjectView), s.q.QuoteIdentifier("person_id"),
+		s.q.QualifiedView(PersonTable), s.q.QuoteIdentifier("id"), s.q.Placeholder(1),
+		s.q.QualifiedView(PersonProjectView), s.q.QuoteIdentifier("project_id"))
+	res, err := s.q.SelectJoined(query, []interface{}{103}, new(Person), new(PersonProject))
+	s.NoError(err)
+	person := &Person{ID: 103, GroupID: pointer.ToInt32(65534), Name: "Elfrieda Abbott", CreatedAt: personCreated}
+	s.Equal([][]reform.Struct{
+		{person, &PersonProject{PersonID: 103, ProjectID: "baron"}},
+		{person, &PersonProject{PersonID: 103, ProjectID: "queen"}},
+		{person, &PersonProject{PersonID: 103, ProjectID: "traveler"}},
+	}, res)
+
+	res, err = s.q.SelectJoined(query, []interface{}{103}, new(Person))
+	s.Nil(res)
+	s.EqualError(err, "reform: SelectJoined query returns 8 columns, targets have 6")
+
+	res, err = s.q.SelectJoined(query, []interface{}{1}, new(Person), new(PersonProject))
+	s.Nil(res)
+	s.NoError(err)
+}
+
+func (s *ReformSuite) TestSelectAllFromCTE() {
+	with := "queen AS (SELECT person_id FROM person_project WHERE project_id = " + s.q.Placeholder(1) + ")"
+	tail := "WHERE $ID IN (SELECT person_id FROM queen) AND $Name = " + s.q.Placeholder(2) + " ORDER BY $ID"
+	structs, err := s.q.SelectAllFromCTE(PersonTable, with, tail, "queen", "Elfrieda Abbott")
+	s.NoError(err)
+	s.Equal([]reform.Struct{
+		&Person{ID: 102, GroupID: pointer.ToInt32(65534), Name: "Elfrieda Abbott", Email: pointer.ToString("elfrieda_abbott@example.org"), CreatedAt: personCreated},
+		&Person{ID: 103, GroupID: pointer.ToInt32(65534), Name: "Elfrieda Abbott", CreatedAt: personCreated},
+	}, structs)
+}
+
+func (s *ReformSuite) TestSelectAllAndCount() {
+	tail := "WHERE name = " + s.q.Placeholder(1) + " ORDER BY id DESC"
+	structs, total, err := s.q.SelectAllAndCount(PersonTable, tail, 1, 0, "Elfrieda Abbott")
+	s.NoError(err)
+	s.Equal(uint64(2), total)
+	s.Equal([]reform.Struct{
+		&Person{ID: 103, GroupID: pointer.ToInt32(65534), Name: "Elfrieda Abbott", CreatedAt: personCreated},
+	}, structs)
+
+	structs, total, err = s.q.SelectAllAndCount(PersonTable, tail, 5, 1, "Elfrieda Abbott")
+	s.NoError(err)
+	s.Equal(uint64(2), total)
+	s.Len(structs, 1)
+	s.Equal(int32(102), structs[0].(*Person).ID)
+
+	structs, total, err = s.q.SelectAllAndCount(PersonTable, tail, 0, 0, "Elfrieda Abbott")
+	s.NoError(err)
+	s.Equal(uint64(2), total)
+	s.Nil(structs)
+
+	structs, total, err = s.q.SelectAllAndCount(PersonTable, tail, 5, 2, "Elfrieda Abbott")
+	s.NoError(err)
+	s.Equal(uint64(2), total)
+	s.Nil(structs)
+
+	// lowercase ORDER BY with sub-query in WHERE
+	structs, total, err = s.q.SelectAllAndCount(PersonTable, "WHERE id IN (SELECT person_id FROM person_project WHERE project_id = "+
+		s.q.Placeholder(1)+")  order\tby id", 10, 0, "traveler")
+	s.NoError(err)
+	s.Equal(uint64(1), total)
+	s.Len(structs, 1)
+}
+
+func (s *ReformSuite) TestSelectFetchFirst() {
+	fake := &queryRowDB{db: s.q}
+	db := reform.NewDBFromInterface(fake, fetchFirstDialect{s.q.Dialect}, nil)
+
+	tail := "WHERE name = " + s.q.Placeholder(1) + " ORDER BY id DESC"
+	_, total, err := db.SelectAllAndCount(PersonTable, tail, 1, 1, "Elfrieda Abbott")
+	s.Equal(errFake, err)
+	s.Equal(uint64(2), total)
+	s.Require().Len(fake.queries, 2)
+	s.True(strings.HasSuffix(fake.queries[1], " ORDER BY id DESC OFFSET 1 ROWS FETCH NEXT 1 ROWS ONLY"), "%s", fake.queries[1])
+
+	fake.queries = nil
+	person, err := db.FindOneFrom(PersonTable, "name", "Elfrieda Abbott")
+	s.Require().Len(fake.queries, 1)
+	s.True(strings.HasSuffix(fake.queries[0], " = "+s.q.Placeholder(1)+" FETCH FIRST 1 ROW ONLY"), "%s", fake.queries[0])
+	if s.q.Dialect == postgresql.Dialect {
+		s.NoError(err)
+		s.Equal("Elfrieda Abbott", person.(*Person).Name)
+	}
+}
+
+func (s *ReformSuite) TestSelectAllFromWithMaps() {
+	structs, maps, err := s.q.SelectAllFromWithMaps(PersonTable, "WHERE id IN (102, 103) ORDER BY id")
+	s.NoError(err)
+	s.Require().Len(structs, 2)
+	s.Require().Len(maps, 2)
+	s.Equal(map[string]interface{}{
+		"id":         int32(102),
+		"group_id":   pointer.ToInt32(65534),
+		"name":       "Elfrieda Abbott",
+		"email":      pointer.ToString("elfrieda_abbott@example.org"),
+		"created_at": personCreated,
+		"updated_at": (*time.Time)(nil),
+	}, maps[0])
+	for i, str := range structs {
+		for j, c := range PersonTable.Columns() {
+			s.Equal(str.Values()[j], maps[i][c])
+		}
+	}
+
+	structs, maps, err = s.q.SelectAllFromWithMaps(PersonTable, "WHERE id = 424242")
+	s.NoError(err)
+	s.Nil(structs)
+	s.Nil(maps)
+
+	structs, maps, err = s.q.SelectAllFromWithMaps(PersonTable, "WHERE invalid_column = 1")
+	s.Error(err)
+	s.Nil(structs)
+	s.Nil(maps)
+}
+
+func (s *ReformSuite) TestSelectAllFromAs() {
+	parent := &Person{Name: "parent"}
+	s.Require().NoError(s.q.Insert(parent))
+	children := []*Person{
+		{GroupID: pointer.ToInt32(parent.ID), Name: "child 1"},
+		{GroupID: pointer.ToInt32(parent.ID), Name: "child 2"},
+	}
+	for _, child := range children {
+		s.Require().NoError(s.q.Insert(child))
+	}
+
+	childID := s.q.QuoteIdentifier("child") + "." + s.q.QuoteIdentifier("id")
+	tail := fmt.Sprintf("JOIN %s AS %s ON %s.%s = %s.%s WHERE %s.%s = %s ORDER BY %s",
+		s.q.QualifiedView(PersonTable), s.q.QuoteIdentifier("parent"),
+		s.q.QuoteIdentifier("parent"), s.q.QuoteIdentifier("id"),
+		s.q.QuoteIdentifier("child"), s.q.QuoteIdentifier("group_id"),
+		s.q.QuoteIdentifier("parent"), s.q.QuoteIdentifier("name"), s.q.Placeholder(1),
+		childID)
+	structs, err := s.q.SelectAllFromAs(PersonTable, "child", tail, parent.Name)
+	s.NoError(err)
+	s.Require().Len(structs, 2)
+	for i, str := range structs {
+		s.Equal(children[i].ID, str.(*Person).ID)
+		s.Equal(children[i].Name, str.(*Person).Name)
+	}
+}
+
+func (s *ReformSuite) TestSelectAllNamed() {
+	args := map[string]interface{}{"name": "Elfrieda Abbott", "unused": 42}
+	tail, a, err := s.q.NamedTail("WHERE name = :name OR email = :name ORDER BY id", args)
+	s.NoError(err)
+	if s.q.Dialect == postgresql.Dialect {
+		s.Equal("WHERE name = $1 OR email = $1 ORDER BY id", tail)
+		s.Equal([]interface{}{"Elfrieda Abbott"}, a)
+	} else {
+		s.Equal([]interface{}{"Elfrieda Abbott", "Elfrieda Abbott"}, a)
+	}
+
+	structs, err := s.q.SelectAllNamed(PersonTable, "WHERE name = :name AND name = :name ORDER BY id", args)
+	s.NoError(err)
+	s.Len(structs, 2)
+
+	structs, err = s.q.SelectAllNamed(PersonTable, "WHERE name = :no_such_name", args)
+	s.Nil(structs)
+	s.EqualError(err, "reform: missing named argument: no_such_name")
+
+	// markers in string literals and quoted identifiers are left as is
+	tail = fmt.Sprintf("WHERE %s = :name OR %s = 'a:b' OR %s = 'it''s :name'",
+		s.q.QuoteIdentifier("name"), s.q.QuoteIdentifier("name"), s.q.QuoteIdentifier("name"))
+	replaced, a, err := s.q.NamedTail(tail, args)
+	s.NoError(err)
+	s.Equal(strings.Replace(tail, ":name", s.q.Placeholder(1), 1), replaced)
+	s.Equal([]interface{}{"Elfrieda Abbott"}, a)
+
+	tail, a, err = s.q.NamedTail("WHERE "+s.q.QuoteIdentifier("a:b")+" = :name", args)
+	s.NoError(err)
+	s.Equal("WHERE "+s.q.QuoteIdentifier("a:b")+" = "+s.q.Placeholder(1), tail)
+	s.Equal([]interface{}{"Elfrieda Abbott"}, a)
+
+	structs, err = s.q.SelectAllNamed(PersonTable, "WHERE name = :name OR name = 'a:b'", args)
+	s.NoError(err)
+	s.Len(structs, 2)
+}
+
+func (s *ReformSuite) TestNamedArgsTail() {
+	args := map[string]interface{}{"name": "Elfrieda Abbott", "unused": 42}
+	tail, a, err := s.q.NamedArgsTail("WHERE name = @name OR email = @name ORDER BY id", args)
+	s.NoError(err)
+	switch {
+	case s.q.NamedArgMethod() == reform.AtNamedArgs:
+		s.Equal("WHERE name = @name OR email = @name ORDER BY id", tail)
+		s.Equal([]interface{}{sql.Named("name", "Elfrieda Abbott")}, a)
+	case s.q.Dialect == postgresql.Dialect:
+		s.Equal("WHERE name = $1 OR email = $1 ORDER BY id", tail)
+		s.Equal([]interface{}{"Elfrieda Abbott"}, a)
+	default:
+		s.Equal([]interface{}{"Elfrieda Abbott", "Elfrieda Abbott"}, a)
+	}
+	structs, err := s.q.SelectAllFrom(PersonTable, tail, a...)
+	s.NoError(err)
+	s.Len(structs, 2)
+
+	_, _, err = s.q.NamedArgsTail("WHERE name = @no_such_name", args)
+	s.EqualError(err, "reform: missing named argument: no_such_name")
+
+	// stored procedure call with two named arguments
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, mssql.Dialect, nil)
+	query, a, err := db.NamedArgsTail("EXEC create_person @name, @group_id; SELECT @@ROWCOUNT",
+		map[string]interface{}{"name": "Named", "group_id": 42})
+	s.NoError(err)
+	s.Equal("EXEC create_person @name, @group_id; SELECT @@ROWCOUNT", query)
+	s.Equal([]interface{}{sql.Named("name", "Named"), sql.Named("group_id", 42)}, a)
+	_, err = db.Exec(query, a...)
+	s.Equal(errFake, err)
+	s.Equal([]string{query}, fake.queries)
+
+	db = reform.NewDBFromInterface(fake, postgresql.Dialect, nil)
+	query, a, err = db.NamedArgsTail("SELECT create_person(@name, @group_id)", map[string]interface{}{"name": "Named", "group_id": 42})
+	s.NoError(err)
+	s.Equal("SELECT create_person($1, $2)", query)
+	s.Equal([]interface{}{"Named", 42}, a)
+}
+
+func (s *ReformSuite) TestSelectForUpdate() {
+	structs, err := s.q.SelectForUpdate(PersonTable, "WHERE name = "+s.q.Placeholder(1)+" ORDER BY id", "Elfrieda Abbott")
+	s.NoError(err)
+	s.Len(structs, 2)
+
+	var person Person
+	err = s.q.FindOneToForUpdate(&person, "id", 102)
+	s.NoError(err)
+	s.Equal(structs[0], &person)
+
+	for dialect, expected := range map[reform.Dialect]string{
+		postgresql.Dialect: `SELECT "people"."id", "people"."group_id", "people"."name", "people"."email", "people"."created_at", "people"."updated_at" ` +
+			`FROM "people" WHERE id = 1 FOR UPDATE`,
+		mssql.Dialect: `SELECT [people].[id], [people].[group_id], [people].[name], [people].[email], [people].[created_at], [people].[updated_at] ` +
+			`FROM [people] WITH (UPDLOCK) WHERE id = 1`,
+	} {
+		fake := new(fakeDB)
+		db := reform.NewDBFromInterface(fake, dialect, nil)
+		structs, err = db.SelectForUpdate(PersonTable, "WHERE id = 1")
+		s.Nil(structs)
+		s.Equal(errFake, err)
+		s.Equal([]string{expected}, fake.queries)
+	}
+}
+
 func (s *ReformSuite) TestFindOneTo() {
 	var person Person
 	err := s.q.FindOneTo(&person, "id", 102)
@@ -139,6 +542,32 @@ func (s *ReformSuite) TestFindOneTo() {
 	s.NotEqual(reform.ErrNoRows, err)
 }
 
+func (s *ReformSuite) TestFindOneToCmp() {
+	cutoff := time.Date(2016, 1, 20, 0, 0, 0, 0, time.UTC)
+	var project Project
+	err := s.q.FindOneToCmp(&project, "start", "<=", cutoff, "Start", true)
+	s.NoError(err)
+	s.Equal(Project{ID: "queen", Name: "Thirsty Queen", Start: queenStart}, project)
+
+	err = s.q.FindOneToCmp(&project, "start", "<=", cutoff, "start", false)
+	s.NoError(err)
+	s.Equal("baron", project.ID)
+
+	err = s.q.FindOneToCmp(&project, "start", "<", time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), "start", true)
+	s.Equal(reform.ErrNoRows, err)
+
+	s.EqualError(s.q.FindOneToCmp(&project, "foo", "<", cutoff, "start", true), "reform: unexpected column foo for projects")
+	s.EqualError(s.q.FindOneToCmp(&project, "start", "<", cutoff, "foo", true), "reform: unexpected column foo for projects")
+	s.EqualError(s.q.FindOneToCmp(&project, "start", "; DROP", cutoff, "start", true), `reform: unexpected operator "; DROP"`)
+	s.EqualError(s.q.FindOneToCmp(&project, "start", "<", nil, "start", true), "reform: FindOneToCmp requires non-nil arg")
+
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, postgresql.Dialect, nil)
+	s.Panics(func() { _ = db.FindOneToCmp(&project, "start", "<=", cutoff, "start", true) })
+	s.Equal([]string{`SELECT "projects"."name", "projects"."id", "projects"."start", "projects"."end" FROM "projects" ` +
+		`WHERE "projects"."start" <= $1 ORDER BY "projects"."start" DESC LIMIT 1`}, fake.queries)
+}
+
 func (s *ReformSuite) TestFindOneFrom() {
 	person, err := s.q.FindOneFrom(PersonTable, "id", 102)
 	s.NoError(err)
@@ -161,6 +590,24 @@ func (s *ReformSuite) TestFindOneFrom() {
 	s.NotEqual(reform.ErrNoRows, err)
 }
 
+func (s *ReformSuite) TestFindOrNew() {
+	project, found, err := s.q.FindOrNew(ProjectTable, "id", "queen")
+	s.NoError(err)
+	s.True(found)
+	s.Equal(&Project{ID: "queen", Name: "Thirsty Queen", Start: queenStart}, project)
+
+	project, found, err = s.q.FindOrNew(ProjectTable, "id", "no_such_project")
+	s.NoError(err)
+	s.False(found)
+	s.Equal(&Project{}, project)
+
+	project, found, err = s.q.FindOrNew(ProjectTable, "invalid_column", nil)
+	s.Nil(project)
+	s.False(found)
+	s.Error(err)
+	s.NotEqual(reform.ErrNoRows, err)
+}
+
 func (s *ReformSuite) TestFindRows() {
 	rows, err := s.q.FindRows(PersonTable, "name", "Elfrieda Abbott")
 	s.NotNil(rows)
@@ -225,6 +672,65 @@ func (s *ReformSuite) TestFindAllFrom() {
 	s.NotEqual(reform.ErrNoRows, err)
 }
 
+func (s *ReformSuite) TestFindAllFromPKInOrder() {
+	pks := []interface{}{103, 1, -1, 102}
+	structs, err := s.q.FindAllFromPKInOrder(PersonTable, pks...)
+	s.NoError(err)
+	ids := make([]int32, len(structs))
+	for i, str := range structs {
+		ids[i] = str.(*Person).ID
+	}
+	s.Equal([]int32{103, 1, 102}, ids)
+	s.Equal([]interface{}{103, 1, -1, 102}, pks)
+
+	_, err = s.q.FindAllFromPKInOrder(PersonTable)
+	s.Equal(reform.ErrNoPK, err)
+
+	for dialect, expected := range map[reform.Dialect]string{
+		postgresql.Dialect: `ORDER BY array_position(ARRAY[$1, $2], "people"."id")`,
+		mysql.Dialect:      "ORDER BY FIELD(`people`.`id`, ?, ?)",
+		sqlite3.Dialect:    `ORDER BY CASE "people"."id" WHEN ? THEN 0 WHEN ? THEN 1 END`,
+	} {
+		fake := new(fakeDB)
+		_, err = reform.NewDBFromInterface(fake, dialect, nil).FindAllFromPKInOrder(PersonTable, 2, 1)
+		s.Equal(errFake, err)
+		s.Require().Len(fake.queries, 1)
+		s.True(strings.HasSuffix(fake.queries[0], expected), "%s", fake.queries[0])
+	}
+}
+
+func (s *ReformSuite) TestFindAllFromOrdered() {
+	ids := func(structs []reform.Struct) []int32 {
+		res := make([]int32, len(structs))
+		for i, str := range structs {
+			res[i] = str.(*Person).ID
+		}
+		return res
+	}
+
+	for _, c := range []struct {
+		order    reform.Order
+		expected []int32
+	}{
+		{reform.Order{Column: "Email"}, []int32{102, 103}},
+		{reform.Order{Column: "Email", Nulls: reform.NullsFirst}, []int32{103, 102}},
+		{reform.Order{Column: "Email", Desc: true}, []int32{103, 102}},
+		{reform.Order{Column: "Email", Desc: true, Nulls: reform.NullsLast}, []int32{102, 103}},
+	} {
+		structs, err := s.q.FindAllFromOrdered(PersonTable, "name", []reform.Order{c.order}, "Elfrieda Abbott")
+		s.NoError(err)
+		s.Equal(c.expected, ids(structs), "%+v", c.order)
+	}
+
+	s.Equal("", s.q.OrderBy(PersonTable))
+	col := s.q.QualifiedView(PersonTable) + "." + s.q.QuoteIdentifier("email")
+	expected := "ORDER BY " + col + " DESC NULLS LAST"
+	if s.q.NullsOrderingMethod() == reform.NullsCase {
+		expected = "ORDER BY CASE WHEN " + col + " IS NULL THEN 1 ELSE 0 END, " + col + " DESC"
+	}
+	s.Equal(expected, s.q.OrderBy(PersonTable, reform.Order{Column: "email", Desc: true, Nulls: reform.NullsLast}))
+}
+
 func (s *ReformSuite) TestFindByPrimaryKeyTo() {
 	var person Person
 	err := s.q.FindByPrimaryKeyTo(&person, 1)
@@ -242,6 +748,43 @@ func (s *ReformSuite) TestFindByPrimaryKeyTo() {
 	s.Equal(reform.ErrNoRows, err)
 }
 
+// noRowsPerson is a Person which AfterFind returns ErrNoRows.
+type noRowsPerson struct {
+	*Person
+}
+
+func (p *noRowsPerson) AfterFind() error {
+	return reform.ErrNoRows
+}
+
+func (s *ReformSuite) TestFindOneToOK() {
+	var person Person
+	found, err := s.q.SelectOneToOK(&person, "WHERE id = "+s.q.Placeholder(1), 1)
+	s.NoError(err)
+	s.True(found)
+	s.Equal("Denis Mills", person.Name)
+
+	found, err = s.q.FindOneToOK(&person, "id", -1)
+	s.NoError(err)
+	s.False(found)
+
+	var project Project
+	found, err = s.q.FindByPrimaryKeyToOK(&project, "baron")
+	s.NoError(err)
+	s.True(found)
+	s.Equal("Vicious Baron", project.Name)
+
+	found, err = s.q.FindByPrimaryKeyToOK(&project, nil)
+	s.NoError(err)
+	s.False(found)
+	s.Equal("Vicious Baron", project.Name) // expect old value
+
+	// hook errors are not treated as missing rows
+	found, err = s.q.FindOneToOK(&noRowsPerson{Person: new(Person)}, "id", 1)
+	s.Equal(reform.ErrNoRows, err)
+	s.False(found)
+}
+
 func (s *ReformSuite) TestFindByPrimaryKeyFrom() {
 	person, err := s.q.FindByPrimaryKeyFrom(PersonTable, 1)
 	s.NoError(err)
@@ -256,6 +799,84 @@ func (s *ReformSuite) TestFindByPrimaryKeyFrom() {
 	s.Equal(reform.ErrNoRows, err)
 }
 
+// personProjectTable is PersonProjectView with composite primary key (person_id, project_id).
+type personProjectTable struct {
+	reform.View
+}
+
+func (personProjectTable) NewStruct() reform.Struct { return new(personProjectRecord) }
+func (personProjectTable) NewRecord() reform.Record { return new(personProjectRecord) }
+func (personProjectTable) PKColumnIndex() uint      { return 0 }
+func (personProjectTable) PK() string               { return "person_id" }
+func (personProjectTable) PKColumnIndexes() []uint  { return []uint{0, 1} }
+
+var personProjectTableWithPK = personProjectTable{PersonProjectView}
+
+// personProjectRecord is PersonProject record with composite primary key.
+type personProjectRecord struct {
+	PersonProject
+}
+
+func (r *personProjectRecord) View() reform.View       { return personProjectTableWithPK }
+func (r *personProjectRecord) Table() reform.Table     { return personProjectTableWithPK }
+func (r *personProjectRecord) PKValue() interface{}    { return r.PersonID }
+func (r *personProjectRecord) PKPointer() interface{}  { return &r.PersonID }
+func (r *personProjectRecord) HasPK() bool             { return r.PersonID != 0 && r.ProjectID != "" }
+func (r *personProjectRecord) SetPK(pk interface{})    { r.PersonID = pk.(int32) }
+func (r *personProjectRecord) PKValues() []interface{} { return []interface{}{r.PersonID, r.ProjectID} }
+
+var (
+	_ reform.CompositeTable  = personProjectTableWithPK
+	_ reform.CompositeRecord = new(personProjectRecord)
+)
+
+func (s *ReformSuite) TestFindByPrimaryKeysTo() {
+	var record personProjectRecord
+	err := s.q.FindByPrimaryKeysTo(&record, int32(102), "queen")
+	s.NoError(err)
+	s.Equal(PersonProject{PersonID: 102, ProjectID: "queen"}, record.PersonProject)
+
+	err = s.q.FindByPrimaryKeysTo(&record, int32(101), "queen")
+	s.Equal(reform.ErrNoRows, err)
+
+	err = s.q.FindByPrimaryKeyTo(&record, int32(102))
+	s.EqualError(err, "reform: person_project has 2 primary key columns, got 1 values")
+
+	rec, err := s.q.FindByPrimaryKeyFrom(personProjectTableWithPK, int32(103), "traveler")
+	s.NoError(err)
+	s.Equal(&personProjectRecord{PersonProject{PersonID: 103, ProjectID: "traveler"}}, rec)
+
+	rec, err = s.q.FindByPrimaryKeyFrom(personProjectTableWithPK, int32(103))
+	s.Nil(rec)
+	s.EqualError(err, "reform: person_project has 2 primary key columns, got 1 values")
+
+	record = personProjectRecord{PersonProject{PersonID: 101, ProjectID: "baron"}}
+	s.NoError(s.q.Reload(&record))
+	record.ProjectID = "traveler"
+	s.Equal(reform.ErrNoRows, s.q.Reload(&record))
+
+	_, err = s.q.FindByPrimaryKeyFrom(PersonTable, 1, 2)
+	s.EqualError(err, "reform: people has 1 primary key columns, got 2 values")
+}
+
+func (s *ReformSuite) TestExistsByPK() {
+	exists, err := s.q.ExistsByPK(PersonTable, 1)
+	s.NoError(err)
+	s.True(exists)
+
+	exists, err = s.q.ExistsByPK(ProjectTable, "baron")
+	s.NoError(err)
+	s.True(exists)
+
+	exists, err = s.q.ExistsByPK(PersonTable, -1)
+	s.NoError(err)
+	s.False(exists)
+
+	exists, err = s.q.ExistsByPK(ProjectTable, "no_such_project")
+	s.NoError(err)
+	s.False(exists)
+}
+
 func (s *ReformSuite) TestReload() {
 	person := Person{ID: 1}
 	err := s.q.Reload(&person)
@@ -292,3 +913,433 @@ func (s *ReformSuite) TestSelectsSchema() {
 		&LegacyPerson{ID: 1003, Name: pointer.ToString("Dena Cummings")},
 	}, structs)
 }
+
+func (s *ReformSuite) TestSelectAllFromIn() {
+	for dialect, expected := range map[reform.Dialect]string{
+		mysql.Dialect:      "WHERE id IN (?, ?, ?) AND name <> ? AND id NOT IN (NULL)",
+		postgresql.Dialect: "WHERE id = ANY($1) AND name <> $2 AND id <> ALL($3)",
+	} {
+		fake := new(fakeDB)
+		db := reform.NewDBFromInterface(fake, dialect, nil)
+		tail := fmt.Sprintf("WHERE id IN (%s) AND name <> %s AND id NOT IN (%s)",
+			dialect.Placeholder(1), dialect.Placeholder(2), dialect.Placeholder(3))
+		_, err := db.SelectAllFromIn(PersonTable, tail, []int64{1, 2, 3}, "Alice", []int64{})
+		s.Equal(errFake, err)
+		s.Require().Len(fake.queries, 1)
+		s.True(strings.HasSuffix(fake.queries[0], expected), "%s", fake.queries[0])
+	}
+
+	tail := fmt.Sprintf("WHERE id IN (%s) AND name <> %s ORDER BY id", s.q.Placeholder(1), s.q.Placeholder(2))
+	structs, err := s.q.SelectAllFromIn(PersonTable, tail, []int64{1, 102, 103}, "Elfrieda Abbott")
+	s.NoError(err)
+	s.Require().Len(structs, 1)
+	s.Equal(int32(1), structs[0].(*Person).ID)
+
+	structs, err = s.q.SelectAllFromIn(PersonTable, tail, []int64{}, "Denis Mills")
+	s.NoError(err)
+	s.Len(structs, 0)
+}
+
+func (s *ReformSuite) TestSliceArgsArray() {
+	db := reform.NewDBFromInterface(new(fakeDB), postgresql.Dialect, nil)
+	times := []time.Time{personCreated, time.Date(2014, 1, 1, 3, 0, 0, 5e8, time.FixedZone("UTC+3", 3*60*60))}
+	strs := []sql.NullString{{String: `a"b`, Valid: true}, {}}
+	tail, args := db.SliceArgs("WHERE created_at IN ($1) AND name IN ($2)", []interface{}{times, strs})
+	s.Equal("WHERE created_at = ANY($1) AND name = ANY($2)", tail)
+	s.Require().Len(args, 2)
+
+	v, err := args[0].(driver.Valuer).Value()
+	s.NoError(err)
+	s.Equal(`{"2014-01-01T00:00:00Z","2014-01-01T03:00:00.5+03:00"}`, v)
+	v, err = args[1].(driver.Valuer).Value()
+	s.NoError(err)
+	s.Equal(`{"a\"b",NULL}`, v)
+}
+
+func (s *ReformSuite) TestSelectMap() {
+	m, err := s.q.SelectMap(PersonTable, "Email", "WHERE name = "+s.q.Placeholder(1), "Elfrieda Abbott")
+	s.NoError(err)
+	s.Equal(map[interface{}]reform.Struct{
+		"elfrieda_abbott@example.org": &Person{ID: 102, GroupID: pointer.ToInt32(65534), Name: "Elfrieda Abbott", Email: pointer.ToString("elfrieda_abbott@example.org"), CreatedAt: personCreated},
+		nil:                           &Person{ID: 103, GroupID: pointer.ToInt32(65534), Name: "Elfrieda Abbott", CreatedAt: personCreated},
+	}, m)
+
+	// last wins
+	m, err = s.q.SelectMap(PersonTable, "name", "WHERE name = "+s.q.Placeholder(1)+" ORDER BY id", "Elfrieda Abbott")
+	s.NoError(err)
+	s.Len(m, 1)
+	s.Equal(int32(103), m["Elfrieda Abbott"].(*Person).ID)
+
+	m, err = s.q.SelectMap(PersonTable, "invalid_column", "")
+	s.Nil(m)
+	s.EqualError(err, "reform: unexpected columns: [invalid_column]")
+
+	m, err = s.q.FindMapByPK(PersonTable, 102, 103)
+	s.NoError(err)
+	s.Len(m, 2)
+	s.Equal("elfrieda_abbott@example.org", *m[int32(102)].(*Person).Email)
+	s.Nil(m[int32(103)].(*Person).Email)
+
+	m, err = s.q.FindMapByPK(PersonTable)
+	s.Nil(m)
+	s.Equal(reform.ErrNoPK, err)
+}
+
+func (s *ReformSuite) TestFindAllByPKMap() {
+	m, err := s.q.FindAllByPKMap(PersonTable, 1, 102, -1)
+	s.NoError(err)
+	s.Len(m, 2)
+	s.Equal("Denis Mills", m[int32(1)].(*Person).Name)
+	s.Equal("elfrieda_abbott@example.org", *m[int32(102)].(*Person).Email)
+	s.NotContains(m, int32(-1))
+
+	m, err = s.q.FindAllByPKMap(PersonTable)
+	s.NoError(err)
+	s.NotNil(m)
+	s.Empty(m)
+}
+
+// extraPerson is a Person which receives extra result columns in AfterFindRows.
+type extraPerson struct {
+	*Person
+	extra map[string]interface{}
+}
+
+func (p *extraPerson) AfterFindRows(extra map[string]interface{}) error {
+	p.extra = extra
+	return p.Person.AfterFind()
+}
+
+func (s *ReformSuite) TestAfterFindRows() {
+	query := fmt.Sprintf("SELECT %s, 'baron' AS %s FROM %s WHERE %s = %s",
+		strings.Join(s.q.QualifiedColumns(PersonTable), ", "), s.q.QuoteIdentifier("project_id"),
+		s.q.QualifiedView(PersonTable), s.q.QuoteIdentifier("id"), s.q.Placeholder(1))
+	rows, err := s.q.Query(query, 102)
+	s.Require().NoError(err)
+	defer rows.Close()
+
+	person := &extraPerson{Person: new(Person)}
+	err = s.q.NextRow(person, rows)
+	s.Require().NoError(err)
+	s.Equal("Elfrieda Abbott", person.Name)
+	s.Require().Len(person.extra, 1)
+	s.Equal("baron", fmt.Sprintf("%s", person.extra["project_id"]))
+
+	err = s.q.NextRow(person, rows)
+	s.Equal(reform.ErrNoRows, err)
+
+	person = &extraPerson{Person: new(Person)}
+	err = s.q.FindOneTo(person, "id", 102)
+	s.NoError(err)
+	s.Equal("Elfrieda Abbott", person.Name)
+	s.Nil(person.extra)
+}
+
+func (s *ReformSuite) TestSelectAllReuse() {
+	var first reform.Struct
+	var ids []int32
+	err := s.q.SelectAllReuse(PersonTable, func(str reform.Struct) error {
+		if first == nil {
+			first = str
+		}
+		s.True(first == str)
+		ids = append(ids, str.(*Person).ID)
+		return nil
+	}, "ORDER BY id")
+	s.NoError(err)
+	s.Equal([]int32{1, 2, 101, 102, 103}, ids)
+
+	ids = nil
+	err = s.q.SelectAllReuse(PersonTable, func(str reform.Struct) error {
+		ids = append(ids, str.(*Person).ID)
+		return errors.New("epic error")
+	}, "ORDER BY id")
+	s.EqualError(err, "epic error")
+	s.Equal([]int32{1}, ids)
+}
+
+func (s *ReformSuite) TestExportCSV() {
+	var buf bytes.Buffer
+	err := s.q.ExportCSV(&buf, PersonTable, "WHERE id IN (1, 102) ORDER BY id")
+	s.NoError(err)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	s.NoError(err)
+	s.Equal([][]string{
+		{"id", "group_id", "name", "email", "created_at", "updated_at"},
+		{"1", "65534", "Denis Mills", "", "2009-11-10T23:00:00Z", ""},
+		{"102", "65534", "Elfrieda Abbott", "elfrieda_abbott@example.org", "2014-01-01T00:00:00Z", ""},
+	}, records)
+
+	buf.Reset()
+	err = s.q.ExportCSV(&buf, PersonTable, "WHERE id = -1")
+	s.NoError(err)
+	s.Equal("id,group_id,name,email,created_at,updated_at\n", buf.String())
+}
+
+func (s *ReformSuite) TestDsSelectAllFrom() {
+	// the same view with different goqu dialects
+	for adapter, expected := range map[string]string{
+		"postgres": `FROM "people"`,
+		"mysql":    "FROM `people`",
+	} {
+		fake := new(fakeDB)
+		ds := goqu.New(adapter, nil).From().Where(goqu.I("id").Eq(1))
+		_, err := reform.NewDBFromInterface(fake, s.q.Dialect, nil).DsSelectAllFrom(PersonTable, ds)
+		s.Equal(errFake, err)
+		s.Require().Len(fake.queries, 1)
+		s.Contains(fake.queries[0], expected)
+	}
+}
+
+func (s *ReformSuite) TestDsSelectAllWithTotal() {
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, postgresql.Dialect, nil)
+	ds := goqu.New("postgres", nil).From().Where(goqu.I("name").Eq("Elfrieda Abbott")).Order(goqu.I("id").Asc()).Limit(1)
+	_, _, err := db.DsSelectAllWithTotal(PersonTable, ds)
+	s.Equal(errFake, err)
+	s.Require().Len(fake.queries, 1)
+	s.Contains(fake.queries[0], `"updated_at", COUNT(*) OVER () AS "__total" FROM "people"`)
+	s.Contains(fake.queries[0], `LIMIT 1`)
+
+	if s.q.Dialect != postgresql.Dialect {
+		s.T().Skip("PostgreSQL-specific test")
+	}
+
+	structs, total, err := s.q.DsSelectAllWithTotal(PersonTable, ds)
+	s.NoError(err)
+	s.Equal(uint64(2), total)
+	s.Require().Len(structs, 1)
+	s.Equal(int32(102), structs[0].(*Person).ID)
+
+	structs, total, err = s.q.DsSelectAllWithTotal(PersonTable, ds.Offset(10))
+	s.NoError(err)
+	s.Equal(uint64(0), total)
+	s.Nil(structs)
+}
+
+func (s *ReformSuite) TestDsToSQL() {
+	ds := goqu.New("postgres", nil).From().Where(goqu.I("id").Eq(1))
+	db := reform.NewDBFromInterface(new(fakeDB), postgresql.Dialect, nil)
+	for mode, expected := range map[reform.DsMode][]string{
+		reform.SelectDsMode: {`SELECT "id", "group_id", "name", "email", "created_at", "updated_at" FROM "people"`, `WHERE ("id" = 1)`},
+		reform.CountDsMode:  {`SELECT COUNT(*) AS "count" FROM "people"`, `WHERE ("id" = 1)`},
+		reform.DeleteDsMode: {`DELETE FROM "people"`, `WHERE ("id" = 1)`},
+		reform.UpdateDsMode: {`UPDATE "people" SET "name"`, `'Jane'`, `WHERE ("id" = 1)`},
+	} {
+		query, _, err := db.DsToSQL(PersonTable, ds, mode, goqu.Record{"name": "Jane"})
+		s.NoError(err)
+		for _, e := range expected {
+			s.Contains(query, e, "mode %d", mode)
+		}
+	}
+
+	_, _, err := db.DsToSQL(PersonTable, ds, reform.DsMode(42), nil)
+	s.EqualError(err, "reform: unexpected DsMode 42")
+
+	// the same query is executed
+	fake := new(fakeDB)
+	db = reform.NewDBFromInterface(fake, postgresql.Dialect, nil)
+	query, _, err := db.DsToSQL(PersonTable, ds, reform.SelectDsMode, nil)
+	s.NoError(err)
+	_, err = db.DsSelectAllFrom(PersonTable, ds)
+	s.Equal(errFake, err)
+	s.Equal([]string{query}, fake.queries)
+}
+
+// reservedOrder is a struct for table and column named with reserved words.
+type reservedOrder struct {
+	ID     int32
+	Select string
+}
+
+type reservedOrderView struct {
+	*reform.ViewBase
+}
+
+func (v *reservedOrderView) Schema() string           { return "" }
+func (v *reservedOrderView) Name() string             { return "order" }
+func (v *reservedOrderView) Columns() []string        { return []string{"id", "select"} }
+func (v *reservedOrderView) NewStruct() reform.Struct { return new(reservedOrder) }
+
+var reservedOrderTable = &reservedOrderView{reform.NewViewBase(&parse.StructInfo{
+	Type:    "reservedOrder",
+	SQLName: "order",
+	Fields: []parse.FieldInfo{
+		{Name: "ID", PKType: "int32", Column: "id"},
+		{Name: "Select", Column: "select"},
+	},
+})}
+
+func (o *reservedOrder) String() string          { return fmt.Sprintf("ID: %d, Select: %q", o.ID, o.Select) }
+func (o *reservedOrder) Values() []interface{}   { return []interface{}{o.ID, o.Select} }
+func (o *reservedOrder) Pointers() []interface{} { return []interface{}{&o.ID, &o.Select} }
+func (o *reservedOrder) View() reform.View       { return reservedOrderTable }
+
+func (s *ReformSuite) TestReservedWords() {
+	for dialect, expected := range map[reform.Dialect][]string{
+		postgresql.Dialect: {
+			`SELECT "order"."id", "order"."select" FROM "order" WHERE "order"."select" IN ($1)`,
+			`INSERT INTO "order" ("id", "select") VALUES ($1, $2)`,
+			`DELETE FROM "order" WHERE "select" = $1`,
+		},
+		mysql.Dialect: {
+			"SELECT `order`.`id`, `order`.`select` FROM `order` WHERE `order`.`select` IN (?)",
+			"INSERT INTO `order` (`id`, `select`) VALUES (?, ?)",
+			"DELETE FROM `order` WHERE `select` = ?",
+		},
+	} {
+		fake := new(fakeDB)
+		db := reform.NewDBFromInterface(fake, dialect, nil)
+		_, err := db.FindAllFrom(reservedOrderTable, "select", "x")
+		s.Equal(errFake, err)
+		s.Equal(errFake, db.Insert(&reservedOrder{ID: 1, Select: "x"}))
+		_, err = db.DeleteFrom(reservedOrderTable, "WHERE "+db.QuoteIdentifier("select")+" = "+db.Placeholder(1), "x")
+		s.Equal(errFake, err)
+		s.Equal(expected, fake.queries)
+	}
+}
+
+func (s *ReformSuite) TestDsReservedWords() {
+	for adapter, dialect := range map[string]reform.Dialect{"postgres": postgresql.Dialect, "mysql": mysql.Dialect} {
+		fake := new(fakeDB)
+		db := reform.NewDBFromInterface(fake, dialect, nil)
+		ds := goqu.New(adapter, nil).From().Where(goqu.I("select").Eq("x"))
+		_, err := db.DsSelectAllFrom(reservedOrderTable, ds)
+		s.Equal(errFake, err)
+		_, err = db.DsFindAllIn(reservedOrderTable, goqu.New(adapter, nil).From(), "Select", []interface{}{"a", "b"})
+		s.Equal(errFake, err)
+		_, err = db.DsUpdate(&reservedOrder{ID: 1, Select: "y"}, ds)
+		s.Equal(errFake, err)
+
+		s.Require().Len(fake.queries, 3)
+		q := db.QuoteIdentifier
+		s.Equal(fmt.Sprintf(`SELECT %s, %s FROM %s WHERE (%s = 'x')`, q("id"), q("select"), q("order"), q("select")), fake.queries[0])
+		s.Contains(fake.queries[1], fmt.Sprintf(`WHERE (%s IN ('a', 'b'))`, q("select")))
+		s.Contains(fake.queries[2], fmt.Sprintf(`UPDATE %s SET`, q("order")))
+		s.Contains(fake.queries[2], q("select"))
+		s.Contains(fake.queries[2], `'y'`)
+	}
+}
+
+func (s *ReformSuite) TestDsFindByJSON() {
+	fake := new(fakeDB)
+	db := reform.NewDBFromInterface(fake, postgresql.Dialect, nil)
+	_, err := db.DsFindByJSON(PersonTable, "Email", []string{"address", "city"}, "Paris")
+	s.Equal(errFake, err)
+	_, err = db.DsFindByJSON(PersonTable, "email", []string{"verified"}, nil)
+	s.Equal(errFake, err)
+	s.Require().Len(fake.queries, 2)
+	s.Contains(fake.queries[0], `WHERE ("email" -> 'address' ->> 'city' = 'Paris')`)
+	s.Contains(fake.queries[1], `WHERE ("email" ->> 'verified' IS NULL)`)
+
+	_, err = db.DsFindByJSON(PersonTable, "Email", nil, "Paris")
+	s.EqualError(err, "reform: DsFindByJSON requires non-empty path")
+	_, err = db.DsFindByJSON(PersonTable, "document", []string{"city"}, "Paris")
+	s.EqualError(err, "reform: unexpected column document for people")
+	_, err = reform.NewDBFromInterface(fake, mysql.Dialect, nil).DsFindByJSON(PersonTable, "email", []string{"city"}, "Paris")
+	s.EqualError(err, "reform: DsFindByJSON is not supported by this dialect")
+	s.Len(fake.queries, 2)
+}
+
+func (s *ReformSuite) TestDataset() {
+	for dialect, adapter := range map[reform.Dialect]string{
+		mssql.Dialect:      "",
+		mysql.Dialect:      "mysql",
+		postgresql.Dialect: "postgres",
+		sqlite3.Dialect:    "sqlite3",
+	} {
+		s.Equal(adapter, dialect.GoquAdapter())
+	}
+
+	ds := s.q.Dataset(PersonTable)
+	query, _, err := ds.ToSql()
+	s.NoError(err)
+	s.Contains(query, "FROM "+s.q.QuoteIdentifier("people"))
+
+	ds = ds.Where(goqu.I("name").Eq("Elfrieda Abbott")).Order(goqu.I("id").Asc())
+	structs, err := s.q.DsSelectAllFrom(PersonTable, ds)
+	s.NoError(err)
+	s.Equal([]reform.Struct{
+		&Person{ID: 102, GroupID: pointer.ToInt32(65534), Name: "Elfrieda Abbott", Email: pointer.ToString("elfrieda_abbott@example.org"), CreatedAt: personCreated},
+		&Person{ID: 103, GroupID: pointer.ToInt32(65534), Name: "Elfrieda Abbott", CreatedAt: personCreated},
+	}, structs)
+}
+
+func BenchmarkDsFindOneTo(b *testing.B) {
+	if DB.Dialect != postgresql.Dialect {
+		b.Skip("PostgreSQL-specific benchmark")
+	}
+
+	ds := goqu.New("postgres", nil).From().Where(goqu.I("id").Eq(1))
+	var person Person
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := DB.DsFindOneTo(&person, ds); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSelectAllFrom(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		structs, err := DB.SelectAllFrom(PersonTable, "")
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, str := range structs {
+			_ = str.(*Person).ID
+		}
+	}
+}
+
+func BenchmarkSelectAllReuse(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := DB.SelectAllReuse(PersonTable, func(str reform.Struct) error {
+			_ = str.(*Person).ID
+			return nil
+		}, "")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func (s *ReformSuite) TestCallMulti() {
+	people := fmt.Sprintf("SELECT %s FROM %s WHERE %s = 1",
+		strings.Join(s.q.QualifiedColumns(PersonTable), ", "), s.q.QualifiedView(PersonTable), s.q.QuoteIdentifier("id"))
+	projects := fmt.Sprintf("SELECT %s FROM %s WHERE %s = 'baron'",
+		strings.Join(s.q.QualifiedColumns(ProjectTable), ", "), s.q.QualifiedView(ProjectTable), s.q.QuoteIdentifier("id"))
+
+	res, err := s.q.CallMulti([]reform.View{PersonTable}, people)
+	s.NoError(err)
+	s.Require().Len(res, 1)
+	s.Require().Len(res[0], 1)
+	s.Equal(int32(1), res[0][0].(*Person).ID)
+
+	if s.q.Dialect == mssql.Dialect {
+		res, err = s.q.CallMulti([]reform.View{PersonTable, ProjectTable}, people+"; "+projects)
+		s.NoError(err)
+		s.Require().Len(res, 2)
+		s.Require().Len(res[1], 1)
+		s.Equal("baron", res[1][0].(*Project).ID)
+		return
+	}
+
+	res, err = s.q.CallMulti([]reform.View{PersonTable, ProjectTable}, people)
+	s.EqualError(err, "reform: expected 2 result sets, got 1")
+	s.Len(res, 1)
+}
+
+func BenchmarkSelectOneTo(b *testing.B) {
+	var person Person
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := DB.SelectOneTo(&person, "WHERE id = "+DB.Placeholder(1), 102); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package reform
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+)
+
+// ReconnectPolicy configures recovery from connection-level errors. See DB.WithReconnect.
+type ReconnectPolicy struct {
+	// Retry enables retrying of failed operation once after successful ping.
+	// It should be enabled only if operations are idempotent or it is known that failed operation
+	// was not executed by database.
+	Retry bool
+}
+
+// IsConnectionError returns true if err is a common connection-level error:
+// driver.ErrBadConn, io.EOF, io.ErrUnexpectedEOF or net.Error.
+// It can be used by Dialect.IsConnectionError implementations.
+func IsConnectionError(err error) bool {
+	switch err {
+	case driver.ErrBadConn, io.EOF, io.ErrUnexpectedEOF:
+		return true
+	}
+	_, ok := err.(net.Error)
+	return ok
+}
+
+// reconnectDB is a DBInterface which pings database on connection-level errors
+// and optionally retries failed operation.
+type reconnectDB struct {
+	DBInterface
+	dialect Dialect
+	policy  ReconnectPolicy
+}
+
+// recover checks err with dialect's IsConnectionError and pings database if it is a connection-level error.
+// It returns true if failed operation should be retried.
+func (r *reconnectDB) recover(err error) bool {
+	if err == nil || !r.dialect.IsConnectionError(err) {
+		return false
+	}
+	if e := (&DB{db: r.DBInterface}).Ping(); e != nil {
+		return false
+	}
+	return r.policy.Retry
+}
+
+func (r *reconnectDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	res, err := r.DBInterface.Exec(query, args...)
+	if r.recover(err) {
+		res, err = r.DBInterface.Exec(query, args...)
+	}
+	return res, err
+}
+
+func (r *reconnectDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := r.DBInterface.Query(query, args...)
+	if r.recover(err) {
+		rows, err = r.DBInterface.Query(query, args...)
+	}
+	return rows, err
+}
+
+func (r *reconnectDB) Begin() (*sql.Tx, error) {
+	tx, err := r.DBInterface.Begin()
+	if r.recover(err) {
+		tx, err = r.DBInterface.Begin()
+	}
+	return tx, err
+}
+
+func (r *reconnectDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	b, ok := r.DBInterface.(interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	})
+	if !ok {
+		return nil, errors.New("reform: DBInterface does not support BeginTx")
+	}
+
+	tx, err := b.BeginTx(ctx, opts)
+	if r.recover(err) {
+		tx, err = b.BeginTx(ctx, opts)
+	}
+	return tx, err
+}
+
+func (r *reconnectDB) PingContext(ctx context.Context) error {
+	return (&DB{db: r.DBInterface}).PingContext(ctx)
+}
+
+func (r *reconnectDB) Stats() sql.DBStats {
+	return (&DB{db: r.DBInterface}).Stats()
+}
+
+// check interface
+var _ DBInterface = new(reconnectDB)
+
+// WithReconnect returns a copy of db which checks errors of queries and transaction starts
+// with dialect's IsConnectionError. On connection-level error it pings database to re-establish connection
+// and, if policy.Retry is true and ping succeeded, retries failed operation once.
+//
+// QueryRow errors are not checked, as they are deferred until Scan. Queries inside transactions
+// are not checked too, as transaction can't be recovered.
+func (db *DB) WithReconnect(policy ReconnectPolicy) *DB {
+	r := &reconnectDB{
+		DBInterface: db.db,
+		dialect:     db.Dialect,
+		policy:      policy,
+	}
+	return &DB{
+		Querier: db.withDBTX(r),
+		db:      r,
+	}
+}
@@ -90,6 +90,15 @@ func (s *{{ .Type }}) Values() []interface{} {
 	}
 }
 
+// ValuesInto appends struct or record field values to values and returns the extended slice.
+// It allows reusing slices instead of allocating a new one with Values.
+// Appended interface{} values are never untyped nils.
+func (s *{{ .Type }}) ValuesInto(values []interface{}) []interface{} {
+	return append(values, {{- range .Fields }}
+		s.{{ .Name }}, {{- end }}
+	)
+}
+
 // Pointers returns a slice of pointers to struct or record fields.
 // Returned interface{} values are never untyped nils.
 func (s *{{ .Type }}) Pointers() []interface{} {
@@ -140,13 +149,14 @@ func (s *{{ .Type }}) SetPK(pk interface{}) {
 
 // check interfaces
 var (
-	_ reform.View   = {{ .TableVar }}
-	_ reform.Struct = new({{ .Type }})
+	_ reform.View         = {{ .TableVar }}
+	_ reform.Struct       = new({{ .Type }})
+	_ reform.ValuesFiller = new({{ .Type }})
 {{- if .IsTable }}
-	_ reform.Table  = {{ .TableVar }}
-	_ reform.Record = new({{ .Type }})
+	_ reform.Table        = {{ .TableVar }}
+	_ reform.Record       = new({{ .Type }})
 {{- end }}
-	_ fmt.Stringer  = new({{ .Type }})
+	_ fmt.Stringer        = new({{ .Type }})
 )
 `))
 
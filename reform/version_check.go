@@ -1,4 +1,4 @@
-// +build !go1.6
+// +build !go1.8
 
 package main
 
@@ -8,5 +8,5 @@ import (
 )
 
 func init() {
-	log.Fatalf("reform requires Go 1.6+, but was compiled with %s.", runtime.Version())
+	log.Fatalf("reform requires Go 1.8+, but was compiled with %s.", runtime.Version())
 }
@@ -0,0 +1,59 @@
+package reform
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// ReplicaPicker returns an index of replica (from 0 to n-1) to be used for the next read.
+// It is called concurrently.
+type ReplicaPicker func(n int) int
+
+// RoundRobinPicker returns a ReplicaPicker which uses replicas in turn.
+func RoundRobinPicker() ReplicaPicker {
+	var next uint64
+	return func(n int) int {
+		return int((atomic.AddUint64(&next, 1) - 1) % uint64(n))
+	}
+}
+
+// RandomPicker is a ReplicaPicker which uses random replicas.
+func RandomPicker(n int) int {
+	return rand.Intn(n)
+}
+
+// ReplicatedDB represents a primary SQL database with read-only replicas.
+// Embedded DB is the primary: all its methods, including transactions, use it.
+type ReplicatedDB struct {
+	*DB
+	replicas []DBInterface
+
+	// Picker picks replica for Reader. NewReplicatedDB sets it to RoundRobinPicker().
+	// It should not be changed concurrently with Reader calls.
+	Picker ReplicaPicker
+}
+
+// NewReplicatedDB creates new ReplicatedDB object for given primary DB and replicas.
+func NewReplicatedDB(primary *DB, replicas ...DBInterface) *ReplicatedDB {
+	return &ReplicatedDB{
+		DB:       primary,
+		replicas: replicas,
+		Picker:   RoundRobinPicker(),
+	}
+}
+
+// Reader returns a Querier for one of replicas chosen by Picker, or primary's Querier if there are no replicas.
+// It should be used for queries (like SelectAllFrom, FindByPrimaryKeyFrom, Count or ExistsByPK) which tolerate
+// replication lag. Returned Querier shares Dialect, Logger and other settings with primary's Querier.
+func (r *ReplicatedDB) Reader() *Querier {
+	if len(r.replicas) == 0 {
+		return r.DB.Querier
+	}
+	return r.DB.Querier.withDBTX(r.replicas[r.Picker(len(r.replicas))])
+}
+
+// Writer returns primary's Querier. It should be used for commands (like Insert, Update and Delete)
+// and for queries which should see their results.
+func (r *ReplicatedDB) Writer() *Querier {
+	return r.DB.Querier
+}